@@ -15,58 +15,64 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/members": {
+        "/admin/api-keys": {
             "get": {
-                "description": "Invalid query parameters are coerced to their default values.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Revoked and expired keys are listed too, most recently created first. Keys themselves are never shown.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get index of members.",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "The size of the returned page. Maximum value is 500.",
-                        "name": "pageSize",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "The page index (zero-based) to get. Pages that are out of range return emtpy lists.",
-                        "name": "page",
-                        "in": "query"
-                    }
-                ],
+                "summary": "Get index of API keys.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/MemberResponse"
+                                "$ref": "#/definitions/APIKeyResponse"
                             }
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
                     }
                 }
             },
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The key is sent as \"Authorization: Bearer \u003ckey\u003e\" and is limited to its scopes, which must be\npermissions the user creating it has. Scopes stop working once the user loses their permission. The\nkey is only shown in this response.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Add a member",
+                "summary": "Create an API key",
                 "parameters": [
                     {
-                        "description": "Member to add",
+                        "description": "The key to create",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/MemberUpdate"
+                            "$ref": "#/definitions/APIKeyCreate"
                         }
                     }
                 ],
@@ -74,7 +80,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/MemberResponse"
+                            "$ref": "#/definitions/APIKeyCreatedResponse"
                         }
                     },
                     "400": {
@@ -82,125 +88,2711 @@ const docTemplate = `{
                         "schema": {
                             "type": "Invalid"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
                     }
                 }
             }
         },
-        "/members/{id}": {
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The key stops working immediately. Revoking a revoked key succeeds without changing it.",
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the key",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/migrations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The database is dirty if a migration failed part of the way through, and has to be fixed by hand.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the version of the database's schema",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MigrationStatusResponseDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Entries are listed most recent first. Invalid paging parameters are coerced to their default values.\nMembers' notes are left out of entries for users without pastoral access.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get a member",
+                "summary": "Search the audit log.",
                 "parameters": [
+                    {
+                        "enum": [
+                            "member",
+                            "schedule",
+                            "user",
+                            "apiKey",
+                            "campus"
+                        ],
+                        "type": "string",
+                        "description": "Only entries for this type of entity",
+                        "name": "entityType",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "The id of the member to get",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only entries for the entity with this id",
+                        "name": "entityId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "Create",
+                            "Update",
+                            "Delete",
+                            "Restore",
+                            "Purge",
+                            "Merge"
+                        ],
+                        "type": "string",
+                        "description": "Only entries for this kind of change",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries for changes made by this actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries at or after this RFC 3339 timestamp",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries before this RFC 3339 timestamp",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The size of the returned page",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The page index (zero-based) to get",
+                        "name": "page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/MemberResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/AuditEntryResponse"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
+                        "schema": {
+                            "type": "A"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "The"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "The returned token is sent as \"Authorization: Bearer \u003ctoken\u003e\" to authenticate later requests, until it expires or is logged out.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update a member",
+                "summary": "Log in with a username and password.",
                 "parameters": [
                     {
-                        "description": "New data for the member. This operation replaces the member entirely.",
+                        "description": "Credentials",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/MemberUpdate"
+                            "$ref": "#/definitions/Login"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Member ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "End the session of the token used to authenticate the request.",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
                     }
+                }
+            }
+        },
+        "/auth/oidc/providers": {
+            "get": {
+                "produces": [
+                    "application/json"
                 ],
+                "summary": "List the single sign-on providers users of the church may log in with.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/MemberResponse"
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/oidc/{provider}/callback": {
+            "post": {
+                "description": "Users are created the first time they log in, named after their preferred username or verified email address.\nTheir roles are mapped from the provider's claims on every login.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete a member",
+                "summary": "Finish logging in with a single sign-on provider.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Member ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "The name of the provider",
+                        "name": "provider",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "The code and state the provider redirected back with",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/OIDCCallback"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "type": "No"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "The"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "MemberResponse": {
-            "type": "object",
-            "properties": {
-                "emailAddress": {
-                    "type": "string",
-                    "example": "aug.of.hippo@live.roma"
-                },
-                "firstName": {
-                    "type": "string",
-                    "example": "Augustinus"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 81996
-                },
+        },
+        "/auth/oidc/{provider}/start": {
+            "post": {
+                "description": "The user is sent to the returned URL, from which the provider redirects back with a code and the\nstate. The code and state are then given to the callback endpoint within 10 minutes.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Start logging in with a single sign-on provider.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The name of the provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/OIDCStartResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/campuses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Campuses are listed in order of name.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get every campus of the church.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/CampusResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a campus",
+                "parameters": [
+                    {
+                        "description": "Campus to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CampusUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/CampusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/campuses/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Rename a campus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the campus",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The campus's new name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CampusUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CampusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Members and schedules at the campus are left at no campus. Users limited to the campus are not\nallowed any more campuses.",
+                "summary": "Remove a campus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the campus",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Does not check the database, so that the server is not restarted while the database is down.",
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Check whether the server is running",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The member's notes are never shown. Any user may use this endpoint, but not an API key.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the logged in user and the member they are.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/MeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Fields which are left out are kept, and fields which are empty are cleared. If the church reviews\nsuch changes, the change waits for approval and is combined with any change already waiting.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update the email address or phone number of the member the logged in user is",
+                "parameters": [
+                    {
+                        "description": "The fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MeUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The change was made",
+                        "schema": {
+                            "$ref": "#/definitions/MeResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "The change is waiting for approval",
+                        "schema": {
+                            "$ref": "#/definitions/MeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/member-changes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes are listed oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the changes members have made to their own details which are waiting for approval.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/MemberChangeResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/member-changes/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The change is made to the member as it is now, keeping every field the change does not set.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Approve a change a member made to their own details",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the change",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/MemberChangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/member-changes/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Reject a change a member made to their own details",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the change",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/MemberChangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            }
+        },
+        "/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invalid paging parameters are coerced to their default values. Users limited to some campuses only\nsee the members of those campuses.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get index of members.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The size of the returned page. Maximum value is 500.",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The page index (zero-based) to get. Pages that are out of range return emtpy lists.",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only include members of these campuses",
+                        "name": "campusId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/MemberResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Notes are only saved for users with pastoral access. Users limited to some campuses may only add\nmembers to those campuses.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a member",
+                "parameters": [
+                    {
+                        "description": "Member to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MemberUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/MemberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/members.vcf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Members are streamed as they are read from the database. Members in the trash are not included.\nUsers limited to some campuses only get the members of those campuses.",
+                "produces": [
+                    "text/vcard"
+                ],
+                "summary": "Get every member as vCards",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only include members with this text in their name or email address",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only include members of these campuses",
+                        "name": "campusId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One vCard 3.0 per member",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pairs of members are scored by name similarity, same email address and same phone number.\nPairs are listed most likely duplicates first. Members in the trash are not considered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Find likely duplicate members.",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "The minimum score, from 0 to 1, of the pairs listed. Defaults to 0.5.",
+                        "name": "minScore",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/MemberDuplicateResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Members are streamed as they are read from the database, so every member can be exported at once.\nMembers in the trash are not exported. Users limited to some campuses only export the members of those\ncampuses.",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/jsonl"
+                ],
+                "summary": "Export members as a spreadsheet or JSON Lines",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "The file format of the export",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "firstName,lastName,emailAddress",
+                        "description": "Comma separated columns to export, in order. Defaults to every column the user may read.",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only export members with this text in their name or email address",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only export members of these campuses",
+                        "name": "campusId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The exported members",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Unknown"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Each row is validated and checked for likely duplicates of existing members. In dry-run mode\nnothing is imported. In commit mode every row is imported in a single transaction, except rows\nwhich are likely duplicates unless allowDuplicates is set; if any row is invalid nothing is imported.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Import members from a CSV file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file with a header row",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object mapping member fields to column headers, e.g. {\\",
+                        "name": "mapping",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "dry-run",
+                            "commit"
+                        ],
+                        "type": "string",
+                        "default": "dry-run",
+                        "description": "Whether to only validate the file or to import it",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Import rows which are likely duplicates of existing members",
+                        "name": "allowDuplicates",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Results of a dry run",
+                        "schema": {
+                            "$ref": "#/definitions/MemberImportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Results of a committed import",
+                        "schema": {
+                            "$ref": "#/definitions/MemberImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "422": {
+                        "description": "Some rows were invalid so nothing was imported",
+                        "schema": {
+                            "$ref": "#/definitions/MemberImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/import/vcard": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reads the N, EMAIL, TEL, ADR and NOTE properties of each vCard 3.0 or 4.0 in the file. Rows are\nvalidated, checked for duplicates and imported as for the CSV import.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Import members from vCards",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File of one or more vCards",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "dry-run",
+                            "commit"
+                        ],
+                        "type": "string",
+                        "default": "dry-run",
+                        "description": "Whether to only validate the file or to import it",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Import vCards which are likely duplicates of existing members",
+                        "name": "allowDuplicates",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Results of a dry run",
+                        "schema": {
+                            "$ref": "#/definitions/MemberImportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Results of a committed import",
+                        "schema": {
+                            "$ref": "#/definitions/MemberImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "422": {
+                        "description": "Some vCards were invalid so nothing was imported",
+                        "schema": {
+                            "$ref": "#/definitions/MemberImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The survivor takes each field from the member chosen in the request, every reference to the merged\nmember is re-pointed to the survivor, and the merged member is moved to the trash. A change the merged\nmember made to their own details which is waiting for approval is rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Merge two members",
+                "parameters": [
+                    {
+                        "description": "The members to merge",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MemberMerge"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/MemberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "One"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deleted members are listed most recently deleted first, until they are purged.\nInvalid query parameters are coerced to their default values.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get index of members in the trash.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The size of the returned page. Maximum value is 500.",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The page index (zero-based) to get. Pages that are out of range return emtpy lists.",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/MemberResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the member to get",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/MemberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Users without pastoral access cannot change the member's notes, which are kept as they were. Users\nlimited to some campuses may only update members of those campuses, and keep them there.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a member",
+                "parameters": [
+                    {
+                        "description": "New data for the member. This operation replaces the member entirely.",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MemberUpdate"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/MemberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The member is moved to the trash, from where it can be restored until it is purged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}.vcf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "text/vcard"
+                ],
+                "summary": "Get a member as a vCard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the member to get",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "vCard 3.0 of the member",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Entries are listed most recent first, and remain available after the member is purged.\nInvalid paging parameters are coerced to their default values.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the change history of a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The size of the returned page. Maximum value is 500.",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The page index (zero-based) to get.",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/AuditEntryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Restore a member from the trash",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/MemberResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Requests are counted and timed by route, along with how the database's connections are used, the\nversion of its schema and the members and schedules of the churches, which are read from the database\nat most once each refresh interval. Metrics read from the database are left out while it cannot be\nread. Only served when a bearer token is configured, which has to be given.",
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Get the server's metrics for Prometheus",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Not ready while the server is starting or shutting down, while the database cannot be reached or\nwhile its schema is not at the version the server was migrated to.",
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Check whether the server should be sent requests",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedules are listed in order of their first service. Users limited to some campuses only see the\nschedules of those campuses.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get every service schedule.",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only include schedules of these campuses",
+                        "name": "campusId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ScheduleResponseDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get index of users.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/UserResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Usernames are case insensitive and stored in lowercase. Passwords must be at least 12 characters long.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a user",
+                "parameters": [
+                    {
+                        "description": "User to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UserCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/campuses": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The user then only sees and changes the members and schedules of those campuses, and cannot use\nendpoints which work across every member. An empty list allows the user every campus.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Limit a user to some campuses",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The campuses to limit the user to",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UserCampuses"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "One"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/member": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The user may then view the member and update their email address and phone number through /me. A null\nmemberId unlinks the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Link a user to the member they are",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The member to link the user to",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UserMember"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "The"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replace the roles of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "The id of the user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The user's new roles",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UserRoles"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "Invalid"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "Not"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "The"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "No"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "APIKeyCreate": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "description": "When the key stops working, or null for a key which does not expire",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Church website"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "schedules:read"
+                    ]
+                }
+            }
+        },
+        "APIKeyCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "description": "The id of the user who created the key, or null if they have been removed",
+                    "type": "integer",
+                    "example": 3
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "key": {
+                    "description": "The key itself, which is only ever shown once. Starts with the slug of\nthe church, so that it also identifies the church.",
+                    "type": "string",
+                    "example": "grace.cmk_Xq3lW7bT0nYk2aPz8r1QmF4vH6sEoUdG9iLwXyBcJ5"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Church website"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "cmk_Xq3lW7bT"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "schedules:read"
+                    ]
+                }
+            }
+        },
+        "APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "description": "The id of the user who created the key, or null if they have been removed",
+                    "type": "integer",
+                    "example": 3
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Church website"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "cmk_Xq3lW7bT"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "schedules:read"
+                    ]
+                }
+            }
+        },
+        "AuditEntryResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AuditAction"
+                        }
+                    ],
+                    "example": "Update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "office.admin"
+                },
+                "after": {
+                    "$ref": "#/definitions/domain.AuditSnapshot"
+                },
+                "before": {
+                    "$ref": "#/definitions/domain.AuditSnapshot"
+                },
+                "diff": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.AuditChange"
+                    }
+                },
+                "entityId": {
+                    "type": "integer",
+                    "example": 81996
+                },
+                "entityType": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AuditEntityType"
+                        }
+                    ],
+                    "example": "member"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "occurredAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "CampusResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Hippo Regius"
+                }
+            }
+        },
+        "CampusUpdate": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Hippo Regius"
+                }
+            }
+        },
+        "Login": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "correct horse battery staple"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "ambrose"
+                }
+            }
+        },
+        "LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Sent as \"Authorization: Bearer \u003ctoken\u003e\" to authenticate later requests.\nStarts with the slug of the church, so that it also identifies the church.",
+                    "type": "string",
+                    "example": "grace.q8Zb1tH0mS6F2wTn9d0sXgKkM3yR5cVhJ7uLpE4aB1o"
+                },
+                "user": {
+                    "$ref": "#/definitions/UserResponse"
+                }
+            }
+        },
+        "MeResponse": {
+            "type": "object",
+            "properties": {
+                "member": {
+                    "description": "The member the user is, or null if the user is not linked to a member",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/MemberProfile"
+                        }
+                    ]
+                },
+                "pendingChange": {
+                    "description": "A change to the member which is waiting for approval",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/MemberChangeResponse"
+                        }
+                    ]
+                },
+                "user": {
+                    "$ref": "#/definitions/UserResponse"
+                }
+            }
+        },
+        "MeUpdate": {
+            "type": "object",
+            "properties": {
+                "emailAddress": {
+                    "type": "string",
+                    "example": "aug.of.hippo@live.roma"
+                },
+                "phoneNumber": {
+                    "type": "string",
+                    "example": "0434579344"
+                }
+            }
+        },
+        "MemberChangeResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "decidedAt": {
+                    "type": "string"
+                },
+                "decidedBy": {
+                    "description": "The username of whoever approved or rejected the change",
+                    "type": "string",
+                    "example": "ambrose"
+                },
+                "emailAddress": {
+                    "description": "The new email address, null if it is unchanged or empty if it is cleared",
+                    "type": "string",
+                    "example": "aug.of.hippo@live.roma"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "memberId": {
+                    "type": "integer",
+                    "example": 81996
+                },
+                "phoneNumber": {
+                    "description": "The new phone number, null if it is unchanged or empty if it is cleared",
+                    "type": "string",
+                    "example": "0434579344"
+                },
+                "requestedBy": {
+                    "description": "The id of the user who made the change, or null if they have been removed",
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "approved",
+                        "rejected"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.MemberChangeStatus"
+                        }
+                    ],
+                    "example": "pending"
+                }
+            }
+        },
+        "MemberDuplicateResponse": {
+            "type": "object",
+            "properties": {
+                "first": {
+                    "$ref": "#/definitions/MemberResponse"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "same email address",
+                        "similar name (92%)"
+                    ]
+                },
+                "score": {
+                    "type": "number",
+                    "example": 0.85
+                },
+                "second": {
+                    "$ref": "#/definitions/MemberResponse"
+                }
+            }
+        },
+        "MemberImportResponse": {
+            "type": "object",
+            "properties": {
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/MemberImportRowResponse"
+                    }
+                }
+            }
+        },
+        "MemberImportRowResponse": {
+            "type": "object",
+            "properties": {
+                "createdId": {
+                    "description": "The id of the member created from the row, if it was imported",
+                    "type": "integer",
+                    "example": 81996
+                },
+                "duplicates": {
+                    "description": "Existing members the row is likely a duplicate of",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/MemberDuplicateResponse"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "line": {
+                    "description": "The line of the row in the file, where the header is line 1",
+                    "type": "integer",
+                    "example": 2
+                },
+                "member": {
+                    "$ref": "#/definitions/MemberUpdate"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.MemberImportRowStatus"
+                        }
+                    ],
+                    "example": "valid"
+                }
+            }
+        },
+        "MemberMerge": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "description": "Which member each field is taken from, by the field's JSON name. Fields\nwhich are not given keep the survivor's value unless it is empty.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.MemberMergeSource"
+                    },
+                    "example": {
+                        "emailAddress": "merged"
+                    }
+                },
+                "mergedId": {
+                    "description": "The member which is moved to the trash after the merge",
+                    "type": "integer",
+                    "example": 81997
+                },
+                "survivorId": {
+                    "description": "The member which remains after the merge",
+                    "type": "integer",
+                    "example": 81996
+                }
+            }
+        },
+        "MemberProfile": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1 Basilica Way\nHippo Regius"
+                },
+                "emailAddress": {
+                    "type": "string",
+                    "example": "aug.of.hippo@live.roma"
+                },
+                "firstName": {
+                    "type": "string",
+                    "example": "Augustinus"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 81996
+                },
+                "lastName": {
+                    "type": "string",
+                    "example": "Hipponensis"
+                },
+                "phoneNumber": {
+                    "type": "string",
+                    "example": "0434579344"
+                }
+            }
+        },
+        "MemberResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1 Basilica Way\nHippo Regius"
+                },
+                "campusId": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "emailAddress": {
+                    "type": "string",
+                    "example": "aug.of.hippo@live.roma"
+                },
+                "firstName": {
+                    "type": "string",
+                    "example": "Augustinus"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 81996
+                },
                 "lastName": {
                     "type": "string",
                     "example": "Hipponensis"
@@ -209,6 +2801,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Fluent in Latin and Greek."
                 },
+                "notesRedacted": {
+                    "description": "Set when the notes are withheld because the user may not read them",
+                    "type": "boolean"
+                },
                 "phoneNumber": {
                     "type": "string",
                     "example": "0434579344"
@@ -218,6 +2814,15 @@ const docTemplate = `{
         "MemberUpdate": {
             "type": "object",
             "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1 Basilica Way\nHippo Regius"
+                },
+                "campusId": {
+                    "description": "The campus the member belongs to, if any",
+                    "type": "integer",
+                    "example": 2
+                },
                 "emailAddress": {
                     "type": "string",
                     "example": "aug.of.hippo@live.roma"
@@ -239,6 +2844,378 @@ const docTemplate = `{
                     "example": "0434579344"
                 }
             }
+        },
+        "OIDCCallback": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "4/0AQlEd8x..."
+                },
+                "state": {
+                    "type": "string",
+                    "example": "Jc5xq3lW7bT0nYk2aPz8r1QmF4vH6sEoUdG9iLwXyBc"
+                }
+            }
+        },
+        "OIDCStartResponse": {
+            "type": "object",
+            "properties": {
+                "authorizationUrl": {
+                    "description": "Where to send the user to log in at the provider",
+                    "type": "string",
+                    "example": "https://accounts.google.com/o/oauth2/v2/auth?client_id=..."
+                },
+                "state": {
+                    "description": "Passed back by the provider, to be given to the callback endpoint with the code",
+                    "type": "string",
+                    "example": "Jc5xq3lW7bT0nYk2aPz8r1QmF4vH6sEoUdG9iLwXyBc"
+                }
+            }
+        },
+        "UserCampuses": {
+            "type": "object",
+            "properties": {
+                "campusIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "UserCreate": {
+            "type": "object",
+            "properties": {
+                "memberId": {
+                    "description": "The member the user is, if any",
+                    "type": "integer",
+                    "example": 81996
+                },
+                "password": {
+                    "type": "string",
+                    "example": "correct horse battery staple"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Role"
+                    },
+                    "example": [
+                        "office_admin"
+                    ]
+                },
+                "username": {
+                    "type": "string",
+                    "example": "ambrose"
+                }
+            }
+        },
+        "UserMember": {
+            "type": "object",
+            "properties": {
+                "memberId": {
+                    "type": "integer",
+                    "example": 81996
+                }
+            }
+        },
+        "UserResponse": {
+            "type": "object",
+            "properties": {
+                "campusIds": {
+                    "description": "The campuses whose members and schedules the user is limited to, or\nempty if the user may see every campus",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        2
+                    ]
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "memberId": {
+                    "description": "The member the user is, who they may view and update through /me",
+                    "type": "integer",
+                    "example": 81996
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Role"
+                    },
+                    "example": [
+                        "pastor"
+                    ]
+                },
+                "username": {
+                    "type": "string",
+                    "example": "ambrose"
+                }
+            }
+        },
+        "UserRoles": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Role"
+                    },
+                    "example": [
+                        "pastor",
+                        "office_admin"
+                    ]
+                }
+            }
+        },
+        "domain.AuditAction": {
+            "type": "string",
+            "enum": [
+                "Create",
+                "Update",
+                "Delete",
+                "Restore",
+                "Purge",
+                "Merge"
+            ],
+            "x-enum-varnames": [
+                "AuditActionCreate",
+                "AuditActionUpdate",
+                "AuditActionDelete",
+                "AuditActionRestore",
+                "AuditActionPurge",
+                "AuditActionMerge"
+            ]
+        },
+        "domain.AuditChange": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {}
+            }
+        },
+        "domain.AuditEntityType": {
+            "type": "string",
+            "enum": [
+                "member",
+                "schedule",
+                "user",
+                "apiKey",
+                "campus"
+            ],
+            "x-enum-varnames": [
+                "AuditEntityMember",
+                "AuditEntitySchedule",
+                "AuditEntityUser",
+                "AuditEntityAPIKey",
+                "AuditEntityCampus"
+            ]
+        },
+        "domain.AuditSnapshot": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "domain.MemberChangeStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "MemberChangePending",
+                "MemberChangeApproved",
+                "MemberChangeRejected"
+            ]
+        },
+        "domain.MemberImportRowStatus": {
+            "type": "string",
+            "enum": [
+                "valid",
+                "invalid",
+                "duplicate",
+                "imported",
+                "skipped"
+            ],
+            "x-enum-varnames": [
+                "ImportRowValid",
+                "ImportRowInvalid",
+                "ImportRowDuplicate",
+                "ImportRowImported",
+                "ImportRowSkipped"
+            ]
+        },
+        "domain.MemberMergeSource": {
+            "type": "string",
+            "enum": [
+                "survivor",
+                "merged"
+            ],
+            "x-enum-varnames": [
+                "MergeFromSurvivor",
+                "MergeFromMerged"
+            ]
+        },
+        "domain.MigrationStatusResponseDTO": {
+            "type": "object",
+            "properties": {
+                "dirty": {
+                    "type": "boolean"
+                },
+                "expectedVersion": {
+                    "description": "The version the server was started with, which it expects the database\nto be at",
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Permission": {
+            "type": "string",
+            "enum": [
+                "members:read",
+                "members:write",
+                "members:notes:read",
+                "members:notes:write",
+                "schedules:read",
+                "schedules:write",
+                "audit:read",
+                "users:manage",
+                "apikeys:manage",
+                "campuses:manage",
+                "migrations:read"
+            ],
+            "x-enum-varnames": [
+                "PermissionMembersRead",
+                "PermissionMembersWrite",
+                "PermissionMemberNotesRead",
+                "PermissionMemberNotesWrite",
+                "PermissionSchedulesRead",
+                "PermissionSchedulesWrite",
+                "PermissionAuditRead",
+                "PermissionUsersManage",
+                "PermissionAPIKeysManage",
+                "PermissionCampusesManage",
+                "PermissionMigrationsRead"
+            ]
+        },
+        "domain.Role": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "pastor",
+                "office_admin",
+                "roster_coordinator",
+                "welcome_team"
+            ],
+            "x-enum-varnames": [
+                "RoleAdmin",
+                "RolePastor",
+                "RoleOfficeAdmin",
+                "RoleRosterCoordinator",
+                "RoleWelcomeTeam"
+            ]
+        },
+        "domain.ScheduleDayOfWeek": {
+            "type": "string",
+            "enum": [
+                "Monday",
+                "Tuesday",
+                "Wednesday",
+                "Thursday",
+                "Friday",
+                "Saturday",
+                "Sunday"
+            ],
+            "x-enum-varnames": [
+                "DayMonday",
+                "DayTuesday",
+                "DayWednesday",
+                "DayThursday",
+                "DayFriday",
+                "DaySaturday",
+                "DaySunday"
+            ]
+        },
+        "domain.ScheduleRepeatUnit": {
+            "type": "string",
+            "enum": [
+                "Day",
+                "Week",
+                "Month",
+                "Year"
+            ],
+            "x-enum-varnames": [
+                "RepeatUnitDay",
+                "RepeatUnitWeek",
+                "RepeatUnitMonth",
+                "RepeatUnitYear"
+            ]
+        },
+        "domain.ScheduleResponseDTO": {
+            "type": "object",
+            "properties": {
+                "beginDate": {
+                    "type": "string"
+                },
+                "campusId": {
+                    "type": "integer"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "repeatInterval": {
+                    "$ref": "#/definitions/domain.ScheduleResponseDTORepeatInterval"
+                },
+                "repeatNthDayOfMonth": {
+                    "$ref": "#/definitions/domain.ScheduleResponseDTORepeatNthDayOfMonth"
+                }
+            }
+        },
+        "domain.ScheduleResponseDTORepeatInterval": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "$ref": "#/definitions/domain.ScheduleRepeatUnit"
+                }
+            }
+        },
+        "domain.ScheduleResponseDTORepeatNthDayOfMonth": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "$ref": "#/definitions/domain.ScheduleDayOfWeek"
+                },
+                "n": {
+                    "type": "integer"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "\"Bearer \" followed by a token from POST /auth/login, or by an API key.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -250,7 +3227,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "Church Manager API",
-	Description:      "API for the Church Manager backend. Same api as used by the frontend.",
+	Description:      "API for the Church Manager backend. Same api as used by the frontend.\nEach request is for the church named by the X-Tenant header, the subdomain of the host or the token,\nand only sees that church's data.\nUsers may be limited to some of the church's campuses, and then only see the members and schedules\nof those campuses.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
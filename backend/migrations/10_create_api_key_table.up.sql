@@ -0,0 +1,17 @@
+CREATE TABLE api_key (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_by BIGINT REFERENCES app_user (id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITHOUT TIME ZONE,
+    last_used_at TIMESTAMP WITHOUT TIME ZONE,
+    revoked_at TIMESTAMP WITHOUT TIME ZONE
+);
+
+COMMENT ON COLUMN api_key.prefix IS 'The start of the key, shown so that keys can be told apart';
+COMMENT ON COLUMN api_key.key_hash IS 'Hex encoded SHA-256 hash of the whole key';
+COMMENT ON COLUMN api_key.scopes IS 'Names of the permissions the key is limited to, e.g. schedules:read';
+COMMENT ON COLUMN api_key.last_used_at IS 'Updated at most once a minute';
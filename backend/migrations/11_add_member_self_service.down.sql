@@ -0,0 +1,3 @@
+DROP TABLE member_change;
+ALTER TABLE app_user DROP COLUMN member_id;
+ALTER TABLE member DROP CONSTRAINT member_pkey;
@@ -0,0 +1,25 @@
+-- users and their changes refer to members, which needs members to be keyed by their id
+ALTER TABLE member ADD CONSTRAINT member_pkey PRIMARY KEY (id);
+
+ALTER TABLE app_user ADD COLUMN member_id BIGINT REFERENCES member (id) ON DELETE SET NULL;
+
+CREATE TABLE member_change (
+    id BIGSERIAL PRIMARY KEY,
+    member_id BIGINT NOT NULL REFERENCES member (id) ON DELETE CASCADE,
+    requested_by BIGINT REFERENCES app_user (id) ON DELETE SET NULL,
+    email_address TEXT,
+    phone_number TEXT,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    decided_at TIMESTAMP WITHOUT TIME ZONE,
+    decided_by TEXT
+);
+
+-- a member has at most one change waiting for approval
+CREATE UNIQUE INDEX member_change_pending_idx ON member_change (member_id) WHERE status = 'pending';
+
+COMMENT ON COLUMN app_user.member_id IS 'The member the user is, who they may view and update through /me';
+COMMENT ON TABLE member_change IS 'Changes members have made to their own details, queued for approval';
+COMMENT ON COLUMN member_change.email_address IS 'The new email address, NULL to keep the current one or empty to clear it';
+COMMENT ON COLUMN member_change.phone_number IS 'The new phone number, NULL to keep the current one or empty to clear it';
+COMMENT ON COLUMN member_change.decided_by IS 'The actor who approved or rejected the change';
@@ -0,0 +1,28 @@
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT ON SEQUENCES FROM churchmanager_tenant;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM churchmanager_tenant;
+REVOKE USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public FROM churchmanager_tenant;
+REVOKE SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public FROM churchmanager_tenant;
+REVOKE USAGE ON SCHEMA public FROM churchmanager_tenant;
+
+-- rows of every church but the first are removed along with their tenants
+DELETE FROM tenant WHERE slug <> 'default';
+
+ALTER TABLE app_user DROP CONSTRAINT app_user_oidc_identity_key;
+ALTER TABLE app_user ADD CONSTRAINT app_user_oidc_identity_key UNIQUE (oidc_issuer, oidc_subject);
+ALTER TABLE app_user DROP CONSTRAINT app_user_username_key;
+ALTER TABLE app_user ADD CONSTRAINT app_user_username_key UNIQUE (username);
+
+DO $$
+DECLARE
+    tenant_table TEXT;
+BEGIN
+    FOREACH tenant_table IN ARRAY ARRAY['member', 'schedule', 'audit_log', 'app_user', 'user_session', 'oidc_login', 'api_key', 'member_change'] LOOP
+        EXECUTE format('DROP POLICY tenant_isolation ON %I', tenant_table);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', tenant_table);
+        EXECUTE format('ALTER TABLE %I DROP COLUMN tenant_id', tenant_table);
+    END LOOP;
+END
+$$;
+
+DROP FUNCTION current_tenant_id();
+DROP TABLE tenant;
@@ -0,0 +1,56 @@
+CREATE TABLE tenant (
+    id BIGSERIAL PRIMARY KEY,
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    name VARCHAR(256) NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+COMMENT ON TABLE tenant IS 'The churches served by the deployment, each of which only sees its own rows';
+COMMENT ON COLUMN tenant.slug IS 'Lowercase name identifying the church in subdomains, the X-Tenant header and tokens';
+
+-- everything so far belongs to the church the deployment was serving
+INSERT INTO tenant (slug, name, created_at) VALUES ('default', 'Default', now() AT TIME ZONE 'UTC');
+
+-- the tenant queries are being run for, or NULL outside of any tenant
+CREATE FUNCTION current_tenant_id() RETURNS BIGINT LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::BIGINT
+$$;
+
+-- every row of these tables belongs to a tenant, and is only seen by queries for that tenant
+DO $$
+DECLARE
+    tenant_table TEXT;
+BEGIN
+    FOREACH tenant_table IN ARRAY ARRAY['member', 'schedule', 'audit_log', 'app_user', 'user_session', 'oidc_login', 'api_key', 'member_change'] LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN tenant_id BIGINT REFERENCES tenant (id) ON DELETE CASCADE', tenant_table);
+        EXECUTE format('UPDATE %I SET tenant_id = (SELECT id FROM tenant WHERE slug = ''default'')', tenant_table);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id SET NOT NULL, ALTER COLUMN tenant_id SET DEFAULT current_tenant_id()', tenant_table);
+        EXECUTE format('CREATE INDEX %I ON %I (tenant_id)', tenant_table || '_tenant_id_idx', tenant_table);
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', tenant_table);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (tenant_id = current_tenant_id())', tenant_table);
+    END LOOP;
+END
+$$;
+
+-- names are only unique within a church
+ALTER TABLE app_user DROP CONSTRAINT app_user_username_key;
+ALTER TABLE app_user ADD CONSTRAINT app_user_username_key UNIQUE (tenant_id, username);
+ALTER TABLE app_user DROP CONSTRAINT app_user_oidc_identity_key;
+ALTER TABLE app_user ADD CONSTRAINT app_user_oidc_identity_key UNIQUE (tenant_id, oidc_issuer, oidc_subject);
+
+-- the server runs its queries as this role, which unlike superusers and the owner of the tables is always
+-- subject to row-level security
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'churchmanager_tenant') THEN
+        CREATE ROLE churchmanager_tenant NOLOGIN NOBYPASSRLS;
+    END IF;
+END
+$$;
+
+GRANT churchmanager_tenant TO CURRENT_USER;
+GRANT USAGE ON SCHEMA public TO churchmanager_tenant;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO churchmanager_tenant;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO churchmanager_tenant;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO churchmanager_tenant;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO churchmanager_tenant;
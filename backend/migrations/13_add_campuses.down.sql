@@ -0,0 +1,4 @@
+ALTER TABLE app_user DROP COLUMN campus_ids;
+ALTER TABLE schedule DROP COLUMN campus_id;
+ALTER TABLE member DROP COLUMN campus_id;
+DROP TABLE campus;
@@ -0,0 +1,22 @@
+CREATE TABLE campus (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL DEFAULT current_tenant_id() REFERENCES tenant (id) ON DELETE CASCADE,
+    name VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT campus_name_key UNIQUE (tenant_id, name)
+);
+
+COMMENT ON TABLE campus IS 'The places within a church where it meets, which members and schedules may belong to';
+
+ALTER TABLE campus ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON campus USING (tenant_id = current_tenant_id());
+
+ALTER TABLE member ADD COLUMN campus_id BIGINT REFERENCES campus (id) ON DELETE SET NULL;
+CREATE INDEX member_campus_id_idx ON member (campus_id);
+
+ALTER TABLE schedule ADD COLUMN campus_id BIGINT REFERENCES campus (id) ON DELETE SET NULL;
+CREATE INDEX schedule_campus_id_idx ON schedule (campus_id);
+
+ALTER TABLE app_user ADD COLUMN campus_ids BIGINT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN app_user.campus_ids IS 'The campuses whose members and schedules the user is limited to, or empty for every campus';
@@ -0,0 +1,8 @@
+DROP INDEX member_change_requested_by_idx;
+DROP INDEX member_change_member_id_idx;
+DROP INDEX api_key_created_by_idx;
+DROP INDEX user_session_user_id_idx;
+DROP INDEX app_user_member_id_idx;
+DROP INDEX member_deleted_at_idx;
+DROP INDEX schedule_begin_date_idx;
+ALTER TABLE schedule DROP CONSTRAINT schedule_pkey;
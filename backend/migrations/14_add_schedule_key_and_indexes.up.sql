@@ -0,0 +1,15 @@
+-- schedules are keyed by their id, as members have been since they were referred to by users
+ALTER TABLE schedule ADD CONSTRAINT schedule_pkey PRIMARY KEY (id);
+
+-- schedules are listed in order of when they begin
+CREATE INDEX schedule_begin_date_idx ON schedule (tenant_id, begin_date);
+
+-- the trash and the purge only look at deleted members, which are few
+CREATE INDEX member_deleted_at_idx ON member (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- foreign keys, which are looked up whenever the rows they refer to are deleted
+CREATE INDEX app_user_member_id_idx ON app_user (member_id);
+CREATE INDEX user_session_user_id_idx ON user_session (user_id);
+CREATE INDEX api_key_created_by_idx ON api_key (created_by);
+CREATE INDEX member_change_member_id_idx ON member_change (member_id);
+CREATE INDEX member_change_requested_by_idx ON member_change (requested_by);
@@ -0,0 +1,2 @@
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE UPDATE ON SEQUENCES FROM churchmanager_tenant;
+REVOKE UPDATE ON ALL SEQUENCES IN SCHEMA public FROM churchmanager_tenant;
@@ -0,0 +1,3 @@
+-- restoring a backup keeps the ids of its rows, after which each sequence is set past the largest of them
+GRANT UPDATE ON ALL SEQUENCES IN SCHEMA public TO churchmanager_tenant;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT UPDATE ON SEQUENCES TO churchmanager_tenant;
@@ -0,0 +1,12 @@
+-- the role queries run as is given the tables of the application one by one, rather than every table in the schema,
+-- so that it cannot change the version of the schema recorded in schema_migrations, and a table a later migration
+-- creates is only usable by the role once that migration grants it
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM churchmanager_tenant;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT, UPDATE ON SEQUENCES FROM churchmanager_tenant;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM churchmanager_tenant;
+
+GRANT SELECT, INSERT, UPDATE, DELETE
+    ON tenant, member, schedule, audit_log, app_user, user_session, oidc_login, api_key, member_change, campus
+    TO churchmanager_tenant;
+-- the version of the schema is read for health checks and metrics
+GRANT SELECT ON schema_migrations TO churchmanager_tenant;
@@ -0,0 +1,2 @@
+COMMENT ON TYPE day_of_week IS NULL;
+COMMENT ON TYPE repeat_interval IS NULL;
@@ -0,0 +1,4 @@
+-- the types were named after the table since migration 2 created them, though its down step still names them as
+-- they were first written, so a database cannot be rolled back past it and is recreated instead
+COMMENT ON TYPE repeat_interval IS 'The unit of schedule.repeat_interval_count';
+COMMENT ON TYPE day_of_week IS 'The day of schedule.repeat_nth_day_of_month_day';
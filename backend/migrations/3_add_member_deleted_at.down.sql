@@ -0,0 +1 @@
+ALTER TABLE member DROP COLUMN deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE member ADD COLUMN deleted_at TIMESTAMP WITHOUT TIME ZONE;
+
+COMMENT ON COLUMN member.deleted_at IS 'When the member was moved to the trash, null if the member has not been deleted';
@@ -0,0 +1,21 @@
+CREATE TYPE audit_action AS ENUM ('Create', 'Update', 'Delete', 'Restore', 'Purge');
+
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(64) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    action audit_action NOT NULL,
+    actor VARCHAR(256) NOT NULL,
+    occurred_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    before JSONB,
+    after JSONB,
+    -- at least one snapshot is present; before is null for creations and after is null for purges
+    CHECK (before IS NOT NULL OR after IS NOT NULL),
+    diff JSONB NOT NULL
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id, occurred_at);
+CREATE INDEX audit_log_occurred_at_idx ON audit_log (occurred_at);
+
+COMMENT ON COLUMN audit_log.actor IS 'The user responsible for the change, or ''system'' for changes made by the server itself';
+COMMENT ON COLUMN audit_log.diff IS 'Object of changed fields, each with a before and after value';
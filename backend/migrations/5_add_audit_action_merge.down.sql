@@ -0,0 +1,6 @@
+-- enum values cannot be dropped, so the type is recreated without 'Merge'
+UPDATE audit_log SET action = 'Update' WHERE action = 'Merge';
+ALTER TYPE audit_action RENAME TO audit_action_old;
+CREATE TYPE audit_action AS ENUM ('Create', 'Update', 'Delete', 'Restore', 'Purge');
+ALTER TABLE audit_log ALTER COLUMN action TYPE audit_action USING action::text::audit_action;
+DROP TYPE audit_action_old;
@@ -0,0 +1,3 @@
+ALTER TABLE member ADD COLUMN address TEXT;
+
+COMMENT ON COLUMN member.address IS 'Postal address, with lines separated by newlines';
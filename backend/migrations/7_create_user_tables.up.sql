@@ -0,0 +1,19 @@
+CREATE TABLE app_user (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(128) NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE TABLE user_session (
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES app_user (id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX user_session_expires_at_idx ON user_session (expires_at);
+
+COMMENT ON COLUMN app_user.username IS 'Lowercase login name';
+COMMENT ON COLUMN app_user.password_hash IS 'Argon2id hash in the PHC string format';
+COMMENT ON COLUMN user_session.token_hash IS 'Hex encoded SHA-256 hash of the session token given to the client';
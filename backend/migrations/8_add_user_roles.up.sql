@@ -0,0 +1,6 @@
+ALTER TABLE app_user ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{}';
+
+-- users from before roles existed could do everything, so keep them able to
+UPDATE app_user SET roles = '{admin}';
+
+COMMENT ON COLUMN app_user.roles IS 'Names of the roles given to the user, e.g. pastor or welcome_team';
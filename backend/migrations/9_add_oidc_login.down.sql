@@ -0,0 +1,8 @@
+DROP TABLE oidc_login;
+
+DELETE FROM app_user WHERE password_hash IS NULL;
+ALTER TABLE app_user DROP CONSTRAINT app_user_credentials_check;
+ALTER TABLE app_user DROP CONSTRAINT app_user_oidc_identity_key;
+ALTER TABLE app_user DROP COLUMN oidc_subject;
+ALTER TABLE app_user DROP COLUMN oidc_issuer;
+ALTER TABLE app_user ALTER COLUMN password_hash SET NOT NULL;
@@ -0,0 +1,19 @@
+-- users who log in with single sign-on have no password
+ALTER TABLE app_user ALTER COLUMN password_hash DROP NOT NULL;
+ALTER TABLE app_user ADD COLUMN oidc_issuer TEXT;
+ALTER TABLE app_user ADD COLUMN oidc_subject TEXT;
+ALTER TABLE app_user ADD CONSTRAINT app_user_oidc_identity_key UNIQUE (oidc_issuer, oidc_subject);
+ALTER TABLE app_user ADD CONSTRAINT app_user_credentials_check
+    CHECK (password_hash IS NOT NULL OR (oidc_issuer IS NOT NULL AND oidc_subject IS NOT NULL));
+
+CREATE TABLE oidc_login (
+    state_hash CHAR(64) PRIMARY KEY,
+    provider VARCHAR(64) NOT NULL,
+    code_verifier TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+COMMENT ON COLUMN app_user.oidc_subject IS 'The subject identifying the user at the OpenID Connect issuer, for users provisioned by single sign-on';
+COMMENT ON TABLE oidc_login IS 'Single sign-on logins which have been started but not yet finished';
+COMMENT ON COLUMN oidc_login.state_hash IS 'Hex encoded SHA-256 hash of the state passed through the provider';
@@ -0,0 +1,10 @@
+DROP TABLE member_change;
+DROP TABLE api_key;
+DROP TABLE oidc_login;
+DROP TABLE user_session;
+DROP TABLE app_user;
+DROP TABLE audit_log;
+DROP TABLE schedule;
+DROP TABLE member;
+DROP TABLE campus;
+DROP TABLE tenant;
@@ -0,0 +1,150 @@
+-- The schema of the postgres migrations as it stands at their version 13, for SQLite. SQLite has no arrays, enums
+-- or JSONB, so arrays and snapshots are kept as JSON text and enums are checked by constraints. SQLite has no
+-- row-level security either, so the stores limit every query to the rows of its tenant themselves. Times are kept
+-- as text in UTC, which sorts in order.
+
+CREATE TABLE tenant (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    name VARCHAR(256) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- the church a deployment serves when it is not told which
+INSERT INTO tenant (slug, name, created_at) VALUES ('default', 'Default', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+
+CREATE TABLE campus (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    name VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT campus_name_key UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE member (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    first_name VARCHAR(128),
+    last_name VARCHAR(128),
+    email_address VARCHAR(256),
+    phone_number VARCHAR(128),
+    address TEXT,
+    campus_id INTEGER REFERENCES campus (id) ON DELETE SET NULL,
+    -- can be the empty string if unused
+    notes TEXT NOT NULL,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX member_tenant_id_idx ON member (tenant_id);
+CREATE INDEX member_campus_id_idx ON member (campus_id);
+
+CREATE TABLE schedule (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    begin_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP,
+    repeat_interval_count INTEGER CHECK (repeat_interval_count >= 0),
+    repeat_interval_unit TEXT CHECK (repeat_interval_unit IN ('Day', 'Week', 'Month', 'Year')),
+    repeat_nth_day_of_month_day TEXT
+        CHECK (repeat_nth_day_of_month_day IN ('Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday', 'Sunday')),
+    repeat_nth_day_of_month_n INTEGER,
+    campus_id INTEGER REFERENCES campus (id) ON DELETE SET NULL,
+    -- the same as the checks of the postgres schedule table
+    CHECK ((repeat_interval_count IS NULL) = (repeat_interval_unit IS NULL)),
+    CHECK ((repeat_nth_day_of_month_day IS NULL) = (repeat_nth_day_of_month_n IS NULL)),
+    CHECK ((repeat_interval_count IS NULL) <> (repeat_nth_day_of_month_day IS NULL))
+);
+
+CREATE INDEX schedule_tenant_id_idx ON schedule (tenant_id);
+CREATE INDEX schedule_campus_id_idx ON schedule (campus_id);
+
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    entity_type VARCHAR(64) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('Create', 'Update', 'Delete', 'Restore', 'Purge', 'Merge')),
+    actor VARCHAR(256) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    before TEXT,
+    after TEXT,
+    diff TEXT NOT NULL,
+    -- at least one snapshot is present; before is null for creations and after is null for purges
+    CHECK (before IS NOT NULL OR after IS NOT NULL)
+);
+
+CREATE INDEX audit_log_tenant_id_idx ON audit_log (tenant_id);
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id, occurred_at);
+CREATE INDEX audit_log_occurred_at_idx ON audit_log (occurred_at);
+
+CREATE TABLE app_user (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    username VARCHAR(128) NOT NULL,
+    password_hash TEXT,
+    roles TEXT NOT NULL DEFAULT '[]',
+    member_id INTEGER REFERENCES member (id) ON DELETE SET NULL,
+    campus_ids TEXT NOT NULL DEFAULT '[]',
+    oidc_issuer TEXT,
+    oidc_subject TEXT,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT app_user_username_key UNIQUE (tenant_id, username),
+    CONSTRAINT app_user_oidc_identity_key UNIQUE (tenant_id, oidc_issuer, oidc_subject),
+    CONSTRAINT app_user_credentials_check
+        CHECK (password_hash IS NOT NULL OR (oidc_issuer IS NOT NULL AND oidc_subject IS NOT NULL))
+);
+
+CREATE TABLE user_session (
+    token_hash CHAR(64) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES app_user (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX user_session_tenant_id_idx ON user_session (tenant_id);
+CREATE INDEX user_session_expires_at_idx ON user_session (expires_at);
+
+CREATE TABLE oidc_login (
+    state_hash CHAR(64) PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    provider VARCHAR(64) NOT NULL,
+    code_verifier TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX oidc_login_tenant_id_idx ON oidc_login (tenant_id);
+
+CREATE TABLE api_key (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    name VARCHAR(128) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    created_by INTEGER REFERENCES app_user (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX api_key_tenant_id_idx ON api_key (tenant_id);
+
+CREATE TABLE member_change (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id INTEGER NOT NULL REFERENCES tenant (id) ON DELETE CASCADE,
+    member_id INTEGER NOT NULL REFERENCES member (id) ON DELETE CASCADE,
+    requested_by INTEGER REFERENCES app_user (id) ON DELETE SET NULL,
+    email_address TEXT,
+    phone_number TEXT,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    created_at TIMESTAMP NOT NULL,
+    decided_at TIMESTAMP,
+    decided_by TEXT
+);
+
+CREATE INDEX member_change_tenant_id_idx ON member_change (tenant_id);
+-- a member has at most one change waiting for approval
+CREATE UNIQUE INDEX member_change_pending_idx ON member_change (member_id) WHERE status = 'pending';
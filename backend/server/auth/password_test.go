@@ -0,0 +1,52 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+)
+
+func TestPasswordHashing(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("error hashing password: %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$") {
+		t.Errorf("expected an argon2id PHC string, got %s", hash)
+	}
+
+	other, _ := auth.HashPassword("correct horse battery staple")
+	if other == hash {
+		t.Error("expected hashes of the same password to differ by their salt")
+	}
+
+	if ok, err := auth.VerifyPassword("correct horse battery staple", hash); err != nil || !ok {
+		t.Errorf("expected the correct password to verify, got %v %v", ok, err)
+	}
+
+	if ok, err := auth.VerifyPassword("Correct horse battery staple", hash); err != nil || ok {
+		t.Errorf("expected an incorrect password not to verify, got %v %v", ok, err)
+	}
+
+	if _, err := auth.VerifyPassword("password", "$2a$10$notargon"); err != auth.ErrMalformedHash {
+		t.Errorf("expected a malformed hash error, got %v", err)
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	token, hash, err := auth.GenerateToken()
+	if err != nil {
+		t.Fatalf("error generating token: %v", err)
+	}
+
+	if auth.HashToken(token) != hash {
+		t.Error("expected the returned hash to be the hash of the token")
+	}
+
+	other, _, _ := auth.GenerateToken()
+	if other == token {
+		t.Error("expected tokens to be random")
+	}
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const tokenLength = 32
+
+// Generates a random session token to give to the client, and the hash of it
+// to keep in the database. Only the hash is stored so that a leaked database
+// does not leak usable tokens.
+func GenerateToken() (token string, tokenHash string, err error) {
+	data := make([]byte, tokenLength)
+	if _, err = rand.Read(data); err != nil {
+		return "", "", fmt.Errorf("generating token: %v", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(data)
+	return token, HashToken(token), nil
+}
+
+// Gives the hash under which a token is stored. Tokens are random and long,
+// so a fast hash is sufficient, unlike for passwords.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,347 @@
+// Package backup writes the rows of every church to an archive, and restores
+// them from one, without the database's own tools such as pg_dump. An archive
+// is a zip file with a file of JSON Lines for each table, holding a JSON
+// object of the columns of each row, and a manifest giving the kind of
+// database and the version of its schema, and the number of rows and the
+// checksum of each file.
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/sqlite"
+)
+
+// The version of the layout of archives, which changes whenever an archive
+// could no longer be read as before.
+const Format = 1
+
+const manifestName = "manifest.json"
+
+// The longest row an archive is read with.
+const maxRowLength = 16 << 20
+
+type Manifest struct {
+	Format uint `json:"format"`
+	// The kind of database backed up, either postgres or sqlite, as each has
+	// its own migrations and so its own versions
+	Database      string          `json:"database"`
+	SchemaVersion uint            `json:"schemaVersion"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Tables        []ManifestTable `json:"tables"`
+}
+
+type ManifestTable struct {
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Columns []string `json:"columns"`
+	Rows    uint     `json:"rows"`
+	// The SHA-256 of the file, in hexadecimal
+	SHA256 string `json:"sha256"`
+}
+
+// Gives the total number of rows in the archive.
+func (manifest *Manifest) Rows() uint {
+	rows := uint(0)
+	for _, table := range manifest.Tables {
+		rows += table.Rows
+	}
+	return rows
+}
+
+// The tables which are backed up, in the order they are restored in, which is
+// that of store.Schema. Sessions and logins which are in progress are left
+// out, so that everyone signs in again after a restore.
+func tables() []store.SchemaTable {
+	return slices.DeleteFunc(slices.Clone(store.Schema), func(table store.SchemaTable) bool {
+		return table.Name == "user_session" || table.Name == "oidc_login"
+	})
+}
+
+// Gives the kind of database the connection string names, for the manifest.
+func Database(connectionString string) string {
+	if sqlite.IsConnectionString(connectionString) {
+		return "sqlite"
+	}
+	return "postgres"
+}
+
+// Writes the files of an archive one table at a time, as the rows of each
+// table are exported.
+type archiveWriter struct {
+	zip      *zip.Writer
+	manifest *Manifest
+	tables   []store.SchemaTable
+	file     io.Writer
+	checksum hash.Hash
+	row      bytes.Buffer
+}
+
+// Finishes the file of the table being written, if there is one, and starts
+// that of the next table.
+func (writer *archiveWriter) next() error {
+	writer.finish()
+	table := writer.tables[len(writer.manifest.Tables)]
+	name := table.Name + ".jsonl"
+	file, err := writer.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	writer.checksum = sha256.New()
+	writer.file = io.MultiWriter(file, writer.checksum)
+	writer.manifest.Tables = append(writer.manifest.Tables, ManifestTable{
+		Name:    table.Name,
+		File:    name,
+		Columns: table.Columns,
+	})
+	return nil
+}
+
+func (writer *archiveWriter) finish() {
+	if len(writer.manifest.Tables) > 0 {
+		current := &writer.manifest.Tables[len(writer.manifest.Tables)-1]
+		current.SHA256 = hex.EncodeToString(writer.checksum.Sum(nil))
+	}
+}
+
+// Writes the row to the file of its table, first starting the files of any
+// tables before it which had no rows.
+func (writer *archiveWriter) write(table string, row json.RawMessage) error {
+	for len(writer.manifest.Tables) == 0 || writer.manifest.Tables[len(writer.manifest.Tables)-1].Name != table {
+		if len(writer.manifest.Tables) == len(writer.tables) {
+			return fmt.Errorf("rows of %s were exported out of order", table)
+		}
+		if err := writer.next(); err != nil {
+			return err
+		}
+	}
+
+	// each row is on a line of its own
+	writer.row.Reset()
+	if err := json.Compact(&writer.row, row); err != nil {
+		return err
+	}
+	writer.row.WriteByte('\n')
+	if _, err := writer.file.Write(writer.row.Bytes()); err != nil {
+		return err
+	}
+	writer.manifest.Tables[len(writer.manifest.Tables)-1].Rows++
+	return nil
+}
+
+// Writes the files of the tables which had no rows and the manifest.
+func (writer *archiveWriter) close() error {
+	for len(writer.manifest.Tables) < len(writer.tables) {
+		if err := writer.next(); err != nil {
+			return err
+		}
+	}
+	writer.finish()
+
+	file, err := writer.zip.Create(manifestName)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(writer.manifest); err != nil {
+		return err
+	}
+	return writer.zip.Close()
+}
+
+// Writes an archive of the rows of every church in the database, which is of
+// the given kind. The rows are read in a single transaction, so that the
+// archive has them as they were at a single moment.
+func Write(ctx context.Context, out io.Writer, stores *store.Stores, database string) (*Manifest, error) {
+	status, err := stores.Health.MigrationStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading the version of the schema: %v", err)
+	}
+	if status.Dirty {
+		return nil, fmt.Errorf("the database is part way through migration %d", status.Version)
+	}
+
+	writer := &archiveWriter{
+		zip: zip.NewWriter(out),
+		manifest: &Manifest{
+			Format:        Format,
+			Database:      database,
+			SchemaVersion: status.Version,
+			CreatedAt:     time.Now().UTC(),
+			Tables:        make([]ManifestTable, 0),
+		},
+		tables: tables(),
+	}
+	if err = stores.Backup.Export(ctx, writer.tables, writer.write); err != nil {
+		return nil, fmt.Errorf("exporting rows: %v", err)
+	}
+	if err = writer.close(); err != nil {
+		return nil, fmt.Errorf("writing the archive: %v", err)
+	}
+	return writer.manifest, nil
+}
+
+// Reads the manifest of the archive, checking that the archive is of a
+// format which can be read.
+func ReadManifest(archive *zip.Reader) (*Manifest, error) {
+	file, err := archive.Open(manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("the archive has no manifest: %v", err)
+	}
+	defer file.Close()
+
+	var manifest Manifest
+	if err = json.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("the archive is of format %d, but only format %d can be read", manifest.Format, Format)
+	}
+	return &manifest, nil
+}
+
+// Checks that the table is one which is backed up, in the place it is restored
+// in, and that the server has each of its columns.
+func checkTable(table *ManifestTable, previous int) (int, error) {
+	backedUp := tables()
+	i := slices.IndexFunc(backedUp, func(schemaTable store.SchemaTable) bool {
+		return schemaTable.Name == table.Name
+	})
+	if i < 0 {
+		return 0, fmt.Errorf("the archive has the unknown table %s", table.Name)
+	}
+	if i <= previous {
+		return 0, fmt.Errorf("the archive has %s out of order", table.Name)
+	}
+	for _, column := range table.Columns {
+		if !slices.Contains(backedUp[i].Columns, column) {
+			return 0, fmt.Errorf("the archive has the unknown column %s.%s", table.Name, column)
+		}
+	}
+	return i, nil
+}
+
+// Reads each row of the table's file, as a line of JSON.
+func readRows(archive *zip.Reader, table *ManifestTable, fn func(row json.RawMessage) error) error {
+	file, err := archive.Open(table.File)
+	if err != nil {
+		return fmt.Errorf("the archive has no file for %s: %v", table.Name, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRowLength)
+	for scanner.Scan() {
+		if err = fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Checks each file of the archive against the number of rows and the checksum
+// in the manifest.
+func checkFile(archive *zip.Reader, table *ManifestTable) error {
+	file, err := archive.Open(table.File)
+	if err != nil {
+		return fmt.Errorf("the archive has no file for %s: %v", table.Name, err)
+	}
+	defer file.Close()
+
+	checksum := sha256.New()
+	if _, err = io.Copy(checksum, file); err != nil {
+		return fmt.Errorf("reading %s: %v", table.File, err)
+	}
+	if hex.EncodeToString(checksum.Sum(nil)) != table.SHA256 {
+		return fmt.Errorf("%s does not match its checksum", table.File)
+	}
+
+	rows := uint(0)
+	err = readRows(archive, table, func(row json.RawMessage) error {
+		if !json.Valid(row) {
+			return fmt.Errorf("row %d of %s is not JSON", rows+1, table.File)
+		}
+		rows++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if rows != table.Rows {
+		return fmt.Errorf("%s has %d rows, but the manifest has %d", table.File, rows, table.Rows)
+	}
+	return nil
+}
+
+// Restores the rows of the archive into a database of the given kind which
+// has been migrated but has no rows other than tenants, replacing its tenants
+// with those of the archive. The database has to be of the same kind as the
+// one backed up, and at the same or a newer version of its schema. Every file
+// is checked against the manifest before any row is restored, and then every
+// row is restored in a single transaction, so that nothing is if any row
+// cannot be. Returns store.ErrNotEmpty if the database has other rows.
+func Restore(ctx context.Context, archive *zip.Reader, stores *store.Stores, database string) (*Manifest, error) {
+	manifest, err := ReadManifest(archive)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Database != database {
+		return nil, fmt.Errorf("the archive is of a %s database, and cannot be restored into a %s one", manifest.Database, database)
+	}
+
+	status, err := stores.Health.MigrationStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading the version of the schema: %v", err)
+	}
+	if status.Dirty {
+		return nil, fmt.Errorf("the database is part way through migration %d", status.Version)
+	}
+	if status.Version < manifest.SchemaVersion {
+		return nil, fmt.Errorf(
+			"the archive is of migration version %d, and cannot be restored into a database at the older version %d",
+			manifest.SchemaVersion, status.Version,
+		)
+	}
+
+	previous := -1
+	for i := range manifest.Tables {
+		if previous, err = checkTable(&manifest.Tables[i], previous); err != nil {
+			return nil, err
+		}
+		if err = checkFile(archive, &manifest.Tables[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	err = stores.Backup.Restore(ctx, func(insert store.RowInserter) error {
+		for _, table := range manifest.Tables {
+			err := readRows(archive, &table, func(row json.RawMessage) error {
+				if err := insert(table.Name, table.Columns, row); err != nil {
+					return fmt.Errorf("restoring %s: %w", table.Name, err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
@@ -0,0 +1,222 @@
+package backup_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/backup"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/migration"
+	"github.com/carsonalh/churchmanagerbackend/server/seed"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/sqlite"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+// Opens a new, migrated SQLite database and gives the id of its default tenant.
+func openStores(t *testing.T) (*store.Stores, uint64) {
+	connectionString := "sqlite://" + filepath.Join(t.TempDir(), "churchmanager.db")
+	if _, err := migration.PerformMigration("../../migrations", connectionString); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	stores, err := sqlite.Open(context.Background(), connectionString, 0)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	t.Cleanup(stores.Close)
+
+	tenant, err := stores.Tenants.FindBySlug(context.Background(), "default")
+	if err != nil || tenant == nil {
+		t.Fatalf("failed to find the default tenant: %v", err)
+	}
+	return stores, tenant.Id()
+}
+
+// Fills the database with some of every kind of row, and gives an archive of
+// it.
+func backedUp(t *testing.T) (*store.Stores, []byte) {
+	ctx := context.Background()
+	stores, tenantId := openStores(t)
+	result, err := seed.Seed(ctx, stores, tenantId, &seed.Config{Seed: 1, Campuses: 2, Members: 40, Schedules: 3})
+	if err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	other, err := stores.Tenants.Ensure(ctx, "other", "Other Church")
+	if err != nil {
+		t.Fatalf("failed to create a tenant: %v", err)
+	}
+	if _, err = stores.Members.ForTenant(other.Id()).Create(ctx, "test", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Lydia")}); err != nil {
+		t.Fatalf("failed to create a member: %v", err)
+	}
+
+	member, err := stores.Members.ForTenant(tenantId).GetPage(ctx, &domain.MemberFilter{}, 1, 0)
+	if err != nil || len(member) != 1 {
+		t.Fatalf("failed to find a member: %v", err)
+	}
+	user, err := stores.Users.ForTenant(tenantId).Create(ctx, "test", "admin", "hash", []domain.Role{domain.RoleAdmin}, util.NewPtr(member[0].Id()))
+	if err != nil {
+		t.Fatalf("failed to create a user: %v", err)
+	}
+	_, _, err = stores.APIKeys.ForTenant(tenantId).Create(ctx, "test", user.Id(), &domain.APIKeyCreateDTO{
+		Name:   "Website",
+		Scopes: []domain.Permission{domain.PermissionMembersRead},
+	})
+	if err != nil {
+		t.Fatalf("failed to create an API key: %v", err)
+	}
+	if _, _, err = stores.Sessions.ForTenant(tenantId).Create(ctx, user.Id(), time.Hour); err != nil {
+		t.Fatalf("failed to create a session: %v", err)
+	}
+	if len(result.Campuses) != 2 {
+		t.Fatalf("expected 2 campuses, got %d", len(result.Campuses))
+	}
+
+	archive := bytes.Buffer{}
+	if _, err = backup.Write(ctx, &archive, stores, "sqlite"); err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+	return stores, archive.Bytes()
+}
+
+func readArchive(t *testing.T, archive []byte) *zip.Reader {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("failed to read the archive: %v", err)
+	}
+	return reader
+}
+
+// Gives the archive with each file replaced by what change gives for it.
+func rewrite(t *testing.T, archive []byte, change func(name string, contents []byte) []byte) *zip.Reader {
+	rewritten := bytes.Buffer{}
+	writer := zip.NewWriter(&rewritten)
+	for _, file := range readArchive(t, archive).File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := writer.Create(file.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = out.Write(change(file.Name, contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return readArchive(t, rewritten.Bytes())
+}
+
+func TestBackupRestoresIntoAnEmptyDatabase(t *testing.T) {
+	ctx := context.Background()
+	original, archive := backedUp(t)
+	restored, _ := openStores(t)
+
+	manifest, err := backup.Restore(ctx, readArchive(t, archive), restored, "sqlite")
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	for _, table := range manifest.Tables {
+		if table.Name == "user_session" || table.Name == "oidc_login" {
+			t.Errorf("expected %s not to be backed up", table.Name)
+		}
+	}
+
+	// backing up what was restored gives the same rows again
+	again := bytes.Buffer{}
+	againManifest, err := backup.Write(ctx, &again, restored, "sqlite")
+	if err != nil {
+		t.Fatalf("failed to back up the restored database: %v", err)
+	}
+	if len(againManifest.Tables) != len(manifest.Tables) {
+		t.Fatalf("expected %d tables, got %d", len(manifest.Tables), len(againManifest.Tables))
+	}
+	for i, table := range manifest.Tables {
+		if againManifest.Tables[i].SHA256 != table.SHA256 || againManifest.Tables[i].Rows != table.Rows {
+			t.Errorf("expected the restored rows of %s to be those backed up", table.Name)
+		}
+	}
+
+	// rows created after the restore do not take the ids of restored ones
+	tenant, err := restored.Tenants.FindBySlug(ctx, "default")
+	if err != nil || tenant == nil {
+		t.Fatalf("expected the default tenant to be restored, got %v", err)
+	}
+	members, err := restored.Members.ForTenant(tenant.Id()).GetAll(ctx)
+	if err != nil {
+		t.Fatalf("failed to get the restored members: %v", err)
+	}
+	created, err := restored.Members.ForTenant(tenant.Id()).Create(ctx, "test", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Priscilla")})
+	if err != nil {
+		t.Fatalf("failed to create a member after the restore: %v", err)
+	}
+	for _, member := range members {
+		if member.Id() >= created.Id() {
+			t.Errorf("expected the new member's id %d to follow those restored, got %d", created.Id(), member.Id())
+		}
+	}
+
+	if _, err = backup.Restore(ctx, readArchive(t, archive), original, "sqlite"); !errors.Is(err, store.ErrNotEmpty) {
+		t.Errorf("expected restoring into a database with members to be refused, got %v", err)
+	}
+}
+
+func TestBackupRestoreChecksTheArchive(t *testing.T) {
+	ctx := context.Background()
+	_, archive := backedUp(t)
+
+	for name, check := range map[string]struct {
+		archive  *zip.Reader
+		database string
+		expected string
+	}{
+		"changed rows": {
+			rewrite(t, archive, func(name string, contents []byte) []byte {
+				if name == "member.jsonl" {
+					return bytes.Replace(contents, []byte(`"notes":""`), []byte(`"notes":"changed"`), 1)
+				}
+				return contents
+			}),
+			"sqlite",
+			"checksum",
+		},
+		"newer schema": {
+			rewrite(t, archive, func(name string, contents []byte) []byte {
+				if name != "manifest.json" {
+					return contents
+				}
+				var manifest backup.Manifest
+				if err := json.Unmarshal(contents, &manifest); err != nil {
+					t.Fatal(err)
+				}
+				manifest.SchemaVersion++
+				changed, _ := json.Marshal(manifest)
+				return changed
+			}),
+			"sqlite",
+			"older version",
+		},
+		"other database": {readArchive(t, archive), "postgres", "sqlite database"},
+	} {
+		restored, _ := openStores(t)
+		_, err := backup.Restore(ctx, check.archive, restored, check.database)
+		if err == nil || !strings.Contains(err.Error(), check.expected) {
+			t.Errorf("expected restoring with %s to fail with %q, got %v", name, check.expected, err)
+		}
+	}
+}
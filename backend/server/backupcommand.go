@@ -0,0 +1,100 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/backup"
+	"github.com/carsonalh/churchmanagerbackend/server/config"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+// Writes an archive of every church's rows to a file, which must not exist yet
+// so that no earlier backup is replaced.
+func runBackup(args []string) {
+	if len(args) != 1 {
+		fatal("backup takes the file to write the archive to")
+	}
+	path := args[0]
+
+	config, err := config.Load(os.LookupEnv)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	// a backup takes as long as the database is large, so it has no timeout
+	ctx := context.Background()
+	stores, err := openStores(ctx, config.Database.URL, 0)
+	if err != nil {
+		fatal("failed to connect to database", "error", err)
+	}
+	defer stores.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		fatal("failed to create the archive", "error", err)
+	}
+	manifest, err := backup.Write(ctx, file, stores, backup.Database(config.Database.URL))
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		os.Remove(path)
+		fatal("failed to back up", "error", err)
+	}
+
+	fmt.Printf(
+		"backed up %d rows of %d tables at migration version %d to %s\n",
+		manifest.Rows(), len(manifest.Tables), manifest.SchemaVersion, path,
+	)
+}
+
+// Restores the rows of an archive into a database which has been migrated but
+// has nothing else in it.
+func runRestore(args []string) {
+	if len(args) != 1 {
+		fatal("restore takes the file to read the archive from")
+	}
+	path := args[0]
+
+	config, err := config.Load(os.LookupEnv)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	if _, err = checkMigrated(config.Database.Migrations, config.Database.URL); err != nil {
+		fatal("the database is not migrated", "error", err)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		fatal("failed to open the archive", "error", err)
+	}
+	defer archive.Close()
+
+	ctx := context.Background()
+	stores, err := openStores(ctx, config.Database.URL, 0)
+	if err != nil {
+		fatal("failed to connect to database", "error", err)
+	}
+	defer stores.Close()
+
+	manifest, err := backup.Restore(ctx, &archive.Reader, stores, backup.Database(config.Database.URL))
+	if errors.Is(err, store.ErrNotEmpty) {
+		fatal("failed to restore: the database has to be empty, having only been migrated, and not yet served")
+	}
+	if err != nil {
+		fatal("failed to restore", "error", err)
+	}
+
+	fmt.Printf(
+		"restored %d rows of %d tables from migration version %d, backed up at %s\n",
+		manifest.Rows(), len(manifest.Tables), manifest.SchemaVersion, manifest.CreatedAt.Format(time.RFC3339),
+	)
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// An environment variable and how its value is set in the configuration.
+type envVar struct {
+	name  string
+	apply func(config *Config, value string) error
+}
+
+func stringEnv(name string, field func(config *Config) *string) envVar {
+	return envVar{name, func(config *Config, value string) error {
+		*field(config) = value
+		return nil
+	}}
+}
+
+// For secrets, which replace any file the secret was to be read from.
+func secretEnv(name string, field func(config *Config) (value *string, path *string)) envVar {
+	return envVar{name, func(config *Config, value string) error {
+		secret, path := field(config)
+		*secret, *path = value, ""
+		return nil
+	}}
+}
+
+func uintEnv(name string, field func(config *Config) *uint) envVar {
+	return envVar{name, func(config *Config, value string) error {
+		parsed, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return err
+		}
+		*field(config) = uint(parsed)
+		return nil
+	}}
+}
+
+func boolEnv(name string, field func(config *Config) *bool) envVar {
+	return envVar{name, func(config *Config, value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(config) = parsed
+		return nil
+	}}
+}
+
+func durationEnv(name string, field func(config *Config) *Duration) envVar {
+	return envVar{name, func(config *Config, value string) error {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(config) = Duration(parsed)
+		return nil
+	}}
+}
+
+// For settings which are lists, given as JSON in the variable.
+func jsonEnv(name string, field func(config *Config) any) envVar {
+	return envVar{name, func(config *Config, value string) error {
+		return json.Unmarshal([]byte(value), field(config))
+	}}
+}
+
+// Every environment variable read into the configuration.
+var envVars = []envVar{
+	secretEnv("CHURCHMANAGER_DATABASE_URL", func(config *Config) (*string, *string) {
+		return &config.Database.URL, &config.Database.URLFile
+	}),
+	stringEnv("CHURCHMANAGER_MIGRATIONS", func(config *Config) *string { return &config.Database.Migrations }),
+	boolEnv("CHURCHMANAGER_DATABASE_AUTO_MIGRATE", func(config *Config) *bool { return &config.Database.AutoMigrate }),
+	durationEnv("CHURCHMANAGER_DATABASE_QUERY_TIMEOUT", func(config *Config) *Duration { return &config.Database.QueryTimeout }),
+	stringEnv("CHURCHMANAGER_LISTEN_ADDRESS", func(config *Config) *string { return &config.ListenAddress }),
+	durationEnv("CHURCHMANAGER_HTTP_READ_HEADER_TIMEOUT", func(config *Config) *Duration { return &config.HTTP.ReadHeaderTimeout }),
+	durationEnv("CHURCHMANAGER_HTTP_READ_TIMEOUT", func(config *Config) *Duration { return &config.HTTP.ReadTimeout }),
+	durationEnv("CHURCHMANAGER_HTTP_WRITE_TIMEOUT", func(config *Config) *Duration { return &config.HTTP.WriteTimeout }),
+	durationEnv("CHURCHMANAGER_HTTP_IDLE_TIMEOUT", func(config *Config) *Duration { return &config.HTTP.IdleTimeout }),
+	durationEnv("CHURCHMANAGER_HTTP_DRAIN_DELAY", func(config *Config) *Duration { return &config.HTTP.DrainDelay }),
+	durationEnv("CHURCHMANAGER_HTTP_SHUTDOWN_TIMEOUT", func(config *Config) *Duration { return &config.HTTP.ShutdownTimeout }),
+	uintEnv("CHURCHMANAGER_MEMBERS_DEFAULT_PAGE_SIZE", func(config *Config) *uint { return &config.Members.DefaultPageSize }),
+	uintEnv("CHURCHMANAGER_MEMBERS_MAX_PAGE_SIZE", func(config *Config) *uint { return &config.Members.MaxPageSize }),
+	uintEnv("CHURCHMANAGER_AUDIT_DEFAULT_PAGE_SIZE", func(config *Config) *uint { return &config.Audit.DefaultPageSize }),
+	uintEnv("CHURCHMANAGER_AUDIT_MAX_PAGE_SIZE", func(config *Config) *uint { return &config.Audit.MaxPageSize }),
+	durationEnv("CHURCHMANAGER_SESSION_DURATION", func(config *Config) *Duration { return &config.SessionDuration }),
+	durationEnv("CHURCHMANAGER_MEMBER_RETENTION", func(config *Config) *Duration { return &config.MemberRetention }),
+	durationEnv("CHURCHMANAGER_PURGE_INTERVAL", func(config *Config) *Duration { return &config.PurgeInterval }),
+	boolEnv("CHURCHMANAGER_SELF_SERVICE_APPROVAL", func(config *Config) *bool { return &config.SelfServiceApproval }),
+	stringEnv("CHURCHMANAGER_BASE_DOMAIN", func(config *Config) *string { return &config.BaseDomain }),
+	stringEnv("CHURCHMANAGER_DEFAULT_TENANT", func(config *Config) *string { return &config.DefaultTenant }),
+	jsonEnv("CHURCHMANAGER_TENANTS", func(config *Config) any { return &config.Tenants }),
+	stringEnv("CHURCHMANAGER_INITIAL_USERNAME", func(config *Config) *string { return &config.InitialUser.Username }),
+	secretEnv("CHURCHMANAGER_INITIAL_PASSWORD", func(config *Config) (*string, *string) {
+		return &config.InitialUser.Password, &config.InitialUser.PasswordFile
+	}),
+	jsonEnv("CHURCHMANAGER_OIDC_PROVIDERS", func(config *Config) any { return &config.OIDCProviders }),
+	boolEnv("CHURCHMANAGER_DEMO", func(config *Config) *bool { return &config.Demo }),
+	stringEnv("CHURCHMANAGER_LOG_LEVEL", func(config *Config) *string { return &config.LogLevel }),
+	secretEnv("CHURCHMANAGER_METRICS_TOKEN", func(config *Config) (*string, *string) {
+		return &config.MetricsToken, &config.MetricsTokenFile
+	}),
+	boolEnv("CHURCHMANAGER_METRICS_TENANT_LABELS", func(config *Config) *bool { return &config.MetricsTenantLabels }),
+	durationEnv("CHURCHMANAGER_METRICS_REFRESH_INTERVAL", func(config *Config) *Duration { return &config.MetricsRefreshInterval }),
+}
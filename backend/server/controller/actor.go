@@ -0,0 +1,17 @@
+package controller
+
+import "github.com/gin-gonic/gin"
+
+// The key in the gin context under which middleware stores the name of the
+// actor responsible for a request, as recorded in the audit log.
+const ActorKey = "actor"
+
+// The actor recorded for requests which no middleware has identified.
+const AnonymousActor = "anonymous"
+
+func requestActor(c *gin.Context) string {
+	if actor := c.GetString(ActorKey); actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	store store.APIKeyRepository
+}
+
+func SetupAPIKeyController(router *gin.RouterGroup, store store.APIKeyRepository) *APIKeyController {
+	controller := &APIKeyController{store: store}
+
+	manage := RequirePermission(domain.PermissionAPIKeysManage)
+
+	router.GET("", manage, controller.getAPIKeys)
+	router.POST("", manage, controller.postAPIKey)
+	router.DELETE(":id", manage, controller.revokeAPIKey)
+
+	return controller
+}
+
+// getAPIKeys godoc
+// @Summary      Get index of API keys.
+// @Description  Revoked and expired keys are listed too, most recently created first. Keys themselves are never shown.
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} domain.APIKeyResponseDTO
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /admin/api-keys [get]
+func (controller *APIKeyController) getAPIKeys(c *gin.Context) {
+	keys, err := controller.store.ForTenant(requestTenant(c).Id()).GetAll(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("error getting API keys from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	responseDTOs := make([]domain.APIKeyResponseDTO, 0)
+
+	for _, key := range keys {
+		responseDTOs = append(responseDTOs, *key.ToResponseDTO())
+	}
+
+	c.JSON(http.StatusOK, responseDTOs)
+}
+
+// postAPIKey godoc
+// @Summary      Create an API key
+// @Description  The key is sent as "Authorization: Bearer <key>" and is limited to its scopes, which must be
+// @Description  permissions the user creating it has. Scopes stop working once the user loses their permission. The
+// @Description  key is only shown in this response.
+// @Security     BearerAuth
+// @Param        request body domain.APIKeyCreateDTO true "The key to create"
+// @Accept       json
+// @Produce      json
+// @Success      201 {object} domain.APIKeyCreatedResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or is itself an API key
+// @Router       /admin/api-keys [post]
+func (controller *APIKeyController) postAPIKey(c *gin.Context) {
+	user := requestUser(c)
+	if user == nil {
+		c.String(http.StatusForbidden, "API keys can only be created by users\n")
+		return
+	}
+
+	var createDto domain.APIKeyCreateDTO
+
+	if err := c.BindJSON(&createDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	if errs := createDto.Validate(user, time.Now()); len(errs) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Failed to validate create object with the following errors:\n")
+		for _, err := range errs {
+			builder.WriteString(err.Error())
+			builder.WriteString("\n")
+		}
+		c.String(http.StatusBadRequest, builder.String())
+		return
+	}
+
+	secret, key, err := controller.store.ForTenant(requestTenant(c).Id()).Create(c.Request.Context(), requestActor(c), user.Id(), &createDto)
+	if err != nil {
+		requestLogger(c).Error("failed to create API key", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain.APIKeyCreatedResponseDTO{
+		APIKeyResponseDTO: *key.ToResponseDTO(),
+		Key:               tenantToken(requestTenant(c), secret),
+	})
+}
+
+// revokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  The key stops working immediately. Revoking a revoked key succeeds without changing it.
+// @Security     BearerAuth
+// @Param        id path int true "The id of the key"
+// @Success      204
+// @Failure      400 The id could not be parsed into an integer of appropriate size
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Failure      404 No key with the given id could be found
+// @Router       /admin/api-keys/{id} [delete]
+func (controller *APIKeyController) revokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	found, err := controller.store.ForTenant(requestTenant(c).Id()).Revoke(c.Request.Context(), requestActor(c), id)
+	if err != nil {
+		requestLogger(c).Error("error revoking API key", "apiKeyId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
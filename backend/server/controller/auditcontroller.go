@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	store           store.AuditRepository
+	defaultPageSize uint
+	maxPageSize     uint
+}
+
+type AuditControllerConfig struct {
+	DefaultPageSize uint
+	MaxPageSize     uint
+}
+
+func SetupAuditController(router *gin.RouterGroup, store store.AuditRepository, config *AuditControllerConfig) *AuditController {
+	controller := &AuditController{
+		store:           store,
+		defaultPageSize: config.DefaultPageSize,
+		maxPageSize:     config.MaxPageSize,
+	}
+
+	router.GET("", RequirePermission(domain.PermissionAuditRead), controller.getAudit)
+
+	return controller
+}
+
+// getAudit godoc
+// @Summary      Search the audit log.
+// @Description  Entries are listed most recent first. Invalid paging parameters are coerced to their default values.
+// @Description  Members' notes are left out of entries for users without pastoral access.
+// @Param        entityType query string false "Only entries for this type of entity" Enums(member, schedule, user, apiKey, campus)
+// @Param        entityId   query int    false "Only entries for the entity with this id"
+// @Param        action     query string false "Only entries for this kind of change" Enums(Create, Update, Delete, Restore, Purge, Merge)
+// @Param        actor      query string false "Only entries for changes made by this actor"
+// @Param        since      query string false "Only entries at or after this RFC 3339 timestamp"
+// @Param        until      query string false "Only entries before this RFC 3339 timestamp"
+// @Param        pageSize   query int    false "The size of the returned page"
+// @Param        page       query int    false "The page index (zero-based) to get"
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} domain.AuditEntryResponseDTO
+// @Failure      400 A filter could not be parsed
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /audit [get]
+func (controller *AuditController) getAudit(c *gin.Context) {
+	filter, errs := parseAuditFilter(c)
+	if len(errs) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Failed to parse audit filter with the following errors:\n")
+		for _, err := range errs {
+			builder.WriteString(err)
+			builder.WriteString("\n")
+		}
+		c.String(http.StatusBadRequest, builder.String())
+		return
+	}
+
+	pageSize, page := parsePageParams(c, controller.defaultPageSize, controller.maxPageSize)
+
+	entries, err := controller.store.ForTenant(requestTenant(c).Id()).GetPage(c.Request.Context(), filter, pageSize, page)
+	if err != nil {
+		requestLogger(c).Error("error getting audit log from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, auditEntriesToResponseDTOs(c, entries))
+}
+
+func parseAuditFilter(c *gin.Context) (*domain.AuditFilter, []string) {
+	filter := &domain.AuditFilter{}
+	errs := make([]string, 0)
+
+	if entityType := c.Query("entityType"); entityType != "" {
+		switch domain.AuditEntityType(entityType) {
+		case domain.AuditEntityMember, domain.AuditEntitySchedule, domain.AuditEntityUser, domain.AuditEntityAPIKey, domain.AuditEntityCampus:
+			filter.EntityType = (*domain.AuditEntityType)(&entityType)
+		default:
+			errs = append(errs, "unknown entityType \""+entityType+"\"")
+		}
+	}
+
+	if entityId := c.Query("entityId"); entityId != "" {
+		id, err := strconv.ParseUint(entityId, 10, 64)
+		if err != nil {
+			errs = append(errs, "invalid entityId \""+entityId+"\"")
+		} else {
+			filter.EntityId = &id
+		}
+	}
+
+	if action := c.Query("action"); action != "" {
+		switch domain.AuditAction(action) {
+		case domain.AuditActionCreate, domain.AuditActionUpdate, domain.AuditActionDelete,
+			domain.AuditActionRestore, domain.AuditActionPurge, domain.AuditActionMerge:
+			filter.Action = (*domain.AuditAction)(&action)
+		default:
+			errs = append(errs, "unknown action \""+action+"\"")
+		}
+	}
+
+	if actor := c.Query("actor"); actor != "" {
+		filter.Actor = &actor
+	}
+
+	for _, bound := range []struct {
+		name string
+		dest **time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errs = append(errs, "invalid "+bound.name+" timestamp \""+value+"\", expected RFC 3339")
+		} else {
+			*bound.dest = &t
+		}
+	}
+
+	return filter, errs
+}
+
+// Converts entries to responses, without the notes of members unless the
+// requesting user has pastoral access.
+func auditEntriesToResponseDTOs(c *gin.Context, entries []domain.AuditEntry) []domain.AuditEntryResponseDTO {
+	responseDTOs := make([]domain.AuditEntryResponseDTO, 0)
+	canReadNotes := hasPermission(c, domain.PermissionMemberNotesRead)
+
+	for _, entry := range entries {
+		if entry.EntityType() == domain.AuditEntityMember && !canReadNotes {
+			entry = *entry.WithoutFields("notes")
+		}
+		responseDTOs = append(responseDTOs, *entry.ToResponseDTO())
+	}
+
+	return responseDTOs
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/oidc"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	userStore       store.UserRepository
+	sessionStore    store.SessionRepository
+	oidcLoginStore  store.OIDCLoginRepository
+	sessionDuration time.Duration
+	oidcProviders   map[string]*oidc.Provider
+}
+
+type AuthControllerConfig struct {
+	// How long a session lasts after logging in.
+	SessionDuration time.Duration
+	// Single sign-on providers users may log in with, by OpenID Connect.
+	OIDCProviders []oidc.ProviderConfig
+}
+
+func SetupAuthController(
+	router *gin.RouterGroup,
+	userStore store.UserRepository,
+	sessionStore store.SessionRepository,
+	oidcLoginStore store.OIDCLoginRepository,
+	config *AuthControllerConfig,
+) *AuthController {
+	controller := &AuthController{
+		userStore:       userStore,
+		sessionStore:    sessionStore,
+		oidcLoginStore:  oidcLoginStore,
+		sessionDuration: config.SessionDuration,
+	}
+
+	router.POST("login", controller.login)
+	router.POST("logout", controller.logout)
+	controller.setupOIDC(router, config.OIDCProviders)
+
+	return controller
+}
+
+// login godoc
+// @Summary      Log in with a username and password.
+// @Description  The returned token is sent as "Authorization: Bearer <token>" to authenticate later requests, until it expires or is logged out.
+// @Param        request body domain.LoginDTO true "Credentials"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} domain.LoginResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      401 The username or password is incorrect
+// @Router       /auth/login [post]
+func (controller *AuthController) login(c *gin.Context) {
+	var loginDto domain.LoginDTO
+
+	if err := c.BindJSON(&loginDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	user, err := controller.userStore.ForTenant(requestTenant(c).Id()).FindByUsername(c.Request.Context(), domain.NormaliseUsername(loginDto.Username))
+	if err != nil {
+		requestLogger(c).Error("error finding user", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if user == nil || user.PasswordHash() == nil {
+		// take as long as a real check so as not to reveal which users exist
+		auth.VerifyDummyPassword(loginDto.Password)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ok, err := auth.VerifyPassword(loginDto.Password, *user.PasswordHash())
+	if err != nil {
+		requestLogger(c).Error("error verifying password of user", "userId", user.Id(), "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	controller.startSession(c, user)
+}
+
+// Responds with a new session for the user.
+func (controller *AuthController) startSession(c *gin.Context, user *domain.User) {
+	token, expiresAt, err := controller.sessionStore.ForTenant(requestTenant(c).Id()).Create(c.Request.Context(), user.Id(), controller.sessionDuration)
+	if err != nil {
+		requestLogger(c).Error("error creating session", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.LoginResponseDTO{
+		Token:     tenantToken(requestTenant(c), token),
+		ExpiresAt: expiresAt,
+		User:      *user.ToResponseDTO(),
+	})
+}
+
+// logout godoc
+// @Summary      End the session of the token used to authenticate the request.
+// @Security     BearerAuth
+// @Success      204
+// @Failure      401 Not authenticated
+// @Router       /auth/logout [post]
+func (controller *AuthController) logout(c *gin.Context) {
+	deleted, err := controller.sessionStore.ForTenant(requestTenant(c).Id()).Delete(c.Request.Context(), bearerToken(c))
+	if err != nil {
+		requestLogger(c).Error("error deleting session", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !deleted {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+// The key in the gin context under which the authentication middleware stores
+// the authenticated *domain.User, for requests authenticated as a user.
+const UserKey = "user"
+
+// The key in the gin context under which the authentication middleware stores
+// the domain.Principal the request is authenticated as.
+const PrincipalKey = "principal"
+
+// Reads the token from an "Authorization: Bearer <token>" header, giving the
+// empty string if there is none.
+func rawBearerToken(c *gin.Context) string {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Gives the bearer token without the tenant it may start with, which has
+// already been used to find the tenant of the request.
+func bearerToken(c *gin.Context) string {
+	_, token := splitTenantToken(rawBearerToken(c))
+	return token
+}
+
+// Middleware which rejects requests without a valid session token or API key
+// with 401 Unauthorized. Requests are authenticated as the user or API key,
+// stored under PrincipalKey, with the user also stored under UserKey. The
+// username, or "apikey:" and the key's prefix, is stored under ActorKey so that
+// changes are audited against them.
+func RequireAuthentication(sessionStore store.SessionRepository, apiKeyStore store.APIKeyRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c)
+		if token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="churchmanager"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		if strings.HasPrefix(token, store.APIKeyPrefix) {
+			key, err := apiKeyStore.ForTenant(requestTenant(c).Id()).FindByKey(c.Request.Context(), token)
+			if err != nil {
+				requestLogger(c).Error("error finding API key", "error", err)
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			if key == nil {
+				c.Header("WWW-Authenticate", `Bearer realm="churchmanager", error="invalid_token"`)
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+
+			c.Set(PrincipalKey, key)
+			c.Set(ActorKey, "apikey:"+key.Prefix())
+			c.Next()
+			return
+		}
+
+		user, err := sessionStore.ForTenant(requestTenant(c).Id()).FindUser(c.Request.Context(), token)
+		if err != nil {
+			requestLogger(c).Error("error finding session", "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if user == nil {
+			c.Header("WWW-Authenticate", `Bearer realm="churchmanager", error="invalid_token"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Set(PrincipalKey, user)
+		c.Set(UserKey, user)
+		c.Set(ActorKey, user.Username())
+		c.Next()
+	}
+}
+
+// Middleware which rejects requests from users or API keys without the
+// permission with 403 Forbidden. Must follow RequireAuthentication.
+func RequirePermission(permission domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := requestPrincipal(c)
+		if principal == nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if !principal.HasPermission(permission) {
+			c.String(http.StatusForbidden, "permission %s is required\n", permission)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// The user authenticated by RequireAuthentication, or nil if the request was
+// not authenticated.
+func requestUser(c *gin.Context) *domain.User {
+	value, _ := c.Get(UserKey)
+	user, _ := value.(*domain.User)
+	return user
+}
+
+// The user or API key authenticated by RequireAuthentication, or nil if the
+// request was not authenticated.
+func requestPrincipal(c *gin.Context) domain.Principal {
+	value, _ := c.Get(PrincipalKey)
+	principal, _ := value.(domain.Principal)
+	return principal
+}
+
+func hasPermission(c *gin.Context, permission domain.Permission) bool {
+	principal := requestPrincipal(c)
+	return principal != nil && principal.HasPermission(permission)
+}
+
+// Gives the member as the requesting user may see it, without its notes unless
+// the user has pastoral access.
+func visibleMember(c *gin.Context, member *domain.Member) *domain.Member {
+	if hasPermission(c, domain.PermissionMemberNotesRead) {
+		return member
+	}
+	return member.WithoutNotes()
+}
+
+// The campuses the request is limited to, or nil if it may see every campus.
+// Only users are limited to campuses, as API keys only have scopes while their
+// creator may see every campus.
+func requestCampusIds(c *gin.Context) []uint64 {
+	user := requestUser(c)
+	if user == nil {
+		return nil
+	}
+	if campusIds := user.CampusIds(); len(campusIds) > 0 {
+		return campusIds
+	}
+	return nil
+}
+
+// Whether the request may see something at the campus, or at no campus when
+// the id is nil.
+func campusAllowed(c *gin.Context, campusId *uint64) bool {
+	return domain.CampusAllowed(requestCampusIds(c), campusId)
+}
+
+// Middleware which rejects users limited to some campuses with 403 Forbidden,
+// for endpoints which are not limited to campuses. Must follow
+// RequireAuthentication.
+func RequireEveryCampus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if requestCampusIds(c) != nil {
+			c.String(http.StatusForbidden, "access to every campus is required\n")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
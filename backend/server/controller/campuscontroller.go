@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+type CampusController struct {
+	store store.CampusRepository
+}
+
+func SetupCampusController(router *gin.RouterGroup, store store.CampusRepository) *CampusController {
+	controller := &CampusController{store: store}
+
+	manage := RequirePermission(domain.PermissionCampusesManage)
+	everyCampus := RequireEveryCampus()
+
+	router.GET("", controller.getCampuses)
+	router.POST("", manage, everyCampus, controller.postCampus)
+	router.PUT(":id", manage, everyCampus, controller.putCampus)
+	router.DELETE(":id", manage, everyCampus, controller.deleteCampus)
+
+	return controller
+}
+
+// getCampuses godoc
+// @Summary      Get every campus of the church.
+// @Description  Campuses are listed in order of name.
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} domain.CampusResponseDTO
+// @Failure      401 Not authenticated
+// @Router       /campuses [get]
+func (controller *CampusController) getCampuses(c *gin.Context) {
+	campuses, err := controller.store.ForTenant(requestTenant(c).Id()).GetAll(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("error getting campuses from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	responseDTOs := make([]domain.CampusResponseDTO, 0)
+
+	for _, campus := range campuses {
+		responseDTOs = append(responseDTOs, *campus.ToResponseDTO())
+	}
+
+	c.JSON(http.StatusOK, responseDTOs)
+}
+
+// Binds and validates the campus in the request body, responding with 400 Bad
+// Request if it is invalid.
+func bindCampusUpdate(c *gin.Context) (*domain.CampusUpdateDTO, bool) {
+	var updateDto domain.CampusUpdateDTO
+
+	if err := c.BindJSON(&updateDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return nil, false
+	}
+
+	if errs := updateDto.Validate(); len(errs) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Failed to validate campus with the following errors:\n")
+		for _, err := range errs {
+			builder.WriteString(err.Error())
+			builder.WriteString("\n")
+		}
+		c.String(http.StatusBadRequest, builder.String())
+		return nil, false
+	}
+
+	return &updateDto, true
+}
+
+// postCampus godoc
+// @Summary      Add a campus
+// @Security     BearerAuth
+// @Param        request body domain.CampusUpdateDTO true "Campus to add"
+// @Accept       json
+// @Produce      json
+// @Success      201 {object} domain.CampusResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or is limited to some campuses
+// @Failure      409 The name is already taken by another campus
+// @Router       /campuses [post]
+func (controller *CampusController) postCampus(c *gin.Context) {
+	createDto, ok := bindCampusUpdate(c)
+	if !ok {
+		return
+	}
+
+	campus, err := controller.store.ForTenant(requestTenant(c).Id()).Create(c.Request.Context(), requestActor(c), createDto)
+	if errors.Is(err, store.ErrCampusNameTaken) {
+		c.String(http.StatusConflict, "campus \"%s\" already exists\n", createDto.Name)
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("failed to create campus", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, campus.ToResponseDTO())
+}
+
+// putCampus godoc
+// @Summary      Rename a campus
+// @Security     BearerAuth
+// @Param        id      path int                    true "The id of the campus"
+// @Param        request body domain.CampusUpdateDTO true "The campus's new name"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} domain.CampusResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or is limited to some campuses
+// @Failure      404 No campus with the given id could be found
+// @Failure      409 The name is already taken by another campus
+// @Router       /campuses/{id} [put]
+func (controller *CampusController) putCampus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	updateDto, ok := bindCampusUpdate(c)
+	if !ok {
+		return
+	}
+
+	campus, err := controller.store.ForTenant(requestTenant(c).Id()).Rename(c.Request.Context(), requestActor(c), id, updateDto)
+	if errors.Is(err, store.ErrCampusNameTaken) {
+		c.String(http.StatusConflict, "campus \"%s\" already exists\n", updateDto.Name)
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("failed to rename campus", "campusId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if campus == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, campus.ToResponseDTO())
+}
+
+// deleteCampus godoc
+// @Summary      Remove a campus
+// @Description  Members and schedules at the campus are left at no campus. Users limited to the campus are not
+// @Description  allowed any more campuses.
+// @Security     BearerAuth
+// @Param        id path int true "The id of the campus"
+// @Success      204
+// @Failure      400 The id could not be parsed into an integer of appropriate size
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or is limited to some campuses
+// @Failure      404 No campus with the given id could be found
+// @Router       /campuses/{id} [delete]
+func (controller *CampusController) deleteCampus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	deleted, err := controller.store.ForTenant(requestTenant(c).Id()).DeleteById(c.Request.Context(), requestActor(c), id)
+	if err != nil {
+		requestLogger(c).Error("error deleting campus", "campusId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !deleted {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Gives the campuses a list is limited to, from the campusId query parameters
+// and the campuses the request is limited to. Responds with 400 Bad Request
+// for an invalid id, or 403 Forbidden for a campus the request may not see.
+// Gives nil for every campus.
+func parseCampusFilter(c *gin.Context) ([]uint64, bool) {
+	allowed := requestCampusIds(c)
+
+	campusIds := make([]uint64, 0)
+	for _, value := range c.QueryArray("campusId") {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.String(http.StatusBadRequest, "invalid campusId \"%s\"\n", value)
+			return nil, false
+		}
+		if allowed != nil && !slices.Contains(allowed, id) {
+			c.String(http.StatusForbidden, "campus %d is not one of your campuses\n", id)
+			return nil, false
+		}
+		campusIds = append(campusIds, id)
+	}
+
+	if len(campusIds) == 0 {
+		return allowed, true
+	}
+	return campusIds, true
+}
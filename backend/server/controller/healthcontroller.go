@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+// Whether the server should be sent requests. It is not ready until it has
+// started, and stops being ready once it begins shutting down so that load
+// balancers stop sending it requests before its connections are drained.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func (readiness *Readiness) SetReady(ready bool) {
+	readiness.ready.Store(ready)
+}
+
+func (readiness *Readiness) Ready() bool {
+	return readiness.ready.Load()
+}
+
+type HealthControllerConfig struct {
+	// Whether the server has started and is not shutting down, or always
+	// ready if nil
+	Readiness *Readiness
+	// The version of the schema the server was migrated to when it started,
+	// or 0 if it is not checked
+	MigrationVersion uint
+}
+
+type HealthController struct {
+	store  store.HealthRepository
+	config *HealthControllerConfig
+}
+
+func SetupHealthController(router gin.IRoutes, store store.HealthRepository, config *HealthControllerConfig) *HealthController {
+	controller := &HealthController{store: store, config: config}
+
+	router.GET("/healthz", controller.getHealth)
+	router.GET("/readyz", controller.getReady)
+
+	return controller
+}
+
+// getHealth godoc
+// @Summary      Check whether the server is running
+// @Description  Does not check the database, so that the server is not restarted while the database is down.
+// @Produce      plain
+// @Success      200
+// @Router       /healthz [get]
+func (controller *HealthController) getHealth(c *gin.Context) {
+	c.String(http.StatusOK, "ok\n")
+}
+
+// getReady godoc
+// @Summary      Check whether the server should be sent requests
+// @Description  Not ready while the server is starting or shutting down, while the database cannot be reached or
+// @Description  while its schema is not at the version the server was migrated to.
+// @Produce      plain
+// @Success      200
+// @Failure      503 The server is not ready, with the reason why
+// @Router       /readyz [get]
+func (controller *HealthController) getReady(c *gin.Context) {
+	if controller.config.Readiness != nil && !controller.config.Readiness.Ready() {
+		c.String(http.StatusServiceUnavailable, "not ready: shutting down\n")
+		return
+	}
+
+	if err := controller.store.Ping(c.Request.Context()); err != nil {
+		requestLogger(c).Error("failed to ping database", "error", err)
+		c.String(http.StatusServiceUnavailable, "not ready: database unavailable\n")
+		return
+	}
+
+	if controller.config.MigrationVersion != 0 {
+		status, err := controller.store.MigrationStatus(c.Request.Context())
+		if err != nil {
+			requestLogger(c).Error("failed to read migration status", "error", err)
+			c.String(http.StatusServiceUnavailable, "not ready: database unavailable\n")
+			return
+		}
+		if !status.IsAt(controller.config.MigrationVersion) {
+			c.String(http.StatusServiceUnavailable, "not ready: database is not at migration version %d\n", controller.config.MigrationVersion)
+			return
+		}
+	}
+
+	c.String(http.StatusOK, "ready\n")
+}
+
+// getMigrations godoc
+// @Summary      Get the version of the database's schema
+// @Description  The database is dirty if a migration failed part of the way through, and has to be fixed by hand.
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} domain.MigrationStatusResponseDTO
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or is limited to some campuses
+// @Router       /admin/migrations [get]
+func (controller *HealthController) GetMigrations(c *gin.Context) {
+	status, err := controller.store.MigrationStatus(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("failed to read migration status", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, status.ToResponseDTO(controller.config.MigrationVersion))
+}
@@ -0,0 +1,68 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// Creates a router whose requests are for the tenant and authenticated as a
+// user with the roles, limited to the campuses if any are given, as the tenant
+// and authentication middleware would.
+func newTestRouter(t *testing.T, tenantId uint64, roles []domain.Role, campusIds ...uint64) *gin.Engine {
+	tenant, err := (&domain.TenantRow{Id: tenantId, Slug: "test", Name: "Test Church"}).ToTenant()
+	if err != nil {
+		t.Fatalf("could not create test tenant: %v", err)
+	}
+	roleNames := make([]string, len(roles))
+	for i, role := range roles {
+		roleNames[i] = string(role)
+	}
+	user, err := (&domain.UserRow{Id: 1, Username: "tester", Roles: roleNames, CampusIds: campusIds}).ToUser()
+	if err != nil {
+		t.Fatalf("could not create test user: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(controller.TenantKey, tenant)
+		c.Set(controller.PrincipalKey, user)
+		c.Set(controller.UserKey, user)
+		c.Set(controller.ActorKey, user.Username())
+	})
+	return router
+}
+
+// Sends a request to the router, encoding the body as JSON if it is not nil
+// and decoding the response into responseBody if it is not nil.
+func serveJSON(t *testing.T, router http.Handler, method string, url string, body any, responseBody any) *httptest.ResponseRecorder {
+	requestData := make([]byte, 0)
+	if body != nil {
+		var err error
+		if requestData, err = json.Marshal(body); err != nil {
+			t.Fatalf("%s %s : failed to encode body: %v", method, url, err)
+		}
+	}
+
+	request := httptest.NewRequest(method, url, bytes.NewReader(requestData))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if responseBody != nil && recorder.Code < 300 {
+		if err := json.Unmarshal(recorder.Body.Bytes(), responseBody); err != nil {
+			t.Fatalf("%s %s : unable to read response json: %v", method, url, err)
+		}
+	}
+	return recorder
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+type MemberChangeController struct {
+	store store.MemberChangeRepository
+}
+
+func SetupMemberChangeController(router *gin.RouterGroup, store store.MemberChangeRepository) *MemberChangeController {
+	controller := &MemberChangeController{store: store}
+
+	write := RequirePermission(domain.PermissionMembersWrite)
+
+	router.GET("", write, controller.getPendingChanges)
+	router.POST(":id/approve", write, controller.approveChange)
+	router.POST(":id/reject", write, controller.rejectChange)
+
+	return controller
+}
+
+// getPendingChanges godoc
+// @Summary      Get the changes members have made to their own details which are waiting for approval.
+// @Description  Changes are listed oldest first.
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} domain.MemberChangeResponseDTO
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /member-changes [get]
+func (controller *MemberChangeController) getPendingChanges(c *gin.Context) {
+	changes, err := controller.store.ForTenant(requestTenant(c).Id()).GetPending(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("error getting pending changes from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	responseDTOs := make([]domain.MemberChangeResponseDTO, 0)
+
+	for _, change := range changes {
+		responseDTOs = append(responseDTOs, *change.ToResponseDTO())
+	}
+
+	c.JSON(http.StatusOK, responseDTOs)
+}
+
+// approveChange godoc
+// @Summary      Approve a change a member made to their own details
+// @Description  The change is made to the member as it is now, keeping every field the change does not set.
+// @Security     BearerAuth
+// @Param        id path int true "The id of the change"
+// @Produce      json
+// @Success      200 {object} domain.MemberChangeResponseDTO
+// @Failure      400 The id could not be parsed into an integer of appropriate size
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Failure      404 No pending change with the given id could be found
+// @Failure      409 The member has since been moved to the trash
+// @Router       /member-changes/{id}/approve [post]
+func (controller *MemberChangeController) approveChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	change, _, err := controller.store.ForTenant(requestTenant(c).Id()).Approve(c.Request.Context(), requestActor(c), id)
+	if errors.Is(err, store.ErrMemberNotFound) {
+		c.String(http.StatusConflict, "the member has been moved to the trash\n")
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("error approving change", "memberChangeId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if change == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, change.ToResponseDTO())
+}
+
+// rejectChange godoc
+// @Summary      Reject a change a member made to their own details
+// @Security     BearerAuth
+// @Param        id path int true "The id of the change"
+// @Produce      json
+// @Success      200 {object} domain.MemberChangeResponseDTO
+// @Failure      400 The id could not be parsed into an integer of appropriate size
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Failure      404 No pending change with the given id could be found
+// @Router       /member-changes/{id}/reject [post]
+func (controller *MemberChangeController) rejectChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	change, err := controller.store.ForTenant(requestTenant(c).Id()).Reject(c.Request.Context(), requestActor(c), id)
+	if err != nil {
+		requestLogger(c).Error("error rejecting change", "memberChangeId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if change == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, change.ToResponseDTO())
+}
@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"log"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,7 +12,8 @@ import (
 )
 
 type MemberController struct {
-	store           *store.MemberStore
+	store           store.MemberRepository
+	auditStore      store.AuditRepository
 	defaultPageSize uint
 	maxPageSize     uint
 }
@@ -22,54 +23,102 @@ type MemberControllerConfig struct {
 	MaxPageSize     uint
 }
 
-func SetupMemberController(router *gin.RouterGroup, store *store.MemberStore, config *MemberControllerConfig) *MemberController {
+func SetupMemberController(
+	router *gin.RouterGroup,
+	store store.MemberRepository,
+	auditStore store.AuditRepository,
+	config *MemberControllerConfig,
+) *MemberController {
 	controller := &MemberController{
 		store:           store,
+		auditStore:      auditStore,
 		maxPageSize:     config.MaxPageSize,
 		defaultPageSize: config.DefaultPageSize,
 	}
 
-	router.GET("", controller.getMembers)
-	router.POST("", controller.postMember)
-	router.GET(":id", controller.getMember)
-	router.PUT(":id", controller.putMember)
-	router.DELETE(":id", controller.deleteMember)
+	read := RequirePermission(domain.PermissionMembersRead)
+	write := RequirePermission(domain.PermissionMembersWrite)
+	// endpoints which work across every member are not for users limited to some campuses
+	everyCampus := RequireEveryCampus()
+
+	router.GET("", read, controller.getMembers)
+	router.POST("", write, controller.postMember)
+	router.GET("trash", read, everyCampus, controller.getTrash)
+	router.GET("duplicates", read, everyCampus, controller.getDuplicates)
+	router.POST("merge", write, everyCampus, controller.mergeMembers)
+	router.POST("import", write, everyCampus, controller.importMembers)
+	router.POST("import/vcard", write, everyCampus, controller.importVCards)
+	router.GET("export", read, controller.exportMembers)
+	router.GET(":id", read, controller.getMember)
+	router.PUT(":id", write, controller.putMember)
+	router.DELETE(":id", write, controller.deleteMember)
+	router.POST(":id/restore", write, everyCampus, controller.restoreMember)
+	router.GET(":id/history", read, everyCampus, controller.getMemberHistory)
 
 	return controller
 }
 
 // getMembers godoc
 // @Summary      Get index of members.
-// @Description  Invalid query parameters are coerced to their default values.
-// @Param        pageSize query int false "The size of the returned page. Maximum value is 500."
-// @Param        page     query int false "The page index (zero-based) to get. Pages that are out of range return emtpy lists."
+// @Description  Invalid paging parameters are coerced to their default values. Users limited to some campuses only
+// @Description  see the members of those campuses.
+// @Param        pageSize query int   false "The size of the returned page. Maximum value is 500."
+// @Param        page     query int   false "The page index (zero-based) to get. Pages that are out of range return emtpy lists."
+// @Param        campusId query []int false "Only include members of these campuses" collectionFormat(multi)
 // @Accept       json
 // @Produce      json
 // @Success      200 {array} domain.MemberResponseDTO
+// @Failure      400 Invalid campus id
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or asked for a campus they are not limited to
 // @Router       /members [get]
 func (controller *MemberController) getMembers(c *gin.Context) {
 	var members []domain.Member
 	var err error
 
-	pageSize64, err := strconv.ParseUint(c.Query("pageSize"), 10, 32)
-	var pageSize uint
-	if err != nil {
-		pageSize = controller.defaultPageSize
-	} else {
-		pageSize = uint(pageSize64)
+	pageSize, page := parsePageParams(c, controller.defaultPageSize, controller.maxPageSize)
+
+	campusIds, ok := parseCampusFilter(c)
+	if !ok {
+		return
 	}
-	pageSize = min(pageSize, controller.maxPageSize)
+	filter := &domain.MemberFilter{CampusIds: campusIds}
 
-	page64, err := strconv.ParseUint(c.Query("page"), 10, 32)
-	var page uint
-	if err != nil {
-		page = 0
-	} else {
-		page = uint(page64)
+	if members, err = controller.store.ForTenant(requestTenant(c).Id()).GetPage(c.Request.Context(), filter, pageSize, page); err != nil {
+		requestLogger(c).Error("error getting members from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	responseDTOs := make([]domain.MemberResponseDTO, 0)
+
+	for _, member := range members {
+		responseDTOs = append(responseDTOs, *visibleMember(c, &member).ToResponseDTO())
 	}
 
-	if members, err = controller.store.GetPage(pageSize, page); err != nil {
-		log.Printf("GET /members : error getting members from database: %v", err)
+	c.JSON(http.StatusOK, responseDTOs)
+}
+
+// getTrash godoc
+// @Summary      Get index of members in the trash.
+// @Description  Deleted members are listed most recently deleted first, until they are purged.
+// @Description  Invalid query parameters are coerced to their default values.
+// @Param        pageSize query int false "The size of the returned page. Maximum value is 500."
+// @Param        page     query int false "The page index (zero-based) to get. Pages that are out of range return emtpy lists."
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} domain.MemberResponseDTO
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/trash [get]
+func (controller *MemberController) getTrash(c *gin.Context) {
+	pageSize, page := parsePageParams(c, controller.defaultPageSize, controller.maxPageSize)
+
+	members, err := controller.store.ForTenant(requestTenant(c).Id()).GetTrashPage(c.Request.Context(), pageSize, page)
+	if err != nil {
+		requestLogger(c).Error("error getting deleted members from database", "error", err)
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
@@ -77,12 +126,113 @@ func (controller *MemberController) getMembers(c *gin.Context) {
 	responseDTOs := make([]domain.MemberResponseDTO, 0)
 
 	for _, member := range members {
-		responseDTOs = append(responseDTOs, *member.ToResponseDTO())
+		responseDTOs = append(responseDTOs, *visibleMember(c, &member).ToResponseDTO())
 	}
 
 	c.JSON(http.StatusOK, responseDTOs)
 }
 
+// The minimum score of the duplicates listed when none is given.
+const defaultDuplicateMinScore = 0.5
+
+// getDuplicates godoc
+// @Summary      Find likely duplicate members.
+// @Description  Pairs of members are scored by name similarity, same email address and same phone number.
+// @Description  Pairs are listed most likely duplicates first. Members in the trash are not considered.
+// @Param        minScore query number false "The minimum score, from 0 to 1, of the pairs listed. Defaults to 0.5."
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} domain.MemberDuplicateResponseDTO
+// @Failure      400 The minimum score could not be parsed or was out of range
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/duplicates [get]
+func (controller *MemberController) getDuplicates(c *gin.Context) {
+	minScore := defaultDuplicateMinScore
+	if minScoreString := c.Query("minScore"); minScoreString != "" {
+		var err error
+		minScore, err = strconv.ParseFloat(minScoreString, 64)
+		if err != nil || minScore < 0 || minScore > 1 {
+			c.String(http.StatusBadRequest, "invalid minScore \"%s\", expected a number from 0 to 1\n", minScoreString)
+			return
+		}
+	}
+
+	members, err := controller.store.ForTenant(requestTenant(c).Id()).GetAll(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("error getting members from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	responseDTOs := make([]domain.MemberDuplicateResponseDTO, 0)
+
+	for _, duplicate := range domain.FindMemberDuplicates(members, minScore) {
+		duplicate.First = visibleMember(c, duplicate.First)
+		duplicate.Second = visibleMember(c, duplicate.Second)
+		responseDTOs = append(responseDTOs, *duplicate.ToResponseDTO())
+	}
+
+	c.JSON(http.StatusOK, responseDTOs)
+}
+
+// mergeMembers godoc
+// @Summary      Merge two members
+// @Description  The survivor takes each field from the member chosen in the request, every reference to the merged
+// @Description  member is re-pointed to the survivor, and the merged member is moved to the trash. A change the merged
+// @Description  member made to their own details which is waiting for approval is rejected.
+// @Param        request body domain.MemberMergeDTO true "The members to merge"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} domain.MemberResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      404 One of the members could not be found outside of the trash
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/merge [post]
+func (controller *MemberController) mergeMembers(c *gin.Context) {
+	var mergeDto domain.MemberMergeDTO
+
+	if err := c.BindJSON(&mergeDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	if errs := mergeDto.Validate(); len(errs) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Failed to validate merge object with the following errors:\n")
+		for _, err := range errs {
+			builder.WriteString(err.Error())
+			builder.WriteString("\n")
+		}
+		c.String(http.StatusBadRequest, builder.String())
+		return
+	}
+
+	if !hasPermission(c, domain.PermissionMemberNotesWrite) {
+		// users without pastoral access cannot see which notes they would keep
+		if mergeDto.Fields == nil {
+			mergeDto.Fields = make(map[string]domain.MemberMergeSource)
+		}
+		mergeDto.Fields["notes"] = domain.MergeFromSurvivor
+	}
+
+	survivor, err := controller.store.ForTenant(requestTenant(c).Id()).Merge(c.Request.Context(), requestActor(c), &mergeDto)
+	if err != nil {
+		requestLogger(c).Error("error merging members", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if survivor == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+	} else {
+		c.JSON(http.StatusOK, visibleMember(c, survivor).ToResponseDTO())
+	}
+}
+
 // getMember godoc
 // @Summary      Get a member
 // @Param        id path int true "The id of the member to get"
@@ -90,34 +240,49 @@ func (controller *MemberController) getMembers(c *gin.Context) {
 // @Produce      json
 // @Success      200 {object} domain.MemberResponseDTO
 // @Failure      400 The id could not be parsed into an integer of appropriate size
+// @Failure      404 No member with the given id could be found at the user's campuses
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
 // @Router       /members/{id} [get]
 func (controller *MemberController) getMember(c *gin.Context) {
+	if strings.HasSuffix(c.Param("id"), vCardExtension) {
+		controller.getMemberVCard(c)
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
 		return
 	}
 
-	member, err := controller.store.FindById(id)
+	member, err := controller.store.ForTenant(requestTenant(c).Id()).FindById(c.Request.Context(), id)
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	if member == nil {
+	if member == nil || !campusAllowed(c, member.CampusId()) {
 		c.AbortWithStatus(http.StatusNotFound)
 	} else {
-		c.JSON(http.StatusOK, member.ToResponseDTO())
+		c.JSON(http.StatusOK, visibleMember(c, member).ToResponseDTO())
 	}
 }
 
 // postMember godoc
 // @Summary      Add a member
+// @Description  Notes are only saved for users with pastoral access. Users limited to some campuses may only add
+// @Description  members to those campuses.
 // @Param        request body domain.MemberUpdateDTO true "Member to add"
 // @Accept       json
 // @Produce      json
 // @Success      201 {object} domain.MemberResponseDTO
 // @Failure      400 Invalid input data
+// @Failure      422 The campus does not exist
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or may not add members to the campus
 // @Router       /members [post]
 func (controller *MemberController) postMember(c *gin.Context) {
 	// Create and update are the same DTO
@@ -139,25 +304,42 @@ func (controller *MemberController) postMember(c *gin.Context) {
 		return
 	}
 
-	member, err := controller.store.Create(&createDto)
+	if !hasPermission(c, domain.PermissionMemberNotesWrite) {
+		createDto.Notes = ""
+	}
+
+	if !campusAllowed(c, createDto.CampusId) {
+		c.String(http.StatusForbidden, "members may only be added to your campuses\n")
+		return
+	}
+
+	member, err := controller.store.ForTenant(requestTenant(c).Id()).Create(c.Request.Context(), requestActor(c), &createDto)
+	if errors.Is(err, store.ErrCampusNotFound) {
+		c.String(http.StatusUnprocessableEntity, "campus %d does not exist\n", *createDto.CampusId)
+		return
+	}
 	if err != nil {
-		log.Printf("failed to create member: %v", err)
+		requestLogger(c).Error("failed to create member", "error", err)
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
 	idString := strconv.FormatUint(member.Id(), 10)
 	c.Header("Location", c.Request.URL.Path+"/"+idString)
-	c.JSON(http.StatusCreated, member.ToResponseDTO())
+	c.JSON(http.StatusCreated, visibleMember(c, member).ToResponseDTO())
 }
 
 // deleteMember godoc
 // @Summary      Delete a member
+// @Description  The member is moved to the trash, from where it can be restored until it is purged.
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Member ID"
 // @Success      200
-// @Failure      404 No member with the given id could be found to delete
+// @Failure      404 No member with the given id could be found at the user's campuses to delete
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
 // @Router       /members/{id} [delete]
 func (controller *MemberController) deleteMember(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -166,9 +348,14 @@ func (controller *MemberController) deleteMember(c *gin.Context) {
 		return
 	}
 
-	deleted, err := controller.store.DeleteById(id)
+	members := controller.store.ForTenant(requestTenant(c).Id())
+	if !controller.memberAtAllowedCampus(c, members, id) {
+		return
+	}
+
+	deleted, err := members.DeleteById(c.Request.Context(), requestActor(c), id)
 	if err != nil {
-		log.Printf("error deleting member by id: %v", err)
+		requestLogger(c).Error("error deleting member by id", "error", err)
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
@@ -180,6 +367,74 @@ func (controller *MemberController) deleteMember(c *gin.Context) {
 	}
 }
 
+// restoreMember godoc
+// @Summary      Restore a member from the trash
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Member ID"
+// @Success      200 {object} domain.MemberResponseDTO
+// @Failure      404 No member with the given id could be found in the trash
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/{id}/restore [post]
+func (controller *MemberController) restoreMember(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	member, err := controller.store.ForTenant(requestTenant(c).Id()).Restore(c.Request.Context(), requestActor(c), id)
+	if err != nil {
+		requestLogger(c).Error("error restoring member by id", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if member == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+	} else {
+		c.JSON(http.StatusOK, visibleMember(c, member).ToResponseDTO())
+	}
+}
+
+// getMemberHistory godoc
+// @Summary      Get the change history of a member
+// @Description  Entries are listed most recent first, and remain available after the member is purged.
+// @Description  Invalid paging parameters are coerced to their default values.
+// @Param        id       path  int true  "Member ID"
+// @Param        pageSize query int false "The size of the returned page. Maximum value is 500."
+// @Param        page     query int false "The page index (zero-based) to get."
+// @Accept       json
+// @Produce      json
+// @Success      200 {array} domain.AuditEntryResponseDTO
+// @Failure      400 The id could not be parsed into an integer of appropriate size
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/{id}/history [get]
+func (controller *MemberController) getMemberHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	pageSize, page := parsePageParams(c, controller.defaultPageSize, controller.maxPageSize)
+	entityType := domain.AuditEntityMember
+	filter := &domain.AuditFilter{EntityType: &entityType, EntityId: &id}
+
+	entries, err := controller.auditStore.ForTenant(requestTenant(c).Id()).GetPage(c.Request.Context(), filter, pageSize, page)
+	if err != nil {
+		requestLogger(c).Error("error getting member history from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, auditEntriesToResponseDTOs(c, entries))
+}
+
 type putMember struct {
 	Id uint64 `uri:"id" binding:"required"`
 	domain.MemberUpdateDTO
@@ -187,11 +442,19 @@ type putMember struct {
 
 // putMember godoc
 // @Summary      Update a member
+// @Description  Users without pastoral access cannot change the member's notes, which are kept as they were. Users
+// @Description  limited to some campuses may only update members of those campuses, and keep them there.
 // @Param        request body domain.MemberUpdateDTO true "New data for the member. This operation replaces the member entirely."
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Member ID"
 // @Success      200 {object} domain.MemberResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      404 No member with the given id could be found at the user's campuses to update
+// @Failure      422 The campus does not exist
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or may not move the member to the campus
 // @Router       /members/{id} [put]
 func (c *MemberController) putMember(ctx *gin.Context) {
 	var request putMember
@@ -206,12 +469,69 @@ func (c *MemberController) putMember(ctx *gin.Context) {
 		return
 	}
 
-	member, err := c.store.Update(request.Id, &request.MemberUpdateDTO)
+	if errs := request.MemberUpdateDTO.Validate(); len(errs) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Failed to validate update object with the following errors:\n")
+		for _, err := range errs {
+			builder.WriteString(err.Error())
+			builder.WriteString("\n")
+		}
+		ctx.String(http.StatusBadRequest, builder.String())
+		return
+	}
+
+	members := c.store.ForTenant(requestTenant(ctx).Id())
+	if !c.memberAtAllowedCampus(ctx, members, request.Id) {
+		return
+	}
+	if !campusAllowed(ctx, request.CampusId) {
+		ctx.String(http.StatusForbidden, "members may only be moved to your campuses\n")
+		return
+	}
+
+	update := members.Update
+	if !hasPermission(ctx, domain.PermissionMemberNotesWrite) {
+		update = members.UpdateExceptNotes
+	}
+
+	member, err := update(ctx.Request.Context(), requestActor(ctx), request.Id, &request.MemberUpdateDTO)
+	if errors.Is(err, store.ErrCampusNotFound) {
+		ctx.String(http.StatusUnprocessableEntity, "campus %d does not exist\n", *request.CampusId)
+		return
+	}
 	if err != nil {
-		log.Printf("error updating member: %v", err)
+		requestLogger(ctx).Error("error updating member", "error", err)
 		ctx.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, member.ToResponseDTO())
+	if member == nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, visibleMember(ctx, member).ToResponseDTO())
+}
+
+// Checks that a member outside of the trash is at one of the campuses the
+// request is limited to, responding with 404 Not Found if it is not, as
+// though the member did not exist. Always passes for requests allowed every
+// campus, leaving missing members to the caller.
+func (controller *MemberController) memberAtAllowedCampus(c *gin.Context, members store.MemberRepository, id uint64) bool {
+	if requestCampusIds(c) == nil {
+		return true
+	}
+
+	member, err := members.FindById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("error finding member to check its campus", "memberId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return false
+	}
+
+	if member == nil || !campusAllowed(c, member.CampusId()) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return false
+	}
+	return true
 }
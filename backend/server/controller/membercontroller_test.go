@@ -0,0 +1,162 @@
+package controller_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/memory"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+	"github.com/gin-gonic/gin"
+)
+
+var testMemberConfig = controller.MemberControllerConfig{DefaultPageSize: 2, MaxPageSize: 10}
+
+// An audit log without entries, as the memory stores do not audit their
+// changes.
+type emptyAuditLog struct{}
+
+func (log emptyAuditLog) ForTenant(tenantId uint64) store.AuditRepository {
+	return log
+}
+
+func (emptyAuditLog) GetPage(ctx context.Context, filter *domain.AuditFilter, pageSize uint, page uint) ([]domain.AuditEntry, error) {
+	return []domain.AuditEntry{}, nil
+}
+
+// Sets up the member endpoints over the repository for a user of the tenant.
+// The audit log is always empty, so history is not tested here.
+func newMemberRouter(t *testing.T, members store.MemberRepository, tenantId uint64, roles []domain.Role, campusIds ...uint64) *gin.Engine {
+	router := newTestRouter(t, tenantId, roles, campusIds...)
+	controller.SetupMemberController(router.Group("/members"), members, emptyAuditLog{}, &testMemberConfig)
+	return router
+}
+
+func TestMemberLifecycle(t *testing.T) {
+	router := newMemberRouter(t, memory.CreateMemberStore(), 1, []domain.Role{domain.RoleAdmin})
+
+	var created domain.MemberResponseDTO
+	response := serveJSON(t, router, "POST", "/members", &domain.MemberUpdateDTO{
+		FirstName: util.NewPtr("Monica"),
+		Notes:     "Prays for her son",
+	}, &created)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("expected POST /members to be 201 Created, but was %d: %s", response.Code, response.Body)
+	}
+	if location := response.Header().Get("Location"); location != fmt.Sprintf("/members/%d", created.Id) {
+		t.Errorf("expected the location of the new member, got %s", location)
+	}
+
+	var updated domain.MemberResponseDTO
+	response = serveJSON(t, router, "PUT", fmt.Sprintf("/members/%d", created.Id), &domain.MemberUpdateDTO{
+		FirstName: util.NewPtr("Monica"),
+		LastName:  util.NewPtr("of Hippo"),
+	}, &updated)
+	if response.Code != http.StatusOK || updated.LastName == nil || *updated.LastName != "of Hippo" || updated.Notes != "" {
+		t.Errorf("expected the member to be replaced, got %d %+v", response.Code, updated)
+	}
+
+	response = serveJSON(t, router, "DELETE", fmt.Sprintf("/members/%d", created.Id), nil, nil)
+	if response.Code != http.StatusOK {
+		t.Errorf("expected DELETE to be 200 OK, but was %d", response.Code)
+	}
+	response = serveJSON(t, router, "GET", fmt.Sprintf("/members/%d", created.Id), nil, nil)
+	if response.Code != http.StatusNotFound {
+		t.Errorf("expected a member in the trash to be 404 Not Found, but was %d", response.Code)
+	}
+
+	var trash []domain.MemberResponseDTO
+	serveJSON(t, router, "GET", "/members/trash", nil, &trash)
+	if len(trash) != 1 || trash[0].Id != created.Id || trash[0].DeletedAt == nil {
+		t.Errorf("expected the member in the trash, got %+v", trash)
+	}
+
+	response = serveJSON(t, router, "POST", fmt.Sprintf("/members/%d/restore", created.Id), nil, nil)
+	if response.Code != http.StatusOK {
+		t.Errorf("expected restoring the member to be 200 OK, but was %d", response.Code)
+	}
+	response = serveJSON(t, router, "GET", fmt.Sprintf("/members/%d", created.Id), nil, nil)
+	if response.Code != http.StatusOK {
+		t.Errorf("expected the restored member to be found, but was %d", response.Code)
+	}
+}
+
+func TestMemberPagingAndSearch(t *testing.T) {
+	router := newMemberRouter(t, memory.CreateMemberStore(), 1, []domain.Role{domain.RoleAdmin})
+	for _, name := range []string{"Ambrose", "Jerome", "Gregory", "Ambrosiaster"} {
+		serveJSON(t, router, "POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr(name)}, nil)
+	}
+
+	var page []domain.MemberResponseDTO
+	serveJSON(t, router, "GET", "/members?page=1", nil, &page)
+	if len(page) != 2 || *page[0].FirstName != "Gregory" || *page[1].FirstName != "Ambrosiaster" {
+		t.Errorf("expected the second page of the default size in order of id, got %+v", page)
+	}
+
+	response := serveJSON(t, router, "GET", "/members/export?format=jsonl&columns=firstName&search=AMBROS", nil, nil)
+	if lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n"); len(lines) != 2 {
+		t.Errorf("expected the search to match names case-insensitively, got %q", response.Body)
+	}
+}
+
+func TestMemberNotesNeedPastoralAccess(t *testing.T) {
+	members := memory.CreateMemberStore()
+	pastor := newMemberRouter(t, members, 1, []domain.Role{domain.RolePastor})
+	welcome := newMemberRouter(t, members, 1, []domain.Role{domain.RoleWelcomeTeam})
+
+	var created domain.MemberResponseDTO
+	serveJSON(t, pastor, "POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Alypius"), Notes: "Old friend"}, &created)
+	url := fmt.Sprintf("/members/%d", created.Id)
+
+	var redacted domain.MemberResponseDTO
+	serveJSON(t, welcome, "GET", url, nil, &redacted)
+	if redacted.Notes != "" || !redacted.NotesRedacted {
+		t.Errorf("expected the notes to be withheld from the welcome team, got %+v", redacted)
+	}
+
+	serveJSON(t, welcome, "PUT", url, &domain.MemberUpdateDTO{FirstName: util.NewPtr("Alypius"), Notes: "Overwritten"}, nil)
+	var kept domain.MemberResponseDTO
+	serveJSON(t, pastor, "GET", url, nil, &kept)
+	if kept.Notes != "Old friend" {
+		t.Errorf("expected the welcome team's update to keep the notes, got %q", kept.Notes)
+	}
+}
+
+func TestMembersAreLimitedByTenantAndCampus(t *testing.T) {
+	members := memory.CreateMemberStore()
+	admin := newMemberRouter(t, members, 1, []domain.Role{domain.RoleAdmin})
+	otherChurch := newMemberRouter(t, members, 2, []domain.Role{domain.RoleAdmin})
+	north := newMemberRouter(t, members, 1, []domain.Role{domain.RoleWelcomeTeam}, 10)
+
+	var atNorth, atSouth domain.MemberResponseDTO
+	serveJSON(t, admin, "POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Possidius"), CampusId: util.NewPtr[uint64](10)}, &atNorth)
+	serveJSON(t, admin, "POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Evodius"), CampusId: util.NewPtr[uint64](20)}, &atSouth)
+
+	var seen []domain.MemberResponseDTO
+	serveJSON(t, otherChurch, "GET", "/members", nil, &seen)
+	if len(seen) != 0 {
+		t.Errorf("expected another church to see none of the members, got %+v", seen)
+	}
+
+	serveJSON(t, north, "GET", "/members?pageSize=10", nil, &seen)
+	if len(seen) != 1 || seen[0].Id != atNorth.Id {
+		t.Errorf("expected a user limited to the north campus to only see its member, got %+v", seen)
+	}
+	response := serveJSON(t, north, "GET", fmt.Sprintf("/members/%d", atSouth.Id), nil, nil)
+	if response.Code != http.StatusNotFound {
+		t.Errorf("expected a member of another campus to be 404 Not Found, but was %d", response.Code)
+	}
+	response = serveJSON(t, north, "POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Severus"), CampusId: util.NewPtr[uint64](20)}, nil)
+	if response.Code != http.StatusForbidden {
+		t.Errorf("expected adding a member to another campus to be 403 Forbidden, but was %d", response.Code)
+	}
+	response = serveJSON(t, north, "GET", "/members/trash", nil, nil)
+	if response.Code != http.StatusForbidden {
+		t.Errorf("expected the trash to be 403 Forbidden to a user limited to a campus, but was %d", response.Code)
+	}
+}
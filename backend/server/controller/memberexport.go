@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/memberio"
+	"github.com/gin-gonic/gin"
+)
+
+// exportMembers godoc
+// @Summary      Export members as a spreadsheet or JSON Lines
+// @Description  Members are streamed as they are read from the database, so every member can be exported at once.
+// @Description  Members in the trash are not exported. Users limited to some campuses only export the members of those
+// @Description  campuses.
+// @Param        format   query string false "The file format of the export" Enums(csv, xlsx, jsonl) default(csv)
+// @Param        columns  query string false "Comma separated columns to export, in order. Defaults to every column the user may read." example(firstName,lastName,emailAddress)
+// @Param        search   query string false "Only export members with this text in their name or email address"
+// @Param        campusId query []int  false "Only export members of these campuses" collectionFormat(multi)
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/jsonl
+// @Success      200 {file} file "The exported members"
+// @Failure      400 Unknown format, column or campus id
+// @Failure      403 The notes column was asked for by a user who may not read notes, or a campus by a user not limited to it
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/export [get]
+func (controller *MemberController) exportMembers(c *gin.Context) {
+	format := memberio.ExportFormat(c.DefaultQuery("format", string(memberio.ExportCSV)))
+	switch format {
+	case memberio.ExportCSV, memberio.ExportXLSX, memberio.ExportJSONL:
+	default:
+		c.String(http.StatusBadRequest, "unknown format \"%s\", expected csv, xlsx or jsonl\n", format)
+		return
+	}
+
+	names := memberio.ParseColumnNames(c.Query("columns"))
+	columns, err := memberio.SelectExportColumns(names)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	if !hasPermission(c, domain.PermissionMemberNotesRead) {
+		visibleColumns := make([]memberio.ExportColumn, 0, len(columns))
+		for _, column := range columns {
+			if column.Name != "notes" {
+				visibleColumns = append(visibleColumns, column)
+			} else if len(names) > 0 {
+				c.String(http.StatusForbidden, "permission %s is required to export notes\n", domain.PermissionMemberNotesRead)
+				return
+			}
+		}
+		columns = visibleColumns
+	}
+
+	campusIds, ok := parseCampusFilter(c)
+	if !ok {
+		return
+	}
+
+	filter := &domain.MemberFilter{CampusIds: campusIds}
+	if search := c.Query("search"); search != "" {
+		filter.Search = &search
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", "attachment; filename=\"members."+string(format)+"\"")
+	c.Status(http.StatusOK)
+
+	writer, err := memberio.NewExportWriter(c.Writer, format, columns)
+	if err != nil {
+		requestLogger(c).Error("error starting export", "error", err)
+		return
+	}
+
+	err = controller.store.ForTenant(requestTenant(c).Id()).Stream(c.Request.Context(), filter, writer.Write)
+	if err != nil {
+		// the response has already begun, so the export is left incomplete
+		requestLogger(c).Error("error exporting members", "error", err)
+		return
+	}
+
+	if err = writer.Close(); err != nil {
+		requestLogger(c).Error("error finishing export", "error", err)
+	}
+}
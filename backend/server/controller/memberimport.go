@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/memberio"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	importModeDryRun = "dry-run"
+	importModeCommit = "commit"
+)
+
+// importMembers godoc
+// @Summary      Import members from a CSV file
+// @Description  Each row is validated and checked for likely duplicates of existing members. In dry-run mode
+// @Description  nothing is imported. In commit mode every row is imported in a single transaction, except rows
+// @Description  which are likely duplicates unless allowDuplicates is set; if any row is invalid nothing is imported.
+// @Param        file            formData file   true  "CSV file with a header row"
+// @Param        mapping         formData string false "JSON object mapping member fields to column headers, e.g. {\"firstName\": \"Given name\"}. Defaults to columns named after the fields."
+// @Param        mode            query    string false "Whether to only validate the file or to import it" Enums(dry-run, commit) default(dry-run)
+// @Param        allowDuplicates query    bool   false "Import rows which are likely duplicates of existing members"
+// @Accept       mpfd
+// @Produce      json
+// @Success      200 {object} domain.MemberImportResponseDTO "Results of a dry run"
+// @Success      201 {object} domain.MemberImportResponseDTO "Results of a committed import"
+// @Failure      400 The file or mapping could not be read
+// @Failure      422 {object} domain.MemberImportResponseDTO "Some rows were invalid so nothing was imported"
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/import [post]
+func (controller *MemberController) importMembers(c *gin.Context) {
+	mode := c.DefaultQuery("mode", importModeDryRun)
+	if mode != importModeDryRun && mode != importModeCommit {
+		c.String(http.StatusBadRequest, "invalid mode \"%s\", expected \"%s\" or \"%s\"\n", mode, importModeDryRun, importModeCommit)
+		return
+	}
+	allowDuplicates := c.Query("allowDuplicates") == "true"
+
+	var mapping memberio.CSVMapping
+	if mappingJson := c.PostForm("mapping"); mappingJson != "" {
+		if err := json.Unmarshal([]byte(mappingJson), &mapping); err != nil {
+			c.String(http.StatusBadRequest, "invalid mapping: %v\n", err)
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.String(http.StatusBadRequest, "expected a CSV file in form field \"file\": %v\n", err)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		requestLogger(c).Error("error opening uploaded import file", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	rows, err := memberio.ReadCSV(file, mapping)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	controller.respondToImport(c, rows, mode == importModeDryRun, allowDuplicates)
+}
+
+// Validates and checks the imported rows for duplicates of existing members,
+// creating the members unless this is a dry run.
+func (controller *MemberController) respondToImport(c *gin.Context, rows []memberio.ImportRow, dryRun bool, allowDuplicates bool) {
+	if !hasPermission(c, domain.PermissionMemberNotesWrite) {
+		for i := range rows {
+			rows[i].Member.Notes = ""
+		}
+	}
+
+	existing, err := controller.store.ForTenant(requestTenant(c).Id()).GetAll(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("error getting members to check import for duplicates", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	candidates := make([]domain.Member, len(rows))
+	for i, row := range rows {
+		memberRow := domain.MemberRow{
+			FirstName:    row.Member.FirstName,
+			LastName:     row.Member.LastName,
+			EmailAddress: row.Member.EmailAddress,
+			PhoneNumber:  row.Member.PhoneNumber,
+			Address:      row.Member.Address,
+			Notes:        row.Member.Notes,
+		}
+		candidate, err := memberRow.ToMember()
+		if err != nil {
+			requestLogger(c).Error("error converting imported row to member", "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		candidates[i] = *candidate
+	}
+
+	candidateIndices := make(map[*domain.Member]int)
+	for i := range candidates {
+		candidateIndices[&candidates[i]] = i
+	}
+
+	response := domain.MemberImportResponseDTO{
+		DryRun: dryRun,
+		Rows:   make([]domain.MemberImportRowResponseDTO, len(rows)),
+	}
+	for i, row := range rows {
+		response.Rows[i] = domain.MemberImportRowResponseDTO{
+			Line:       row.Line,
+			Status:     domain.ImportRowValid,
+			Member:     row.Member,
+			Errors:     make([]string, 0),
+			Duplicates: make([]domain.MemberDuplicateResponseDTO, 0),
+		}
+		for _, err := range row.Member.Validate() {
+			response.Rows[i].Errors = append(response.Rows[i].Errors, err.Error())
+			response.Rows[i].Status = domain.ImportRowInvalid
+		}
+	}
+
+	for _, duplicate := range domain.FindMemberDuplicatesOf(candidates, existing, defaultDuplicateMinScore) {
+		result := &response.Rows[candidateIndices[duplicate.First]]
+		duplicate.Second = visibleMember(c, duplicate.Second)
+		result.Duplicates = append(result.Duplicates, *duplicate.ToResponseDTO())
+		if result.Status == domain.ImportRowValid {
+			result.Status = domain.ImportRowDuplicate
+		}
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	toCreate := make([]domain.MemberUpdateDTO, 0)
+	toCreateRows := make([]int, 0)
+	for i := range response.Rows {
+		result := &response.Rows[i]
+		switch result.Status {
+		case domain.ImportRowInvalid:
+			c.JSON(http.StatusUnprocessableEntity, response)
+			return
+		case domain.ImportRowDuplicate:
+			if !allowDuplicates {
+				result.Status = domain.ImportRowSkipped
+				continue
+			}
+		}
+		toCreate = append(toCreate, result.Member)
+		toCreateRows = append(toCreateRows, i)
+	}
+
+	created, err := controller.store.ForTenant(requestTenant(c).Id()).CreateMany(c.Request.Context(), requestActor(c), toCreate)
+	if err != nil {
+		requestLogger(c).Error("error creating imported members", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	for i, member := range created {
+		result := &response.Rows[toCreateRows[i]]
+		result.Status = domain.ImportRowImported
+		id := member.Id()
+		result.CreatedId = &id
+	}
+	response.Imported = len(created)
+
+	c.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/memberio"
+	"github.com/gin-gonic/gin"
+)
+
+const vCardExtension = ".vcf"
+
+// getMemberVCard godoc
+// @Summary      Get a member as a vCard
+// @Param        id path int true "The id of the member to get"
+// @Produce      text/vcard
+// @Success      200 {string} string "vCard 3.0 of the member"
+// @Failure      400 The id could not be parsed into an integer of appropriate size
+// @Failure      404 No member with the given id could be found at the user's campuses
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/{id}.vcf [get]
+func (controller *MemberController) getMemberVCard(c *gin.Context) {
+	idString := strings.TrimSuffix(c.Param("id"), vCardExtension)
+	id, err := strconv.ParseUint(idString, 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", idString)
+		return
+	}
+
+	member, err := controller.store.ForTenant(requestTenant(c).Id()).FindById(c.Request.Context(), id)
+	if err != nil {
+		requestLogger(c).Error("error getting member for vCard", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if member == nil || !campusAllowed(c, member.CampusId()) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Content-Type", memberio.VCardContentType)
+	c.Header("Content-Disposition", "attachment; filename=\"member-"+idString+vCardExtension+"\"")
+	c.Status(http.StatusOK)
+	if err = memberio.WriteVCard(c.Writer, visibleMember(c, member)); err != nil {
+		requestLogger(c).Error("error writing vCard", "error", err)
+	}
+}
+
+// GetMembersVCard godoc
+// @Summary      Get every member as vCards
+// @Description  Members are streamed as they are read from the database. Members in the trash are not included.
+// @Description  Users limited to some campuses only get the members of those campuses.
+// @Param        search   query string false "Only include members with this text in their name or email address"
+// @Param        campusId query []int  false "Only include members of these campuses" collectionFormat(multi)
+// @Produce      text/vcard
+// @Success      200 {string} string "One vCard 3.0 per member"
+// @Failure      400 Invalid campus id
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or asked for a campus they are not limited to
+// @Router       /members.vcf [get]
+func (controller *MemberController) GetMembersVCard(c *gin.Context) {
+	campusIds, ok := parseCampusFilter(c)
+	if !ok {
+		return
+	}
+
+	filter := &domain.MemberFilter{CampusIds: campusIds}
+	if search := c.Query("search"); search != "" {
+		filter.Search = &search
+	}
+
+	c.Header("Content-Type", memberio.VCardContentType)
+	c.Header("Content-Disposition", "attachment; filename=\"members"+vCardExtension+"\"")
+	c.Status(http.StatusOK)
+
+	err := controller.store.ForTenant(requestTenant(c).Id()).Stream(c.Request.Context(), filter, func(member *domain.Member) error {
+		return memberio.WriteVCard(c.Writer, visibleMember(c, member))
+	})
+	if err != nil {
+		// the response has already begun, so the vCards are left incomplete
+		requestLogger(c).Error("error writing vCards", "error", err)
+	}
+}
+
+// importVCards godoc
+// @Summary      Import members from vCards
+// @Description  Reads the N, EMAIL, TEL, ADR and NOTE properties of each vCard 3.0 or 4.0 in the file. Rows are
+// @Description  validated, checked for duplicates and imported as for the CSV import.
+// @Param        file            formData file   true  "File of one or more vCards"
+// @Param        mode            query    string false "Whether to only validate the file or to import it" Enums(dry-run, commit) default(dry-run)
+// @Param        allowDuplicates query    bool   false "Import vCards which are likely duplicates of existing members"
+// @Accept       mpfd
+// @Produce      json
+// @Success      200 {object} domain.MemberImportResponseDTO "Results of a dry run"
+// @Success      201 {object} domain.MemberImportResponseDTO "Results of a committed import"
+// @Failure      400 The file could not be read
+// @Failure      422 {object} domain.MemberImportResponseDTO "Some vCards were invalid so nothing was imported"
+// @Security     BearerAuth
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint
+// @Router       /members/import/vcard [post]
+func (controller *MemberController) importVCards(c *gin.Context) {
+	mode := c.DefaultQuery("mode", importModeDryRun)
+	if mode != importModeDryRun && mode != importModeCommit {
+		c.String(http.StatusBadRequest, "invalid mode \"%s\", expected \"%s\" or \"%s\"\n", mode, importModeDryRun, importModeCommit)
+		return
+	}
+	allowDuplicates := c.Query("allowDuplicates") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.String(http.StatusBadRequest, "expected a vCard file in form field \"file\": %v\n", err)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		requestLogger(c).Error("error opening uploaded vCard file", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	rows, err := memberio.ReadVCards(file)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	controller.respondToImport(c, rows, mode == importModeDryRun, allowDuplicates)
+}
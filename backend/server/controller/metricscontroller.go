@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/metrics"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsControllerConfig struct {
+	// The requests counted by CountRequests
+	Requests *metrics.Requests
+	// The bearer token scrapes must give. The metrics are not served at all
+	// without one, as they show how every church uses the server.
+	Token    string
+	Database metrics.DatabaseConfig
+}
+
+type MetricsController struct {
+	database *metrics.Database
+	handler  http.Handler
+	config   *MetricsControllerConfig
+}
+
+// Middleware which counts and times each request by its route.
+func CountRequests(requests *metrics.Requests) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		requests.Observe(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+func SetupMetricsController(
+	router gin.IRoutes,
+	tenants store.TenantRepository,
+	members store.MemberRepository,
+	schedules store.ScheduleRepository,
+	health store.HealthRepository,
+	config *MetricsControllerConfig,
+) *MetricsController {
+	database := metrics.NewDatabase(tenants, members, schedules, health, &config.Database)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		config.Requests,
+		database,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	controller := &MetricsController{
+		database: database,
+		handler:  promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		config:   config,
+	}
+
+	if config.Token != "" {
+		router.GET("/metrics", controller.getMetrics)
+	}
+
+	return controller
+}
+
+// getMetrics godoc
+// @Summary      Get the server's metrics for Prometheus
+// @Description  Requests are counted and timed by route, along with how the database's connections are used, the
+// @Description  version of its schema and the members and schedules of the churches, which are read from the database
+// @Description  at most once each refresh interval. Metrics read from the database are left out while it cannot be
+// @Description  read. Only served when a bearer token is configured, which has to be given.
+// @Produce      plain
+// @Success      200
+// @Failure      401 The configured bearer token was not given
+// @Failure      404 No bearer token is configured
+// @Router       /metrics [get]
+func (controller *MetricsController) getMetrics(c *gin.Context) {
+	if subtle.ConstantTimeCompare([]byte(rawBearerToken(c)), []byte(controller.config.Token)) != 1 {
+		c.Header("WWW-Authenticate", `Bearer realm="churchmanager metrics"`)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if err := controller.database.Refresh(c.Request.Context()); err != nil {
+		requestLogger(c).Error("failed to read the database's metrics", "error", err)
+	}
+	controller.handler.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/oidc"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+// How long a user has to log in at a provider after starting a login.
+const oidcLoginDuration = 10 * time.Minute
+
+func (controller *AuthController) setupOIDC(router *gin.RouterGroup, configs []oidc.ProviderConfig) {
+	controller.oidcProviders = make(map[string]*oidc.Provider)
+	for _, config := range configs {
+		controller.oidcProviders[config.Name] = oidc.NewProvider(config, nil)
+	}
+
+	router.GET("oidc/providers", controller.getOIDCProviders)
+	router.POST("oidc/:provider/start", controller.startOIDCLogin)
+	router.POST("oidc/:provider/callback", controller.finishOIDCLogin)
+}
+
+// Gives the provider named in the path if the church of the request logs in
+// with it.
+func (controller *AuthController) requestProvider(c *gin.Context) (*oidc.Provider, bool) {
+	provider, ok := controller.oidcProviders[c.Param("provider")]
+	if !ok || !provider.ServesTenant(requestTenant(c).Slug()) {
+		return nil, false
+	}
+	return provider, true
+}
+
+// getOIDCProviders godoc
+// @Summary      List the single sign-on providers users of the church may log in with.
+// @Produce      json
+// @Success      200 {array} string
+// @Router       /auth/oidc/providers [get]
+func (controller *AuthController) getOIDCProviders(c *gin.Context) {
+	names := make([]string, 0, len(controller.oidcProviders))
+	for name, provider := range controller.oidcProviders {
+		if provider.ServesTenant(requestTenant(c).Slug()) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	c.JSON(http.StatusOK, names)
+}
+
+// startOIDCLogin godoc
+// @Summary      Start logging in with a single sign-on provider.
+// @Description  The user is sent to the returned URL, from which the provider redirects back with a code and the
+// @Description  state. The code and state are then given to the callback endpoint within 10 minutes.
+// @Param        provider path string true "The name of the provider"
+// @Produce      json
+// @Success      200 {object} domain.OIDCStartResponseDTO
+// @Failure      404 No provider with the given name is configured for the church
+// @Failure      502 The provider could not be reached
+// @Router       /auth/oidc/{provider}/start [post]
+func (controller *AuthController) startOIDCLogin(c *gin.Context) {
+	provider, ok := controller.requestProvider(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	values := make([]string, 3)
+	for i := range values {
+		var err error
+		if values[i], err = oidc.GenerateRandomValue(); err != nil {
+			requestLogger(c).Error("error generating login values", "provider", provider.Name(), "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+	state, codeVerifier, nonce := values[0], values[1], values[2]
+
+	authorizationURL, err := provider.AuthorizationURL(c.Request.Context(), state, nonce, codeVerifier)
+	if err != nil {
+		requestLogger(c).Error("error building authorization URL", "provider", provider.Name(), "error", err)
+		c.AbortWithStatus(http.StatusBadGateway)
+		return
+	}
+
+	err = controller.oidcLoginStore.ForTenant(requestTenant(c).Id()).Create(c.Request.Context(), state, &domain.OIDCLogin{
+		Provider:     provider.Name(),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+	}, oidcLoginDuration)
+	if err != nil {
+		requestLogger(c).Error("error storing login", "provider", provider.Name(), "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.OIDCStartResponseDTO{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+	})
+}
+
+// finishOIDCLogin godoc
+// @Summary      Finish logging in with a single sign-on provider.
+// @Description  Users are created the first time they log in, named after their preferred username or verified email address.
+// @Description  Their roles are mapped from the provider's claims on every login.
+// @Param        provider path string                 true "The name of the provider"
+// @Param        request  body domain.OIDCCallbackDTO true "The code and state the provider redirected back with"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} domain.LoginResponseDTO
+// @Failure      400 The state does not belong to an unfinished login with the provider
+// @Failure      401 The provider did not accept the code or gave an invalid ID token
+// @Failure      403 The user has no roles, or no usable username
+// @Failure      404 No provider with the given name is configured for the church
+// @Failure      409 The username is already taken by another user
+// @Failure      502 The provider could not be reached
+// @Router       /auth/oidc/{provider}/callback [post]
+func (controller *AuthController) finishOIDCLogin(c *gin.Context) {
+	provider, ok := controller.requestProvider(c)
+	if !ok {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	var callbackDto domain.OIDCCallbackDTO
+
+	if err := c.BindJSON(&callbackDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	login, err := controller.oidcLoginStore.ForTenant(requestTenant(c).Id()).Take(c.Request.Context(), callbackDto.State)
+	if err != nil {
+		requestLogger(c).Error("error finding login", "provider", provider.Name(), "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if login == nil || login.Provider != provider.Name() {
+		c.String(http.StatusBadRequest, "unknown or expired login, start the login again\n")
+		return
+	}
+
+	claims, err := provider.Exchange(c.Request.Context(), callbackDto.Code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		requestLogger(c).Error("error exchanging code", "provider", provider.Name(), "error", err)
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			c.AbortWithStatus(http.StatusBadGateway)
+		} else {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+		return
+	}
+
+	roles := provider.Roles(claims)
+	if len(roles) == 0 {
+		c.String(http.StatusForbidden, "no roles are given to this user by %s\n", provider.Name())
+		return
+	}
+
+	username := claims.PreferredUsername
+	if username == "" {
+		// anyone could be named after an address they do not own
+		if !claims.EmailVerified {
+			c.String(http.StatusForbidden, "no username or verified email address is given by %s\n", provider.Name())
+			return
+		}
+		username = claims.Email
+	}
+	username = domain.NormaliseUsername(username)
+	if err = domain.ValidateUsername(username); err != nil {
+		c.String(http.StatusForbidden, "no usable username is given by %s: %v\n", provider.Name(), err)
+		return
+	}
+
+	user, err := controller.userStore.ForTenant(requestTenant(c).Id()).ProvisionOIDCUser(c.Request.Context(), provider.Issuer(), claims.Subject, username, roles)
+	if errors.Is(err, store.ErrUsernameTaken) {
+		c.String(http.StatusConflict, "username \"%s\" is already taken by another user\n", username)
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("error provisioning user", "provider", provider.Name(), "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	controller.startSession(c, user)
+}
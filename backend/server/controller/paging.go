@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reads the pageSize and page query parameters, coercing invalid values to
+// their defaults.
+func parsePageParams(c *gin.Context, defaultPageSize uint, maxPageSize uint) (pageSize uint, page uint) {
+	pageSize64, err := strconv.ParseUint(c.Query("pageSize"), 10, 32)
+	if err != nil {
+		pageSize = defaultPageSize
+	} else {
+		pageSize = uint(pageSize64)
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	page64, err := strconv.ParseUint(c.Query("page"), 10, 32)
+	if err != nil {
+		page = 0
+	} else {
+		page = uint(page64)
+	}
+
+	return pageSize, page
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// The header a request's id is taken from, if the client or a proxy in front
+// of the server gave it one, and is returned in.
+const RequestIdHeader = "X-Request-ID"
+
+// The longest request id taken from a request, as the id is logged with
+// everything logged for the request.
+const maxRequestIdLength = 128
+
+// Whether the id given with a request is short and printable enough to log.
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, char := range []byte(requestId) {
+		if char < '!' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Middleware which gives each request an id, taken from its X-Request-ID
+// header if it has a usable one, and returns the id in the X-Request-ID header
+// of the response. Everything logged for the request, including by the stores
+// it calls, is logged with the id, by the logger in the request's context.
+// Each request is logged once it has been served, by its route rather than by
+// its path, so that searches and other details in URLs are not logged.
+func LogRequests(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestId := c.GetHeader(RequestIdHeader)
+		if !validRequestId(requestId) {
+			requestId = newRequestId()
+		}
+		c.Header(RequestIdHeader, requestId)
+		requestLogger := logger.With("requestId", requestId, "method", c.Request.Method, "route", c.FullPath())
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), requestLogger))
+
+		c.Next()
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		// later middleware may have added to the request's logger
+		logging.FromContext(c.Request.Context()).LogAttrs(
+			c.Request.Context(),
+			level,
+			"request served",
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+		)
+	}
+}
+
+// Middleware which recovers from handlers which panic, logging the panic with
+// the request and responding with 500 Internal Server Error.
+func Recover() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					// the handler gave up on the response on purpose
+					panic(recovered)
+				}
+				requestLogger(c).Error("handler panicked", "panic", recovered, "stack", string(debug.Stack()))
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// The logger of the request, which logs its id with everything.
+func requestLogger(c *gin.Context) *slog.Logger {
+	return logging.FromContext(c.Request.Context())
+}
+
+// Adds the attributes to everything logged for the rest of the request.
+func logWith(c *gin.Context, args ...any) {
+	c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), requestLogger(c).With(args...)))
+}
@@ -0,0 +1,112 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// Creates a router which logs its requests into the buffer, with a route which
+// logs with the request's logger and one which panics.
+func newLoggingRouter(logs *bytes.Buffer) *gin.Engine {
+	router := gin.New()
+	router.Use(controller.LogRequests(logging.New(logs, nil)), controller.Recover())
+	router.GET("/members/:id", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info("looking up member", "firstName", "Jane")
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("handler failed")
+	})
+	return router
+}
+
+// Decodes each line of the logs.
+func logLines(t *testing.T, logs *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var decoded map[string]any
+		if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		lines = append(lines, decoded)
+	}
+	return lines
+}
+
+func TestRequestIdIsTakenFromTheRequest(t *testing.T) {
+	logs := &bytes.Buffer{}
+	router := newLoggingRouter(logs)
+
+	request := httptest.NewRequest(http.MethodGet, "/members/12?search=jane", nil)
+	request.Header.Set(controller.RequestIdHeader, "proxy-given-id")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if got := recorder.Header().Get(controller.RequestIdHeader); got != "proxy-given-id" {
+		t.Fatalf("expected the request's id to be returned, got %q", got)
+	}
+	lines := logLines(t, logs)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), logs.String())
+	}
+	for _, line := range lines {
+		if line["requestId"] != "proxy-given-id" || line["route"] != "/members/:id" {
+			t.Errorf("expected the line to have the request's id and route, got %v", line)
+		}
+	}
+	if lines[0]["firstName"] != logging.Redacted {
+		t.Errorf("expected the member's name to be redacted, got %v", lines[0]["firstName"])
+	}
+	if lines[1]["msg"] != "request served" || lines[1]["status"] != float64(http.StatusNoContent) {
+		t.Errorf("expected the request to be logged as served, got %v", lines[1])
+	}
+	if strings.Contains(logs.String(), "search=jane") {
+		t.Errorf("expected the query string not to be logged: %s", logs.String())
+	}
+}
+
+func TestUnusableRequestIdsAreReplaced(t *testing.T) {
+	for _, requestId := range []string{"", "has spaces", strings.Repeat("a", 129)} {
+		logs := &bytes.Buffer{}
+		router := newLoggingRouter(logs)
+
+		request := httptest.NewRequest(http.MethodGet, "/members/12", nil)
+		request.Header.Set(controller.RequestIdHeader, requestId)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		got := recorder.Header().Get(controller.RequestIdHeader)
+		if got == "" || got == requestId {
+			t.Errorf("expected %q to be replaced, got %q", requestId, got)
+		}
+		for _, line := range logLines(t, logs) {
+			if line["requestId"] != got {
+				t.Errorf("expected the line to have the id %q, got %v", got, line)
+			}
+		}
+	}
+}
+
+func TestPanicsAreLoggedAndServedAsErrors(t *testing.T) {
+	logs := &bytes.Buffer{}
+	router := newLoggingRouter(logs)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", recorder.Code)
+	}
+	lines := logLines(t, logs)
+	if len(lines) != 2 || lines[0]["msg"] != "handler panicked" || lines[1]["level"] != "ERROR" {
+		t.Errorf("expected the panic and an error to be logged, got %s", logs.String())
+	}
+}
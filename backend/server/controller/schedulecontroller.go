@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
@@ -14,13 +13,48 @@ import (
 )
 
 type ScheduleHandler struct {
-	store *store.ScheduleStore
+	store store.ScheduleRepository
 }
 
-func SetupScheduleHandler(router *gin.RouterGroup, store *store.ScheduleStore) {
+func SetupScheduleHandler(router *gin.RouterGroup, store store.ScheduleRepository) {
 	handler := ScheduleHandler{store: store}
 
-	router.POST("", handler.postSchedule)
+	router.GET("", RequirePermission(domain.PermissionSchedulesRead), handler.getSchedules)
+	router.POST("", RequirePermission(domain.PermissionSchedulesWrite), handler.postSchedule)
+}
+
+// getSchedules godoc
+// @Summary      Get every service schedule.
+// @Description  Schedules are listed in order of their first service. Users limited to some campuses only see the
+// @Description  schedules of those campuses.
+// @Param        campusId query []int false "Only include schedules of these campuses" collectionFormat(multi)
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} domain.ScheduleResponseDTO
+// @Failure      400 Invalid campus id
+// @Failure      401 Not authenticated
+// @Failure      403 The user lacks the permission for this endpoint, or asked for a campus they are not limited to
+// @Router       /schedules [get]
+func (h *ScheduleHandler) getSchedules(c *gin.Context) {
+	campusIds, ok := parseCampusFilter(c)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.store.ForTenant(requestTenant(c).Id()).GetAll(c.Request.Context(), &domain.ScheduleFilter{CampusIds: campusIds})
+	if err != nil {
+		requestLogger(c).Error("error getting schedules from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	responseDTOs := make([]domain.ScheduleResponseDTO, 0)
+
+	for _, schedule := range schedules {
+		responseDTOs = append(responseDTOs, *schedule.ToResponseDTO())
+	}
+
+	c.JSON(http.StatusOK, responseDTOs)
 }
 
 func (h *ScheduleHandler) postSchedule(c *gin.Context) {
@@ -47,9 +81,18 @@ func (h *ScheduleHandler) postSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.store.Create(&createDto)
+	if !campusAllowed(c, createDto.CampusId) {
+		c.String(http.StatusForbidden, "schedules may only be added to your campuses\n")
+		return
+	}
+
+	schedule, err := h.store.ForTenant(requestTenant(c).Id()).Create(c.Request.Context(), requestActor(c), &createDto)
+	if errors.Is(err, store.ErrCampusNotFound) {
+		c.String(http.StatusUnprocessableEntity, "campus %d does not exist\n", *createDto.CampusId)
+		return
+	}
 	if err != nil {
-		log.Printf("error inserting into database: %v", err)
+		requestLogger(c).Error("error inserting into database", "error", err)
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,77 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/memory"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+	"github.com/gin-gonic/gin"
+)
+
+func newScheduleRouter(t *testing.T, schedules store.ScheduleRepository, roles []domain.Role, campusIds ...uint64) *gin.Engine {
+	router := newTestRouter(t, 1, roles, campusIds...)
+	controller.SetupScheduleHandler(router.Group("/schedules"), schedules)
+	return router
+}
+
+func weeklySchedule(beginDate time.Time, campusId *uint64) *domain.ScheduleCreateDTO {
+	return &domain.ScheduleCreateDTO{
+		BeginDate:      &beginDate,
+		RepeatInterval: &domain.ScheduleCreateDTORepeatInterval{Count: 1, Unit: domain.RepeatUnitWeek},
+		CampusId:       campusId,
+	}
+}
+
+func TestSchedulesAreListedInOrder(t *testing.T) {
+	router := newScheduleRouter(t, memory.CreateScheduleStore(), []domain.Role{domain.RoleRosterCoordinator})
+
+	evening := time.Date(2025, time.March, 2, 18, 0, 0, 0, time.UTC)
+	morning := time.Date(2025, time.March, 2, 9, 0, 0, 0, time.UTC)
+	for _, beginDate := range []time.Time{evening, morning} {
+		response := serveJSON(t, router, "POST", "/schedules", weeklySchedule(beginDate, nil), nil)
+		if response.Code != http.StatusOK {
+			t.Fatalf("expected POST /schedules to be 200 OK, but was %d: %s", response.Code, response.Body)
+		}
+	}
+
+	var schedules []domain.ScheduleResponseDTO
+	serveJSON(t, router, "GET", "/schedules", nil, &schedules)
+	if len(schedules) != 2 || !schedules[0].BeginDate.Equal(morning) || !schedules[1].BeginDate.Equal(evening) {
+		t.Errorf("expected the schedules in order of their first service, got %+v", schedules)
+	}
+
+	response := serveJSON(t, router, "POST", "/schedules", &domain.ScheduleCreateDTO{BeginDate: &morning}, nil)
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("expected a schedule which does not repeat to be 400 Bad Request, but was %d", response.Code)
+	}
+}
+
+func TestSchedulesAreLimitedByCampus(t *testing.T) {
+	schedules := memory.CreateScheduleStore()
+	admin := newScheduleRouter(t, schedules, []domain.Role{domain.RoleAdmin})
+	north := newScheduleRouter(t, schedules, []domain.Role{domain.RoleRosterCoordinator}, 10)
+
+	beginDate := time.Date(2025, time.March, 2, 9, 0, 0, 0, time.UTC)
+	serveJSON(t, admin, "POST", "/schedules", weeklySchedule(beginDate, util.NewPtr[uint64](10)), nil)
+	serveJSON(t, admin, "POST", "/schedules", weeklySchedule(beginDate, util.NewPtr[uint64](20)), nil)
+
+	var seen []domain.ScheduleResponseDTO
+	serveJSON(t, north, "GET", "/schedules", nil, &seen)
+	if len(seen) != 1 || seen[0].CampusId == nil || *seen[0].CampusId != 10 {
+		t.Errorf("expected a user limited to the north campus to only see its schedule, got %+v", seen)
+	}
+
+	response := serveJSON(t, north, "GET", "/schedules?campusId=20", nil, nil)
+	if response.Code != http.StatusForbidden {
+		t.Errorf("expected asking for another campus to be 403 Forbidden, but was %d", response.Code)
+	}
+	response = serveJSON(t, north, "POST", "/schedules", weeklySchedule(beginDate, util.NewPtr[uint64](20)), nil)
+	if response.Code != http.StatusForbidden {
+		t.Errorf("expected adding a schedule to another campus to be 403 Forbidden, but was %d", response.Code)
+	}
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+type SelfServiceControllerConfig struct {
+	// Whether changes members make to their own details wait for approval by
+	// a user who may write members, rather than being made straight away
+	RequireApproval bool
+}
+
+type SelfServiceController struct {
+	memberStore store.MemberRepository
+	changeStore store.MemberChangeRepository
+	config      SelfServiceControllerConfig
+}
+
+// Any user may use these endpoints, which only ever show or change the member
+// the user is linked to.
+func SetupSelfServiceController(
+	router *gin.RouterGroup,
+	memberStore store.MemberRepository,
+	changeStore store.MemberChangeRepository,
+	config *SelfServiceControllerConfig,
+) *SelfServiceController {
+	controller := &SelfServiceController{
+		memberStore: memberStore,
+		changeStore: changeStore,
+		config:      *config,
+	}
+
+	router.GET("", controller.getMe)
+	router.PATCH("", controller.patchMe)
+
+	return controller
+}
+
+// getMe godoc
+// @Summary      Get the logged in user and the member they are.
+// @Description  The member's notes are never shown. Any user may use this endpoint, but not an API key.
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} domain.MeResponseDTO
+// @Failure      401 Not authenticated
+// @Failure      403 The request is authenticated with an API key
+// @Router       /me [get]
+func (controller *SelfServiceController) getMe(c *gin.Context) {
+	user := requestUser(c)
+	if user == nil {
+		c.String(http.StatusForbidden, "only users have a profile\n")
+		return
+	}
+
+	response := domain.MeResponseDTO{User: *user.ToResponseDTO()}
+
+	if memberId := user.MemberId(); memberId != nil {
+		member, err := controller.memberStore.ForTenant(requestTenant(c).Id()).FindById(c.Request.Context(), *memberId)
+		if err != nil {
+			requestLogger(c).Error("error getting member", "memberId", *memberId, "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if member != nil {
+			response.Member = member.ToProfileDTO()
+		}
+
+		change, err := controller.changeStore.ForTenant(requestTenant(c).Id()).FindPending(c.Request.Context(), *memberId)
+		if err != nil {
+			requestLogger(c).Error("error getting pending change to member", "memberId", *memberId, "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if change != nil {
+			response.PendingChange = change.ToResponseDTO()
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// patchMe godoc
+// @Summary      Update the email address or phone number of the member the logged in user is
+// @Description  Fields which are left out are kept, and fields which are empty are cleared. If the church reviews
+// @Description  such changes, the change waits for approval and is combined with any change already waiting.
+// @Security     BearerAuth
+// @Param        request body domain.MeUpdateDTO true "The fields to change"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} domain.MeResponseDTO "The change was made"
+// @Success      202 {object} domain.MeResponseDTO "The change is waiting for approval"
+// @Failure      400 Invalid input data
+// @Failure      401 Not authenticated
+// @Failure      403 The request is authenticated with an API key
+// @Failure      404 The user is not linked to a member
+// @Router       /me [patch]
+func (controller *SelfServiceController) patchMe(c *gin.Context) {
+	user := requestUser(c)
+	if user == nil {
+		c.String(http.StatusForbidden, "only users have a profile\n")
+		return
+	}
+
+	memberId := user.MemberId()
+	if memberId == nil {
+		c.String(http.StatusNotFound, "your account is not linked to a member\n")
+		return
+	}
+
+	var updateDto domain.MeUpdateDTO
+
+	if err := c.BindJSON(&updateDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	if errs := updateDto.Validate(); len(errs) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Failed to validate update object with the following errors:\n")
+		for _, err := range errs {
+			builder.WriteString(err.Error())
+			builder.WriteString("\n")
+		}
+		c.String(http.StatusBadRequest, builder.String())
+		return
+	}
+
+	response := domain.MeResponseDTO{User: *user.ToResponseDTO()}
+
+	if controller.config.RequireApproval {
+		member, err := controller.memberStore.ForTenant(requestTenant(c).Id()).FindById(c.Request.Context(), *memberId)
+		if err != nil {
+			requestLogger(c).Error("error getting member", "memberId", *memberId, "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if member == nil {
+			c.String(http.StatusNotFound, "your account is not linked to a member\n")
+			return
+		}
+
+		change, err := controller.changeStore.ForTenant(requestTenant(c).Id()).Create(c.Request.Context(), user.Id(), *memberId, &updateDto)
+		if err != nil {
+			requestLogger(c).Error("error queueing change to member", "memberId", *memberId, "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		response.Member = member.ToProfileDTO()
+		response.PendingChange = change.ToResponseDTO()
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	member, err := controller.memberStore.ForTenant(requestTenant(c).Id()).UpdateContactDetails(c.Request.Context(), requestActor(c), *memberId, &updateDto)
+	if err != nil {
+		requestLogger(c).Error("error updating member", "memberId", *memberId, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if member == nil {
+		c.String(http.StatusNotFound, "your account is not linked to a member\n")
+		return
+	}
+
+	response.Member = member.ToProfileDTO()
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+// The header naming the slug of the tenant a request is for.
+const TenantHeader = "X-Tenant"
+
+// The key in the gin context under which the tenant middleware stores the
+// *domain.Tenant the request is for.
+const TenantKey = "tenant"
+
+type TenantConfig struct {
+	// The domain under which each church has a subdomain named after its slug,
+	// e.g. "churchmanager.app" for "grace.churchmanager.app", or empty if
+	// churches are not told apart by subdomain
+	BaseDomain string
+	// The slug of the tenant of requests which do not name one, or empty if
+	// every request must name its tenant
+	DefaultTenant string
+}
+
+// Middleware which finds the tenant a request is for from, in order, the
+// X-Tenant header, the subdomain of the host, the tenant given in the bearer
+// token and the default tenant. Requests without a tenant are rejected with
+// 400 Bad Request, and requests for an unknown tenant with 404 Not Found.
+func ResolveTenant(tenantStore store.TenantRepository, config *TenantConfig) gin.HandlerFunc {
+	baseDomain := strings.ToLower(strings.TrimPrefix(config.BaseDomain, "."))
+	defaultTenant := config.DefaultTenant
+
+	// tenants are never removed while serving, so are only looked up once
+	tenants := sync.Map{}
+
+	return func(c *gin.Context) {
+		slug := strings.ToLower(c.GetHeader(TenantHeader))
+		if slug == "" && baseDomain != "" {
+			slug = subdomainOf(c.Request.Host, baseDomain)
+		}
+		if slug == "" {
+			slug, _ = splitTenantToken(rawBearerToken(c))
+		}
+		if slug == "" {
+			slug = defaultTenant
+		}
+		if slug == "" {
+			c.String(http.StatusBadRequest, "the church could not be determined; use its subdomain or the %s header\n", TenantHeader)
+			c.Abort()
+			return
+		}
+
+		if tenant, ok := tenants.Load(slug); ok {
+			c.Set(TenantKey, tenant)
+			logWith(c, "tenant", slug)
+			c.Next()
+			return
+		}
+
+		tenant, err := tenantStore.FindBySlug(c.Request.Context(), slug)
+		if err != nil {
+			requestLogger(c).Error("error finding tenant", "tenant", slug, "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if tenant == nil {
+			c.String(http.StatusNotFound, "there is no church \"%s\"\n", slug)
+			c.Abort()
+			return
+		}
+
+		tenants.Store(slug, tenant)
+		c.Set(TenantKey, tenant)
+		logWith(c, "tenant", slug)
+		c.Next()
+	}
+}
+
+// The tenant found by ResolveTenant. Must only be used behind it.
+func requestTenant(c *gin.Context) *domain.Tenant {
+	return c.MustGet(TenantKey).(*domain.Tenant)
+}
+
+// Gives the single label in front of the base domain in the host, or the empty
+// string if the host is not a subdomain of the base domain.
+func subdomainOf(host string, baseDomain string) string {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+baseDomain)
+	if !found || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+// Tokens given to clients start with the slug of their tenant, so that they
+// identify the tenant by themselves.
+func tenantToken(tenant *domain.Tenant, token string) string {
+	return tenant.Slug() + "." + token
+}
+
+// Splits a token given by tenantToken into the slug of the tenant and the
+// token itself. Tokens without a tenant give an empty slug.
+func splitTenantToken(token string) (string, string) {
+	slug, rest, found := strings.Cut(token, ".")
+	if !found {
+		return "", token
+	}
+	return slug, rest
+}
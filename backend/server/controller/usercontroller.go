@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/gin-gonic/gin"
+)
+
+type UserController struct {
+	store store.UserRepository
+}
+
+func SetupUserController(router *gin.RouterGroup, store store.UserRepository) *UserController {
+	controller := &UserController{store: store}
+
+	manage := RequirePermission(domain.PermissionUsersManage)
+
+	router.GET("", manage, controller.getUsers)
+	router.POST("", manage, controller.postUser)
+	router.PUT(":id/roles", manage, controller.putUserRoles)
+	router.PUT(":id/member", manage, controller.putUserMember)
+	router.PUT(":id/campuses", manage, controller.putUserCampuses)
+
+	return controller
+}
+
+// getUsers godoc
+// @Summary      Get index of users.
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {array} domain.UserResponseDTO
+// @Failure      401 Not authenticated
+// @Failure      403 The user may not manage users
+// @Router       /users [get]
+func (controller *UserController) getUsers(c *gin.Context) {
+	users, err := controller.store.ForTenant(requestTenant(c).Id()).GetAll(c.Request.Context())
+	if err != nil {
+		requestLogger(c).Error("error getting users from database", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	responseDTOs := make([]domain.UserResponseDTO, 0)
+
+	for _, user := range users {
+		responseDTOs = append(responseDTOs, *user.ToResponseDTO())
+	}
+
+	c.JSON(http.StatusOK, responseDTOs)
+}
+
+// postUser godoc
+// @Summary      Add a user
+// @Description  Usernames are case insensitive and stored in lowercase. Passwords must be at least 12 characters long.
+// @Security     BearerAuth
+// @Param        request body domain.UserCreateDTO true "User to add"
+// @Accept       json
+// @Produce      json
+// @Success      201 {object} domain.UserResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      401 Not authenticated
+// @Failure      403 The user may not manage users
+// @Failure      409 The username is already taken
+// @Failure      422 The member to link the user to does not exist
+// @Router       /users [post]
+func (controller *UserController) postUser(c *gin.Context) {
+	var createDto domain.UserCreateDTO
+
+	if err := c.BindJSON(&createDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	if errs := createDto.Validate(); len(errs) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Failed to validate create object with the following errors:\n")
+		for _, err := range errs {
+			builder.WriteString(err.Error())
+			builder.WriteString("\n")
+		}
+		c.String(http.StatusBadRequest, builder.String())
+		return
+	}
+
+	user, err := CreateUser(c.Request.Context(), controller.store.ForTenant(requestTenant(c).Id()), requestActor(c), &createDto)
+	if errors.Is(err, store.ErrUsernameTaken) {
+		c.String(http.StatusConflict, "username \"%s\" is already taken\n", domain.NormaliseUsername(createDto.Username))
+		return
+	}
+	if errors.Is(err, store.ErrMemberNotFound) {
+		c.String(http.StatusUnprocessableEntity, "member %d does not exist\n", *createDto.MemberId)
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("failed to create user", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user.ToResponseDTO())
+}
+
+// Hashes the password of an already validated user and stores the user.
+func CreateUser(ctx context.Context, userStore store.UserRepository, actor string, createDto *domain.UserCreateDTO) (*domain.User, error) {
+	passwordHash, err := auth.HashPassword(createDto.Password)
+	if err != nil {
+		return nil, err
+	}
+	return userStore.Create(ctx, actor, domain.NormaliseUsername(createDto.Username), passwordHash, createDto.Roles, createDto.MemberId)
+}
+
+// putUserRoles godoc
+// @Summary      Replace the roles of a user
+// @Security     BearerAuth
+// @Param        id      path int                 true "The id of the user"
+// @Param        request body domain.UserRolesDTO true "The user's new roles"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} domain.UserResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      401 Not authenticated
+// @Failure      403 The user may not manage users
+// @Failure      404 No user with the given id could be found
+// @Router       /users/{id}/roles [put]
+func (controller *UserController) putUserRoles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	var rolesDto domain.UserRolesDTO
+
+	if err := c.BindJSON(&rolesDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	if errs := rolesDto.Validate(); len(errs) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Failed to validate roles with the following errors:\n")
+		for _, err := range errs {
+			builder.WriteString(err.Error())
+			builder.WriteString("\n")
+		}
+		c.String(http.StatusBadRequest, builder.String())
+		return
+	}
+
+	user, err := controller.store.ForTenant(requestTenant(c).Id()).SetRoles(c.Request.Context(), requestActor(c), id, rolesDto.Roles)
+	if err != nil {
+		requestLogger(c).Error("failed to set roles", "userId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if user == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponseDTO())
+}
+
+// putUserMember godoc
+// @Summary      Link a user to the member they are
+// @Description  The user may then view the member and update their email address and phone number through /me. A null
+// @Description  memberId unlinks the user.
+// @Security     BearerAuth
+// @Param        id      path int                  true "The id of the user"
+// @Param        request body domain.UserMemberDTO true "The member to link the user to"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} domain.UserResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      401 Not authenticated
+// @Failure      403 The user may not manage users
+// @Failure      404 No user with the given id could be found
+// @Failure      422 The member does not exist
+// @Router       /users/{id}/member [put]
+func (controller *UserController) putUserMember(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	var memberDto domain.UserMemberDTO
+
+	if err := c.BindJSON(&memberDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	user, err := controller.store.ForTenant(requestTenant(c).Id()).SetMember(c.Request.Context(), requestActor(c), id, memberDto.MemberId)
+	if errors.Is(err, store.ErrMemberNotFound) {
+		c.String(http.StatusUnprocessableEntity, "member %d does not exist\n", *memberDto.MemberId)
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("failed to link member", "userId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if user == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponseDTO())
+}
+
+// putUserCampuses godoc
+// @Summary      Limit a user to some campuses
+// @Description  The user then only sees and changes the members and schedules of those campuses, and cannot use
+// @Description  endpoints which work across every member. An empty list allows the user every campus.
+// @Security     BearerAuth
+// @Param        id      path int                    true "The id of the user"
+// @Param        request body domain.UserCampusesDTO true "The campuses to limit the user to"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} domain.UserResponseDTO
+// @Failure      400 Invalid input data
+// @Failure      401 Not authenticated
+// @Failure      403 The user may not manage users
+// @Failure      404 No user with the given id could be found
+// @Failure      422 One of the campuses does not exist
+// @Router       /users/{id}/campuses [put]
+func (controller *UserController) putUserCampuses(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid parameter id \"%s\"\n", c.Param("id"))
+		return
+	}
+
+	var campusesDto domain.UserCampusesDTO
+
+	if err := c.BindJSON(&campusesDto); err != nil {
+		c.String(http.StatusBadRequest, err.Error()+"\n")
+		return
+	}
+
+	user, err := controller.store.ForTenant(requestTenant(c).Id()).SetCampuses(c.Request.Context(), requestActor(c), id, campusesDto.CampusIds)
+	if errors.Is(err, store.ErrCampusNotFound) {
+		c.String(http.StatusUnprocessableEntity, "campuses %v do not all exist\n", campusesDto.CampusIds)
+		return
+	}
+	if err != nil {
+		requestLogger(c).Error("failed to set campuses", "userId", id, "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if user == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponseDTO())
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+// A key with which integrations authenticate without a user, limited to its
+// scopes.
+type APIKey struct {
+	id         uint64
+	name       string
+	prefix     string
+	scopes     []Permission
+	createdBy  *uint64
+	createdAt  time.Time
+	expiresAt  *time.Time
+	lastUsedAt *time.Time
+	revokedAt  *time.Time
+}
+
+func (key *APIKey) ToResponseDTO() *APIKeyResponseDTO {
+	return &APIKeyResponseDTO{
+		Id:         key.id,
+		Name:       key.name,
+		Prefix:     key.prefix,
+		Scopes:     key.Scopes(),
+		CreatedBy:  key.createdBy,
+		CreatedAt:  key.createdAt,
+		ExpiresAt:  key.expiresAt,
+		LastUsedAt: key.lastUsedAt,
+		RevokedAt:  key.revokedAt,
+	}
+}
+
+func (key *APIKey) Id() uint64 {
+	return key.id
+}
+
+func (key *APIKey) Name() string {
+	return key.name
+}
+
+// The start of the key, shown so that keys can be told apart without
+// revealing them.
+func (key *APIKey) Prefix() string {
+	return key.prefix
+}
+
+func (key *APIKey) Scopes() []Permission {
+	return slices.Clone(key.scopes)
+}
+
+// The key is limited to the permissions in its scopes.
+func (key *APIKey) HasPermission(permission Permission) bool {
+	return slices.Contains(key.scopes, permission)
+}
+
+// Gives the key limited to the scopes its creator still has permission for, so
+// that a key does not outlive the roles of the user who created it. Keys of
+// creators since limited to some campuses have no scopes left, as keys are not
+// limited to campuses.
+func (key *APIKey) LimitedTo(creator *User) *APIKey {
+	limited := *key
+	limited.scopes = make([]Permission, 0, len(key.scopes))
+	if len(creator.campusIds) > 0 {
+		return &limited
+	}
+	for _, scope := range key.scopes {
+		if creator.HasPermission(scope) {
+			limited.scopes = append(limited.scopes, scope)
+		}
+	}
+	return &limited
+}
+
+// The time after which the key may no longer be used, or nil if it does not
+// expire.
+func (key *APIKey) ExpiresAt() *time.Time {
+	if key.expiresAt == nil {
+		return nil
+	}
+
+	return util.NewPtr(*key.expiresAt)
+}
+
+// The last time the key was used, to within a minute, or nil if it has not
+// been used.
+func (key *APIKey) LastUsedAt() *time.Time {
+	if key.lastUsedAt == nil {
+		return nil
+	}
+
+	return util.NewPtr(*key.lastUsedAt)
+}
+
+func (key *APIKey) RevokedAt() *time.Time {
+	if key.revokedAt == nil {
+		return nil
+	}
+
+	return util.NewPtr(*key.revokedAt)
+}
+
+type APIKeyRow struct {
+	Id         uint64
+	Name       string
+	Prefix     string
+	Scopes     []string
+	CreatedBy  *uint64
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+func (row *APIKeyRow) ToAPIKey() (*APIKey, error) {
+	scopes := make([]Permission, len(row.Scopes))
+	for i, name := range row.Scopes {
+		if scopes[i] = Permission(name); !scopes[i].Valid() {
+			return nil, fmt.Errorf("unknown scope %q", name)
+		}
+	}
+
+	key := &APIKey{
+		id:         row.Id,
+		name:       row.Name,
+		prefix:     row.Prefix,
+		scopes:     scopes,
+		createdBy:  row.CreatedBy,
+		createdAt:  row.CreatedAt,
+		expiresAt:  row.ExpiresAt,
+		lastUsedAt: row.LastUsedAt,
+		revokedAt:  row.RevokedAt,
+	}
+
+	return key, nil
+}
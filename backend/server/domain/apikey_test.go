@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+func TestAPIKeyCreateValidation(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		creator domain.Role
+		dto     domain.APIKeyCreateDTO
+		valid   bool
+	}{
+		{"scope the creator has", domain.RoleWelcomeTeam, domain.APIKeyCreateDTO{Name: "Website", Scopes: []domain.Permission{domain.PermissionSchedulesRead}, ExpiresAt: &future}, true},
+		{"scope the creator lacks", domain.RoleWelcomeTeam, domain.APIKeyCreateDTO{Name: "Website", Scopes: []domain.Permission{domain.PermissionMemberNotesRead}}, false},
+		{"unknown scope", domain.RoleAdmin, domain.APIKeyCreateDTO{Name: "Website", Scopes: []domain.Permission{"members:everything"}}, false},
+		{"no scopes", domain.RoleAdmin, domain.APIKeyCreateDTO{Name: "Website"}, false},
+		{"no name", domain.RoleAdmin, domain.APIKeyCreateDTO{Scopes: []domain.Permission{domain.PermissionMembersRead}}, false},
+		{"expired", domain.RoleAdmin, domain.APIKeyCreateDTO{Name: "Website", Scopes: []domain.Permission{domain.PermissionMembersRead}, ExpiresAt: &past}, false},
+	}
+
+	for _, test := range tests {
+		errs := test.dto.Validate(test.creator, now)
+		if test.valid && len(errs) > 0 {
+			t.Errorf("%s: expected key to be valid, got %v", test.name, errs)
+		} else if !test.valid && len(errs) == 0 {
+			t.Errorf("%s: expected key to be invalid", test.name)
+		}
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	future := time.Now().UTC().Add(time.Hour)
+	row := domain.APIKeyRow{Id: 1, Name: "Website", Prefix: "cmk_abcdefgh", Scopes: []string{"schedules:read"}, ExpiresAt: &future}
+	key, err := row.ToAPIKey()
+	if err != nil {
+		t.Fatalf("error converting row to API key: %v", err)
+	}
+
+	if !key.HasPermission(domain.PermissionSchedulesRead) {
+		t.Error("expected the key to have its scope")
+	}
+	if key.HasPermission(domain.PermissionMembersRead) {
+		t.Error("expected the key not to have permissions outside of its scopes")
+	}
+}
+
+func TestAPIKeyLimitedToCreator(t *testing.T) {
+	row := domain.APIKeyRow{Id: 1, Name: "Directory sync", Prefix: "cmk_abcdefgh", Scopes: []string{"members:read", "audit:read"}}
+	key, err := row.ToAPIKey()
+	if err != nil {
+		t.Fatalf("error converting row to API key: %v", err)
+	}
+
+	demoted, err := (&domain.UserRow{Id: 1, Username: "demoted", Roles: []string{"welcome_team"}}).ToUser()
+	if err != nil {
+		t.Fatalf("error converting row to user: %v", err)
+	}
+	limited := key.LimitedTo(demoted)
+	if !limited.HasPermission(domain.PermissionMembersRead) || limited.HasPermission(domain.PermissionAuditRead) {
+		t.Errorf("expected the key to keep only the scopes its creator still has, got %v", limited.Scopes())
+	}
+
+	campus, err := (&domain.UserRow{Id: 2, Username: "campus", Roles: []string{"admin"}, CampusIds: []uint64{3}}).ToUser()
+	if err != nil {
+		t.Fatalf("error converting row to user: %v", err)
+	}
+	if scopes := key.LimitedTo(campus).Scopes(); len(scopes) != 0 {
+		t.Errorf("expected a key of a creator limited to a campus to have no scopes, got %v", scopes)
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type APIKeyCreateDTO struct {
+	Name   string       `json:"name" example:"Church website"`
+	Scopes []Permission `json:"scopes" example:"schedules:read"`
+	// When the key stops working, or null for a key which does not expire
+	ExpiresAt *time.Time `json:"expiresAt"`
+} // @name APIKeyCreate
+
+const maxAPIKeyNameLength = 128
+
+// Checks the key, which may only be given scopes its creator has.
+func (dto *APIKeyCreateDTO) Validate(creator Principal, now time.Time) []error {
+	errs := make([]error, 0)
+
+	if dto.Name == "" || len(dto.Name) > maxAPIKeyNameLength {
+		errs = append(errs, fmt.Errorf("field name must be from 1 to %d bytes long, got %d", maxAPIKeyNameLength, len(dto.Name)))
+	}
+
+	if len(dto.Scopes) == 0 {
+		errs = append(errs, fmt.Errorf("field scopes must contain at least one scope"))
+	}
+	for _, scope := range dto.Scopes {
+		if !scope.Valid() {
+			errs = append(errs, fmt.Errorf("unknown scope %q, expected one of %v", scope, Permissions))
+		} else if !creator.HasPermission(scope) {
+			errs = append(errs, fmt.Errorf("cannot give scope %q which you do not have", scope))
+		}
+	}
+
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(now) {
+		errs = append(errs, fmt.Errorf("field expiresAt must be in the future"))
+	}
+
+	return errs
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type APIKeyResponseDTO struct {
+	Id     uint64       `json:"id" example:"7"`
+	Name   string       `json:"name" example:"Church website"`
+	Prefix string       `json:"prefix" example:"cmk_Xq3lW7bT"`
+	Scopes []Permission `json:"scopes" example:"schedules:read"`
+	// The id of the user who created the key, or null if they have been removed
+	CreatedBy  *uint64    `json:"createdBy" example:"3"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+} // @name APIKeyResponse
+
+type APIKeyCreatedResponseDTO struct {
+	APIKeyResponseDTO
+	// The key itself, which is only ever shown once. Starts with the slug of
+	// the church, so that it also identifies the church.
+	Key string `json:"key" example:"grace.cmk_Xq3lW7bT0nYk2aPz8r1QmF4vH6sEoUdG9iLwXyBcJ5"`
+} // @name APIKeyCreatedResponse
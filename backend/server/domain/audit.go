@@ -0,0 +1,207 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+type AuditEntityType string
+
+const (
+	AuditEntityMember   AuditEntityType = "member"
+	AuditEntitySchedule AuditEntityType = "schedule"
+	AuditEntityUser     AuditEntityType = "user"
+	AuditEntityAPIKey   AuditEntityType = "apiKey"
+	AuditEntityCampus   AuditEntityType = "campus"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "Create"
+	AuditActionUpdate  AuditAction = "Update"
+	AuditActionDelete  AuditAction = "Delete"
+	AuditActionRestore AuditAction = "Restore"
+	AuditActionPurge   AuditAction = "Purge"
+	AuditActionMerge   AuditAction = "Merge"
+)
+
+// The actor recorded against changes made by the server itself, rather than
+// on behalf of a request.
+const AuditActorSystem = "system"
+
+// A JSON object representation of an entity at a point in time, keyed by the
+// entity's JSON field names.
+type AuditSnapshot map[string]any
+
+// The value of a single field before and after a change.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Creates a snapshot from the JSON representation of the given value, which
+// should usually be an entity's response DTO.
+func NewAuditSnapshot(value any) (AuditSnapshot, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding audit snapshot: %v", err)
+	}
+	snapshot := AuditSnapshot{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("decoding audit snapshot: %v", err)
+	}
+	return snapshot, nil
+}
+
+// Gives every field which differs between the two snapshots. Either snapshot
+// may be nil, as it is for creations and permanent deletions.
+func DiffAuditSnapshots(before AuditSnapshot, after AuditSnapshot) map[string]AuditChange {
+	diff := make(map[string]AuditChange)
+
+	for field, beforeValue := range before {
+		afterValue := after[field]
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			diff[field] = AuditChange{Before: beforeValue, After: afterValue}
+		}
+	}
+
+	for field, afterValue := range after {
+		if _, ok := before[field]; ok {
+			continue
+		}
+		if afterValue != nil {
+			diff[field] = AuditChange{Before: nil, After: afterValue}
+		}
+	}
+
+	return diff
+}
+
+type AuditEntry struct {
+	id         uint64
+	entityType AuditEntityType
+	entityId   uint64
+	action     AuditAction
+	actor      string
+	occurredAt time.Time
+	before     AuditSnapshot
+	after      AuditSnapshot
+	diff       map[string]AuditChange
+}
+
+func (entry *AuditEntry) ToResponseDTO() *AuditEntryResponseDTO {
+	return &AuditEntryResponseDTO{
+		Id:         entry.id,
+		EntityType: entry.entityType,
+		EntityId:   entry.entityId,
+		Action:     entry.action,
+		Actor:      entry.actor,
+		OccurredAt: entry.occurredAt,
+		Before:     entry.before,
+		After:      entry.after,
+		Diff:       entry.diff,
+	}
+}
+
+// Gives a copy of the entry with the fields removed from its snapshots and
+// diff, to show to users who may not read those fields.
+func (entry *AuditEntry) WithoutFields(fields ...string) *AuditEntry {
+	redacted := *entry
+
+	withoutFields := func(snapshot AuditSnapshot) AuditSnapshot {
+		if snapshot == nil {
+			return nil
+		}
+		copied := make(AuditSnapshot, len(snapshot))
+		for field, value := range snapshot {
+			copied[field] = value
+		}
+		for _, field := range fields {
+			delete(copied, field)
+		}
+		return copied
+	}
+	redacted.before = withoutFields(entry.before)
+	redacted.after = withoutFields(entry.after)
+
+	redacted.diff = make(map[string]AuditChange, len(entry.diff))
+	for field, change := range entry.diff {
+		redacted.diff[field] = change
+	}
+	for _, field := range fields {
+		delete(redacted.diff, field)
+	}
+
+	return &redacted
+}
+
+func (entry *AuditEntry) Id() uint64 {
+	return entry.id
+}
+
+func (entry *AuditEntry) EntityType() AuditEntityType {
+	return entry.entityType
+}
+
+func (entry *AuditEntry) EntityId() uint64 {
+	return entry.entityId
+}
+
+func (entry *AuditEntry) Action() AuditAction {
+	return entry.action
+}
+
+func (entry *AuditEntry) Actor() string {
+	return entry.actor
+}
+
+func (entry *AuditEntry) OccurredAt() time.Time {
+	return entry.occurredAt
+}
+
+type AuditEntryRow struct {
+	Id         uint64
+	EntityType AuditEntityType
+	EntityId   uint64
+	Action     AuditAction
+	Actor      string
+	OccurredAt time.Time
+	Before     AuditSnapshot
+	After      AuditSnapshot
+	Diff       map[string]AuditChange
+}
+
+func (row *AuditEntryRow) ToAuditEntry() (*AuditEntry, error) {
+	if row.Before == nil && row.After == nil {
+		return nil, fmt.Errorf("at least one of the before and after snapshots must be defined")
+	}
+
+	entry := &AuditEntry{
+		id:         row.Id,
+		entityType: row.EntityType,
+		entityId:   row.EntityId,
+		action:     row.Action,
+		actor:      row.Actor,
+		occurredAt: row.OccurredAt,
+		before:     row.Before,
+		after:      row.After,
+		diff:       row.Diff,
+	}
+
+	return entry, nil
+}
+
+// Criteria for searching the audit log. Nil fields do not filter the results.
+type AuditFilter struct {
+	EntityType *AuditEntityType
+	EntityId   *uint64
+	Action     *AuditAction
+	Actor      *string
+	// Inclusive lower bound on the time of the change
+	Since *time.Time
+	// Exclusive upper bound on the time of the change
+	Until *time.Time
+}
@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestDiffAuditSnapshots(t *testing.T) {
+	before, err := domain.NewAuditSnapshot(domain.MemberResponseDTO{
+		Id:           1,
+		FirstName:    util.NewPtr("Philip"),
+		EmailAddress: util.NewPtr("philip@wittenberg.de"),
+		Notes:        "Greek lecturer",
+	})
+	if err != nil {
+		t.Fatalf("error creating snapshot: %v", err)
+	}
+	after, err := domain.NewAuditSnapshot(domain.MemberResponseDTO{
+		Id:           1,
+		FirstName:    util.NewPtr("Philip"),
+		EmailAddress: util.NewPtr("melanchthon@wittenberg.de"),
+		Notes:        "Greek lecturer",
+	})
+	if err != nil {
+		t.Fatalf("error creating snapshot: %v", err)
+	}
+
+	t.Run("update gives only the changed fields", func(t *testing.T) {
+		diff := domain.DiffAuditSnapshots(before, after)
+		if len(diff) != 1 {
+			t.Fatalf("expected exactly one changed field, got %v", diff)
+		}
+		change := diff["emailAddress"]
+		if change.Before != "philip@wittenberg.de" || change.After != "melanchthon@wittenberg.de" {
+			t.Errorf("expected emailAddress to change between the two addresses, got %v", change)
+		}
+	})
+
+	t.Run("creation gives every non-null field", func(t *testing.T) {
+		diff := domain.DiffAuditSnapshots(nil, after)
+		for _, field := range []string{"id", "firstName", "emailAddress", "notes"} {
+			if change, ok := diff[field]; !ok || change.Before != nil {
+				t.Errorf("expected field %s to change from nil, got %v", field, diff[field])
+			}
+		}
+		if _, ok := diff["lastName"]; ok {
+			t.Error("expected null field lastName to be left out of the diff")
+		}
+	})
+
+	t.Run("deletion gives every non-null field", func(t *testing.T) {
+		diff := domain.DiffAuditSnapshots(before, nil)
+		for _, field := range []string{"id", "firstName", "emailAddress", "notes"} {
+			if change, ok := diff[field]; !ok || change.After != nil {
+				t.Errorf("expected field %s to change to nil, got %v", field, diff[field])
+			}
+		}
+		if _, ok := diff["lastName"]; ok {
+			t.Error("expected null field lastName to be left out of the diff")
+		}
+	})
+}
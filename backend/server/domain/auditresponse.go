@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type AuditEntryResponseDTO struct {
+	Id         uint64                 `json:"id" example:"1024"`
+	EntityType AuditEntityType        `json:"entityType" example:"member"`
+	EntityId   uint64                 `json:"entityId" example:"81996"`
+	Action     AuditAction            `json:"action" example:"Update"`
+	Actor      string                 `json:"actor" example:"office.admin"`
+	OccurredAt time.Time              `json:"occurredAt"`
+	Before     AuditSnapshot          `json:"before"`
+	After      AuditSnapshot          `json:"after"`
+	Diff       map[string]AuditChange `json:"diff"`
+} // @name AuditEntryResponse
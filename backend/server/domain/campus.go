@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// A place within a church where it meets, which members and schedules may
+// belong to.
+type Campus struct {
+	id        uint64
+	name      string
+	createdAt time.Time
+}
+
+func (campus *Campus) ToResponseDTO() *CampusResponseDTO {
+	return &CampusResponseDTO{
+		Id:        campus.id,
+		Name:      campus.name,
+		CreatedAt: campus.createdAt,
+	}
+}
+
+func (campus *Campus) Id() uint64 {
+	return campus.id
+}
+
+func (campus *Campus) Name() string {
+	return campus.name
+}
+
+func (campus *Campus) CreatedAt() time.Time {
+	return campus.createdAt
+}
+
+type CampusRow struct {
+	Id        uint64
+	Name      string
+	CreatedAt time.Time
+}
+
+func (row *CampusRow) ToCampus() (*Campus, error) {
+	campus := &Campus{
+		id:        row.Id,
+		name:      row.Name,
+		createdAt: row.CreatedAt,
+	}
+
+	return campus, nil
+}
+
+// Creates or renames a campus.
+type CampusUpdateDTO struct {
+	Name string `json:"name" example:"Hippo Regius"`
+} // @name CampusUpdate
+
+type CampusResponseDTO struct {
+	Id        uint64    `json:"id" example:"2"`
+	Name      string    `json:"name" example:"Hippo Regius"`
+	CreatedAt time.Time `json:"createdAt"`
+} // @name CampusResponse
+
+const maxCampusNameLength = 128
+
+func (dto *CampusUpdateDTO) Validate() []error {
+	errs := make([]error, 0)
+
+	if dto.Name == "" || len(dto.Name) > maxCampusNameLength {
+		errs = append(errs, fmt.Errorf("field name must be from 1 to %d bytes long, got %d", maxCampusNameLength, len(dto.Name)))
+	}
+
+	return errs
+}
+
+// The campuses a user is limited to. An empty list allows every campus.
+type UserCampusesDTO struct {
+	CampusIds []uint64 `json:"campusIds" example:"2,3"`
+} // @name UserCampuses
+
+// Whether something at the campus, or at no campus when the id is nil, may be
+// seen by someone limited to the campuses. An empty list allows every campus,
+// while members and schedules at no campus are only seen by those allowed
+// every campus.
+func CampusAllowed(campusIds []uint64, campusId *uint64) bool {
+	if len(campusIds) == 0 {
+		return true
+	}
+	return campusId != nil && slices.Contains(campusIds, *campusId)
+}
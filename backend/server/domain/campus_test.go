@@ -0,0 +1,40 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestCampusAllowed(t *testing.T) {
+	if !domain.CampusAllowed(nil, util.NewPtr(uint64(3))) || !domain.CampusAllowed(nil, nil) {
+		t.Error("expected no campuses to allow every campus, and no campus")
+	}
+
+	limited := []uint64{2, 3}
+	if !domain.CampusAllowed(limited, util.NewPtr(uint64(3))) {
+		t.Error("expected one of the campuses to be allowed")
+	}
+	if domain.CampusAllowed(limited, util.NewPtr(uint64(4))) {
+		t.Error("expected another campus not to be allowed")
+	}
+	if domain.CampusAllowed(limited, nil) {
+		t.Error("expected no campus not to be allowed to someone limited to some campuses")
+	}
+}
+
+func TestCampusValidation(t *testing.T) {
+	valid := domain.CampusUpdateDTO{Name: "Hippo Regius"}
+	if errs := valid.Validate(); len(errs) > 0 {
+		t.Errorf("expected campus to be valid, got %v", errs)
+	}
+
+	for _, name := range []string{"", strings.Repeat("a", 129)} {
+		invalid := domain.CampusUpdateDTO{Name: name}
+		if errs := invalid.Validate(); len(errs) == 0 {
+			t.Errorf("expected campus named %q to be invalid", name)
+		}
+	}
+}
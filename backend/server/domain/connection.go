@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// How the connections to the database are used. The waits are counted from
+// when the connections were first opened.
+type ConnectionStats struct {
+	// Connections in use by a query or transaction
+	Acquired int64
+	// Connections open and not in use
+	Idle int64
+	// The most connections which may be open at once, or 0 if there is no
+	// limit
+	Max int64
+	// How many times a query waited for a connection because none were idle
+	Waits int64
+	// How long queries have waited for connections in all
+	WaitDuration time.Duration
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type LoginDTO struct {
+	Username string `json:"username" example:"ambrose"`
+	Password string `json:"password" example:"correct horse battery staple"`
+} // @name Login
+
+type LoginResponseDTO struct {
+	// Sent as "Authorization: Bearer <token>" to authenticate later requests.
+	// Starts with the slug of the church, so that it also identifies the church.
+	Token     string          `json:"token" example:"grace.q8Zb1tH0mS6F2wTn9d0sXgKkM3yR5cVhJ7uLpE4aB1o"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+	User      UserResponseDTO `json:"user"`
+} // @name LoginResponse
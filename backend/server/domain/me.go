@@ -0,0 +1,87 @@
+package domain
+
+import "errors"
+
+// What a user may see of the member they are, which leaves out the notes kept
+// about them.
+type MemberProfileDTO struct {
+	Id           uint64  `json:"id" example:"81996"`
+	FirstName    *string `json:"firstName" example:"Augustinus"`
+	LastName     *string `json:"lastName" example:"Hipponensis"`
+	EmailAddress *string `json:"emailAddress" example:"aug.of.hippo@live.roma"`
+	PhoneNumber  *string `json:"phoneNumber" example:"0434579344"`
+	Address      *string `json:"address" example:"1 Basilica Way\nHippo Regius"`
+} // @name MemberProfile
+
+type MeResponseDTO struct {
+	User UserResponseDTO `json:"user"`
+	// The member the user is, or null if the user is not linked to a member
+	Member *MemberProfileDTO `json:"member"`
+	// A change to the member which is waiting for approval
+	PendingChange *MemberChangeResponseDTO `json:"pendingChange"`
+} // @name MeResponse
+
+// A change by a member to their own details. Fields which are left out are
+// kept, and fields which are empty are cleared.
+type MeUpdateDTO struct {
+	EmailAddress *string `json:"emailAddress" example:"aug.of.hippo@live.roma"`
+	PhoneNumber  *string `json:"phoneNumber" example:"0434579344"`
+} // @name MeUpdate
+
+func (member *Member) ToProfileDTO() *MemberProfileDTO {
+	return &MemberProfileDTO{
+		Id:           member.id,
+		FirstName:    member.firstName,
+		LastName:     member.lastName,
+		EmailAddress: member.emailAddress,
+		PhoneNumber:  member.phoneNumber,
+		Address:      member.address,
+	}
+}
+
+func (dto *MeUpdateDTO) Validate() []error {
+	errs := make([]error, 0)
+
+	if dto.EmailAddress == nil && dto.PhoneNumber == nil {
+		errs = append(errs, errors.New("at least one of emailAddress and phoneNumber must be given"))
+	}
+
+	// the fields are checked as they would be set on the member
+	updateDto := MemberUpdateDTO{
+		EmailAddress: nilIfEmpty(dto.EmailAddress),
+		PhoneNumber:  nilIfEmpty(dto.PhoneNumber),
+	}
+
+	return append(errs, updateDto.Validate()...)
+}
+
+// Gives the member with the change made to it, keeping every other field.
+func (dto *MeUpdateDTO) ApplyTo(member *Member) *MemberUpdateDTO {
+	return applySelfServiceChange(member, dto.EmailAddress, dto.PhoneNumber)
+}
+
+func applySelfServiceChange(member *Member, emailAddress *string, phoneNumber *string) *MemberUpdateDTO {
+	updateDto := &MemberUpdateDTO{
+		FirstName:    member.FirstName(),
+		LastName:     member.LastName(),
+		EmailAddress: member.EmailAddress(),
+		PhoneNumber:  member.PhoneNumber(),
+		Address:      member.Address(),
+		CampusId:     member.CampusId(),
+		Notes:        member.Notes(),
+	}
+	if emailAddress != nil {
+		updateDto.EmailAddress = nilIfEmpty(emailAddress)
+	}
+	if phoneNumber != nil {
+		updateDto.PhoneNumber = nilIfEmpty(phoneNumber)
+	}
+	return updateDto
+}
+
+func nilIfEmpty(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
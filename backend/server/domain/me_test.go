@@ -0,0 +1,56 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestMeUpdateValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		dto   domain.MeUpdateDTO
+		valid bool
+	}{
+		{"email address", domain.MeUpdateDTO{EmailAddress: util.NewPtr("monica@tagaste.org")}, true},
+		{"cleared phone number", domain.MeUpdateDTO{PhoneNumber: util.NewPtr("")}, true},
+		{"nothing to change", domain.MeUpdateDTO{}, false},
+		{"bad email address", domain.MeUpdateDTO{EmailAddress: util.NewPtr("Monica <monica@tagaste.org>")}, false},
+	}
+
+	for _, test := range tests {
+		errs := test.dto.Validate()
+		if test.valid && len(errs) > 0 {
+			t.Errorf("%s: expected change to be valid, got %v", test.name, errs)
+		} else if !test.valid && len(errs) == 0 {
+			t.Errorf("%s: expected change to be invalid", test.name)
+		}
+	}
+}
+
+func TestMeUpdateKeepsOtherFields(t *testing.T) {
+	row := domain.MemberRow{
+		Id:           1,
+		FirstName:    util.NewPtr("Monica"),
+		EmailAddress: util.NewPtr("monica@tagaste.org"),
+		PhoneNumber:  util.NewPtr("0400000000"),
+		Notes:        "Prays for her son",
+	}
+	member, err := row.ToMember()
+	if err != nil {
+		t.Fatalf("error converting row to member: %v", err)
+	}
+
+	updateDto := (&domain.MeUpdateDTO{PhoneNumber: util.NewPtr("")}).ApplyTo(member)
+
+	if updateDto.PhoneNumber != nil {
+		t.Errorf("expected an empty phone number to clear it, got %q", *updateDto.PhoneNumber)
+	}
+	if updateDto.EmailAddress == nil || *updateDto.EmailAddress != "monica@tagaste.org" {
+		t.Errorf("expected the email address to be kept, got %v", updateDto.EmailAddress)
+	}
+	if updateDto.FirstName == nil || *updateDto.FirstName != "Monica" || updateDto.Notes != "Prays for her son" {
+		t.Errorf("expected every other field to be kept, got %v", updateDto)
+	}
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "github.com/carsonalh/churchmanagerbackend/server/util"
+import (
+	"log/slog"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
 
 type Member struct {
 	id           uint64
@@ -8,17 +13,26 @@ type Member struct {
 	lastName     *string
 	emailAddress *string
 	phoneNumber  *string
+	address      *string
+	campusId     *uint64
 	notes        string
+	// Whether the notes have been removed for a user without pastoral access
+	notesRedacted bool
+	deletedAt     *time.Time
 }
 
 func (member *Member) ToResponseDTO() *MemberResponseDTO {
 	return &MemberResponseDTO{
-		Id:           member.id,
-		FirstName:    member.firstName,
-		LastName:     member.lastName,
-		EmailAddress: member.emailAddress,
-		PhoneNumber:  member.phoneNumber,
-		Notes:        member.notes,
+		Id:            member.id,
+		FirstName:     member.firstName,
+		LastName:      member.lastName,
+		EmailAddress:  member.emailAddress,
+		PhoneNumber:   member.phoneNumber,
+		Address:       member.address,
+		CampusId:      member.CampusId(),
+		Notes:         member.notes,
+		NotesRedacted: member.notesRedacted,
+		DeletedAt:     member.deletedAt,
 	}
 }
 
@@ -58,17 +72,67 @@ func (member *Member) PhoneNumber() *string {
 	return util.NewPtr(*member.phoneNumber)
 }
 
+// The member's postal address, with lines separated by newlines.
+func (member *Member) Address() *string {
+	if member.address == nil {
+		return nil
+	}
+
+	return util.NewPtr(*member.address)
+}
+
+// The id of the campus the member belongs to, or nil if they belong to none.
+func (member *Member) CampusId() *uint64 {
+	if member.campusId == nil {
+		return nil
+	}
+
+	return util.NewPtr(*member.campusId)
+}
+
 func (member *Member) Notes() string {
 	return member.notes
 }
 
+// Gives a copy of the member without its notes, to show to users who may not
+// read them.
+func (member *Member) WithoutNotes() *Member {
+	redacted := *member
+	redacted.notes = ""
+	redacted.notesRedacted = true
+	return &redacted
+}
+
+// The time the member was moved to the trash, or nil if the member has not
+// been deleted.
+func (member *Member) DeletedAt() *time.Time {
+	if member.deletedAt == nil {
+		return nil
+	}
+
+	return util.NewPtr(*member.deletedAt)
+}
+
+// Logs the member as its id and campus alone, so that its personal details
+// are never written to the logs.
+func (member *Member) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.Uint64("id", member.id)}
+	if member.campusId != nil {
+		attrs = append(attrs, slog.Uint64("campusId", *member.campusId))
+	}
+	return slog.GroupValue(attrs...)
+}
+
 type MemberRow struct {
 	Id           uint64
 	FirstName    *string
 	LastName     *string
 	EmailAddress *string
 	PhoneNumber  *string
+	Address      *string
+	CampusId     *uint64
 	Notes        string
+	DeletedAt    *time.Time
 }
 
 func (row *MemberRow) ToMember() (*Member, error) {
@@ -78,7 +142,10 @@ func (row *MemberRow) ToMember() (*Member, error) {
 		lastName:     row.LastName,
 		emailAddress: row.EmailAddress,
 		phoneNumber:  row.PhoneNumber,
+		address:      row.Address,
+		campusId:     row.CampusId,
 		notes:        row.Notes,
+		deletedAt:    row.DeletedAt,
 	}
 
 	return member, nil
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+type MemberChangeStatus string
+
+const (
+	MemberChangePending  MemberChangeStatus = "pending"
+	MemberChangeApproved MemberChangeStatus = "approved"
+	MemberChangeRejected MemberChangeStatus = "rejected"
+)
+
+// A change a member has made to their own details through /me, waiting for
+// approval if the church reviews such changes.
+type MemberChange struct {
+	id           uint64
+	memberId     uint64
+	requestedBy  *uint64
+	emailAddress *string
+	phoneNumber  *string
+	status       MemberChangeStatus
+	createdAt    time.Time
+	decidedAt    *time.Time
+	decidedBy    *string
+}
+
+func (change *MemberChange) ToResponseDTO() *MemberChangeResponseDTO {
+	return &MemberChangeResponseDTO{
+		Id:           change.id,
+		MemberId:     change.memberId,
+		RequestedBy:  change.requestedBy,
+		EmailAddress: change.emailAddress,
+		PhoneNumber:  change.phoneNumber,
+		Status:       change.status,
+		CreatedAt:    change.createdAt,
+		DecidedAt:    change.decidedAt,
+		DecidedBy:    change.decidedBy,
+	}
+}
+
+func (change *MemberChange) Id() uint64 {
+	return change.id
+}
+
+func (change *MemberChange) MemberId() uint64 {
+	return change.memberId
+}
+
+// The id of the user who made the change, or nil if they have been removed.
+func (change *MemberChange) RequestedBy() *uint64 {
+	if change.requestedBy == nil {
+		return nil
+	}
+
+	return util.NewPtr(*change.requestedBy)
+}
+
+func (change *MemberChange) Status() MemberChangeStatus {
+	return change.status
+}
+
+// Gives the member with the change made to it, keeping every other field.
+func (change *MemberChange) ApplyTo(member *Member) *MemberUpdateDTO {
+	return applySelfServiceChange(member, change.emailAddress, change.phoneNumber)
+}
+
+type MemberChangeRow struct {
+	Id           uint64
+	MemberId     uint64
+	RequestedBy  *uint64
+	EmailAddress *string
+	PhoneNumber  *string
+	Status       string
+	CreatedAt    time.Time
+	DecidedAt    *time.Time
+	DecidedBy    *string
+}
+
+func (row *MemberChangeRow) ToMemberChange() (*MemberChange, error) {
+	status := MemberChangeStatus(row.Status)
+	switch status {
+	case MemberChangePending, MemberChangeApproved, MemberChangeRejected:
+	default:
+		return nil, fmt.Errorf("unknown member change status %q", row.Status)
+	}
+
+	change := &MemberChange{
+		id:           row.Id,
+		memberId:     row.MemberId,
+		requestedBy:  row.RequestedBy,
+		emailAddress: row.EmailAddress,
+		phoneNumber:  row.PhoneNumber,
+		status:       status,
+		createdAt:    row.CreatedAt,
+		decidedAt:    row.DecidedAt,
+		decidedBy:    row.DecidedBy,
+	}
+
+	return change, nil
+}
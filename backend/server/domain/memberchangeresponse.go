@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type MemberChangeResponseDTO struct {
+	Id       uint64 `json:"id" example:"12"`
+	MemberId uint64 `json:"memberId" example:"81996"`
+	// The id of the user who made the change, or null if they have been removed
+	RequestedBy *uint64 `json:"requestedBy" example:"3"`
+	// The new email address, null if it is unchanged or empty if it is cleared
+	EmailAddress *string `json:"emailAddress" example:"aug.of.hippo@live.roma"`
+	// The new phone number, null if it is unchanged or empty if it is cleared
+	PhoneNumber *string            `json:"phoneNumber" example:"0434579344"`
+	Status      MemberChangeStatus `json:"status" example:"pending" enums:"pending,approved,rejected"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	DecidedAt   *time.Time         `json:"decidedAt"`
+	// The username of whoever approved or rejected the change
+	DecidedBy *string `json:"decidedBy" example:"ambrose"`
+} // @name MemberChangeResponse
@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Two members which are likely to be records of the same person.
+type MemberDuplicate struct {
+	First  *Member
+	Second *Member
+	// How likely the two members are to be the same person, from 0 to 1
+	Score float64
+	// Human readable reasons contributing to the score
+	Reasons []string
+}
+
+func (duplicate *MemberDuplicate) ToResponseDTO() *MemberDuplicateResponseDTO {
+	return &MemberDuplicateResponseDTO{
+		First:   *duplicate.First.ToResponseDTO(),
+		Second:  *duplicate.Second.ToResponseDTO(),
+		Score:   duplicate.Score,
+		Reasons: duplicate.Reasons,
+	}
+}
+
+// Names at least this similar are considered to contribute to a duplicate.
+const minNameSimilarity = 0.8
+
+// Phone numbers are compared by this many of their trailing digits, so that
+// the same number written with and without an international prefix match.
+const phoneNumberSignificantDigits = 9
+
+// Reduces a phone number to its significant digits, or gives the empty string
+// if it has too few digits to be compared.
+func NormalisePhoneNumber(phoneNumber string) string {
+	digits := strings.Builder{}
+	for _, r := range phoneNumber {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	normalised := digits.String()
+	if len(normalised) < phoneNumberSignificantDigits {
+		return ""
+	}
+	return normalised[len(normalised)-phoneNumberSignificantDigits:]
+}
+
+func normaliseEmailAddress(emailAddress *string) string {
+	if emailAddress == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(*emailAddress))
+}
+
+func normaliseName(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return strings.Join(strings.FieldsFunc(strings.ToLower(*name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}), " ")
+}
+
+func memberFullName(member *Member) string {
+	return strings.TrimSpace(normaliseName(member.firstName) + " " + normaliseName(member.lastName))
+}
+
+// Gives the similarity of two strings from 0 (nothing in common) to 1
+// (identical), based on their edit distance.
+func stringSimilarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			substitution := previous[j-1]
+			if ra[i-1] != rb[j-1] {
+				substitution += 1
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// Scores how likely two members are to be records of the same person, from 0
+// to 1, with the reasons for that score.
+func ScoreMemberDuplicate(a *Member, b *Member) (float64, []string) {
+	score := 0.0
+	reasons := make([]string, 0)
+
+	if email := normaliseEmailAddress(a.emailAddress); email != "" && email == normaliseEmailAddress(b.emailAddress) {
+		score += 0.6
+		reasons = append(reasons, "same email address")
+	}
+
+	if a.phoneNumber != nil && b.phoneNumber != nil {
+		if phone := NormalisePhoneNumber(*a.phoneNumber); phone != "" && phone == NormalisePhoneNumber(*b.phoneNumber) {
+			score += 0.5
+			reasons = append(reasons, "same phone number")
+		}
+	}
+
+	nameA, nameB := memberFullName(a), memberFullName(b)
+	if nameA != "" && nameB != "" {
+		similarity := stringSimilarity(nameA, nameB)
+		if similarity == 1 {
+			score += 0.5
+			reasons = append(reasons, "same name")
+		} else if similarity >= minNameSimilarity {
+			score += 0.5 * similarity
+			reasons = append(reasons, fmt.Sprintf("similar name (%.0f%%)", similarity*100))
+		}
+	}
+
+	return min(score, 1), reasons
+}
+
+// Gives the keys under which a member is compared with other members. Only
+// members sharing at least one key are compared, which avoids comparing every
+// pair of members.
+func memberDuplicateKeys(member *Member) []string {
+	keys := make([]string, 0, 3)
+	if email := normaliseEmailAddress(member.emailAddress); email != "" {
+		keys = append(keys, "email:"+email)
+	}
+	if member.phoneNumber != nil {
+		if phone := NormalisePhoneNumber(*member.phoneNumber); phone != "" {
+			keys = append(keys, "phone:"+phone)
+		}
+	}
+	firstName, lastName := []rune(normaliseName(member.firstName)), []rune(normaliseName(member.lastName))
+	if len(firstName) > 0 && len(lastName) > 0 {
+		keys = append(keys, "name:"+string(firstName[:1])+string(lastName[:min(2, len(lastName))]))
+	}
+	return keys
+}
+
+// Finds every pair of members scoring at least minScore, most likely
+// duplicates first.
+func FindMemberDuplicates(members []Member, minScore float64) []MemberDuplicate {
+	return findMemberDuplicates(members, members, minScore, true)
+}
+
+// Finds the members among existing which are likely duplicates of any of the
+// candidates, most likely duplicates first. The candidate is the first member
+// of each pair.
+func FindMemberDuplicatesOf(candidates []Member, existing []Member, minScore float64) []MemberDuplicate {
+	return findMemberDuplicates(candidates, existing, minScore, false)
+}
+
+func findMemberDuplicates(candidates []Member, existing []Member, minScore float64, sameSet bool) []MemberDuplicate {
+	blocks := make(map[string][]int)
+	for i := range existing {
+		for _, key := range memberDuplicateKeys(&existing[i]) {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	duplicates := make([]MemberDuplicate, 0)
+	for i := range candidates {
+		compared := make(map[int]bool)
+		for _, key := range memberDuplicateKeys(&candidates[i]) {
+			for _, j := range blocks[key] {
+				// within one set, only compare each pair once and never a member with itself
+				if compared[j] || (sameSet && j <= i) {
+					continue
+				}
+				compared[j] = true
+
+				score, reasons := ScoreMemberDuplicate(&candidates[i], &existing[j])
+				if score >= minScore {
+					duplicates = append(duplicates, MemberDuplicate{
+						First:   &candidates[i],
+						Second:  &existing[j],
+						Score:   score,
+						Reasons: reasons,
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Score > duplicates[j].Score
+	})
+
+	return duplicates
+}
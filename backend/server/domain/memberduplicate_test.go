@@ -0,0 +1,148 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func newTestMember(t *testing.T, row domain.MemberRow) domain.Member {
+	member, err := row.ToMember()
+	if err != nil {
+		t.Fatalf("error converting row to member: %v", err)
+	}
+	return *member
+}
+
+func TestNormalisePhoneNumber(t *testing.T) {
+	cases := map[string]string{
+		"0434 579 344":    "434579344",
+		"+61 434 579 344": "434579344",
+		"(04) 3457-9344":  "434579344",
+		"12345":           "",
+		"not a phone":     "",
+	}
+
+	for phoneNumber, expected := range cases {
+		if normalised := domain.NormalisePhoneNumber(phoneNumber); normalised != expected {
+			t.Errorf("NormalisePhoneNumber(%q): expected %q and got %q", phoneNumber, expected, normalised)
+		}
+	}
+}
+
+func TestFindMemberDuplicates(t *testing.T) {
+	members := []domain.Member{
+		newTestMember(t, domain.MemberRow{
+			Id:           1,
+			FirstName:    util.NewPtr("John"),
+			LastName:     util.NewPtr("Knox"),
+			EmailAddress: util.NewPtr("john.knox@edinburgh.sc"),
+		}),
+		newTestMember(t, domain.MemberRow{
+			Id:           2,
+			FirstName:    util.NewPtr("Jon"),
+			LastName:     util.NewPtr("Knox"),
+			EmailAddress: util.NewPtr("John.Knox@Edinburgh.sc "),
+		}),
+		newTestMember(t, domain.MemberRow{
+			Id:          3,
+			FirstName:   util.NewPtr("Andrew"),
+			LastName:    util.NewPtr("Melville"),
+			PhoneNumber: util.NewPtr("0434 579 344"),
+		}),
+		newTestMember(t, domain.MemberRow{
+			Id:          4,
+			FirstName:   util.NewPtr("Andrew"),
+			LastName:    util.NewPtr("Melville"),
+			PhoneNumber: util.NewPtr("+61434579344"),
+		}),
+		newTestMember(t, domain.MemberRow{
+			Id:        5,
+			FirstName: util.NewPtr("George"),
+			LastName:  util.NewPtr("Wishart"),
+		}),
+	}
+
+	duplicates := domain.FindMemberDuplicates(members, 0.5)
+
+	if len(duplicates) != 2 {
+		t.Fatalf("expected 2 pairs of duplicates, got %d", len(duplicates))
+	}
+
+	pairs := make(map[[2]uint64]domain.MemberDuplicate)
+	for _, duplicate := range duplicates {
+		pairs[[2]uint64{duplicate.First.Id(), duplicate.Second.Id()}] = duplicate
+	}
+
+	if duplicate, ok := pairs[[2]uint64{3, 4}]; !ok || duplicate.Score != 1 {
+		t.Errorf("expected the same name and phone number to be a certain duplicate, got %v", duplicate)
+	}
+
+	if duplicate, ok := pairs[[2]uint64{1, 2}]; !ok || len(duplicate.Reasons) != 2 {
+		t.Errorf("expected the same email address and similar name to be a duplicate, got %v", duplicate)
+	}
+
+	existing := members[2:]
+	candidates := members[:1]
+	if found := domain.FindMemberDuplicatesOf(candidates, existing, 0.5); len(found) != 0 {
+		t.Errorf("expected no duplicates of an unrelated member, got %v", found)
+	}
+}
+
+func TestMemberMerge(t *testing.T) {
+	survivor := newTestMember(t, domain.MemberRow{
+		Id:           1,
+		FirstName:    util.NewPtr("Theodore"),
+		LastName:     util.NewPtr("Beza"),
+		EmailAddress: util.NewPtr("beza@geneva.ch"),
+		Notes:        "Succeeded Calvin",
+	})
+	merged := newTestMember(t, domain.MemberRow{
+		Id:           2,
+		FirstName:    util.NewPtr("Théodore"),
+		LastName:     util.NewPtr("de Bèze"),
+		EmailAddress: util.NewPtr("theodore@geneva.ch"),
+		PhoneNumber:  util.NewPtr("0434579344"),
+		CampusId:     util.NewPtr(uint64(4)),
+	})
+
+	mergeDto := domain.MemberMergeDTO{
+		SurvivorId: 1,
+		MergedId:   2,
+		Fields: map[string]domain.MemberMergeSource{
+			"emailAddress": domain.MergeFromMerged,
+		},
+	}
+
+	if errs := mergeDto.Validate(); len(errs) > 0 {
+		t.Fatalf("expected merge to be valid, got %v", errs)
+	}
+
+	update := mergeDto.Merge(&survivor, &merged)
+
+	if *update.FirstName != "Theodore" {
+		t.Errorf("expected the survivor's first name to be kept, got %s", *update.FirstName)
+	}
+	if *update.EmailAddress != "theodore@geneva.ch" {
+		t.Errorf("expected the merged member's email address to be chosen, got %s", *update.EmailAddress)
+	}
+	if update.PhoneNumber == nil || *update.PhoneNumber != "0434579344" {
+		t.Errorf("expected the merged member's phone number to fill the survivor's empty one, got %v", update.PhoneNumber)
+	}
+	if update.CampusId == nil || *update.CampusId != 4 {
+		t.Errorf("expected the merged member's campus to fill the survivor's empty one, got %v", update.CampusId)
+	}
+	if update.Notes != "Succeeded Calvin" {
+		t.Errorf("expected the survivor's notes to be kept, got %s", update.Notes)
+	}
+
+	invalid := domain.MemberMergeDTO{
+		SurvivorId: 1,
+		MergedId:   1,
+		Fields:     map[string]domain.MemberMergeSource{"id": domain.MergeFromMerged},
+	}
+	if errs := invalid.Validate(); len(errs) != 2 {
+		t.Errorf("expected errors for merging a member with itself and for an unknown field, got %v", errs)
+	}
+}
@@ -0,0 +1,8 @@
+package domain
+
+type MemberDuplicateResponseDTO struct {
+	First   MemberResponseDTO `json:"first"`
+	Second  MemberResponseDTO `json:"second"`
+	Score   float64           `json:"score" example:"0.85"`
+	Reasons []string          `json:"reasons" example:"same email address,similar name (92%)"`
+} // @name MemberDuplicateResponse
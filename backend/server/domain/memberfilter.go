@@ -0,0 +1,11 @@
+package domain
+
+// Criteria for selecting members. Nil or empty fields do not filter the
+// results.
+type MemberFilter struct {
+	// Case-insensitive text which must appear in the member's first name, last
+	// name or email address
+	Search *string
+	// The campuses the member must belong to one of
+	CampusIds []uint64
+}
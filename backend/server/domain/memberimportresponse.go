@@ -0,0 +1,34 @@
+package domain
+
+type MemberImportRowStatus string
+
+const (
+	// The row passed validation but has not been imported
+	ImportRowValid MemberImportRowStatus = "valid"
+	// The row failed validation
+	ImportRowInvalid MemberImportRowStatus = "invalid"
+	// The row is likely a duplicate of an existing member
+	ImportRowDuplicate MemberImportRowStatus = "duplicate"
+	// The row was imported as a new member
+	ImportRowImported MemberImportRowStatus = "imported"
+	// The row was not imported because it is likely a duplicate
+	ImportRowSkipped MemberImportRowStatus = "skipped"
+)
+
+type MemberImportRowResponseDTO struct {
+	// The line of the row in the file, where the header is line 1
+	Line   int                   `json:"line" example:"2"`
+	Status MemberImportRowStatus `json:"status" example:"valid"`
+	Member MemberUpdateDTO       `json:"member"`
+	Errors []string              `json:"errors"`
+	// Existing members the row is likely a duplicate of
+	Duplicates []MemberDuplicateResponseDTO `json:"duplicates"`
+	// The id of the member created from the row, if it was imported
+	CreatedId *uint64 `json:"createdId,omitempty" example:"81996"`
+} // @name MemberImportRowResponse
+
+type MemberImportResponseDTO struct {
+	DryRun   bool                         `json:"dryRun"`
+	Imported int                          `json:"imported" example:"120"`
+	Rows     []MemberImportRowResponseDTO `json:"rows"`
+} // @name MemberImportResponse
@@ -0,0 +1,91 @@
+package domain
+
+import "fmt"
+
+// Which of the two merged members a field's value is taken from.
+type MemberMergeSource string
+
+const (
+	MergeFromSurvivor MemberMergeSource = "survivor"
+	MergeFromMerged   MemberMergeSource = "merged"
+)
+
+type MemberMergeDTO struct {
+	// The member which remains after the merge
+	SurvivorId uint64 `json:"survivorId" example:"81996"`
+	// The member which is moved to the trash after the merge
+	MergedId uint64 `json:"mergedId" example:"81997"`
+	// Which member each field is taken from, by the field's JSON name. Fields
+	// which are not given keep the survivor's value unless it is empty.
+	Fields map[string]MemberMergeSource `json:"fields" example:"emailAddress:merged"`
+} // @name MemberMerge
+
+var memberMergeFields = []string{"firstName", "lastName", "emailAddress", "phoneNumber", "address", "campusId", "notes"}
+
+func (dto *MemberMergeDTO) Validate() []error {
+	errs := make([]error, 0)
+
+	if dto.SurvivorId == 0 {
+		errs = append(errs, fmt.Errorf("field survivorId must be given"))
+	}
+
+	if dto.MergedId == 0 {
+		errs = append(errs, fmt.Errorf("field mergedId must be given"))
+	}
+
+	if dto.SurvivorId != 0 && dto.SurvivorId == dto.MergedId {
+		errs = append(errs, fmt.Errorf("a member cannot be merged with itself"))
+	}
+
+	for field, source := range dto.Fields {
+		known := false
+		for _, f := range memberMergeFields {
+			known = known || f == field
+		}
+		if !known {
+			errs = append(errs, fmt.Errorf("unknown field %q, expected one of %v", field, memberMergeFields))
+		}
+		if source != MergeFromSurvivor && source != MergeFromMerged {
+			errs = append(errs, fmt.Errorf("field %q must be merged from %q or %q, got %q", field, MergeFromSurvivor, MergeFromMerged, source))
+		}
+	}
+
+	return errs
+}
+
+// Gives the update which makes the survivor the merge of the two members.
+func (dto *MemberMergeDTO) Merge(survivor *Member, merged *Member) *MemberUpdateDTO {
+	chooseString := func(field string, survivorValue *string, mergedValue *string) *string {
+		switch dto.Fields[field] {
+		case MergeFromSurvivor:
+			return survivorValue
+		case MergeFromMerged:
+			return mergedValue
+		}
+		if survivorValue == nil || *survivorValue == "" {
+			return mergedValue
+		}
+		return survivorValue
+	}
+
+	campusId := survivor.campusId
+	switch dto.Fields["campusId"] {
+	case MergeFromSurvivor:
+	case MergeFromMerged:
+		campusId = merged.campusId
+	default:
+		if campusId == nil {
+			campusId = merged.campusId
+		}
+	}
+
+	return &MemberUpdateDTO{
+		FirstName:    chooseString("firstName", survivor.firstName, merged.firstName),
+		LastName:     chooseString("lastName", survivor.lastName, merged.lastName),
+		EmailAddress: chooseString("emailAddress", survivor.emailAddress, merged.emailAddress),
+		PhoneNumber:  chooseString("phoneNumber", survivor.phoneNumber, merged.phoneNumber),
+		Address:      chooseString("address", survivor.address, merged.address),
+		CampusId:     campusId,
+		Notes:        *chooseString("notes", &survivor.notes, &merged.notes),
+	}
+}
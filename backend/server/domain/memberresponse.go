@@ -1,10 +1,17 @@
 package domain
 
+import "time"
+
 type MemberResponseDTO struct {
 	Id           uint64  `json:"id" example:"81996"`
 	FirstName    *string `json:"firstName" example:"Augustinus"`
 	LastName     *string `json:"lastName" example:"Hipponensis"`
 	EmailAddress *string `json:"emailAddress" example:"aug.of.hippo@live.roma"`
 	PhoneNumber  *string `json:"phoneNumber" example:"0434579344"`
+	Address      *string `json:"address" example:"1 Basilica Way\nHippo Regius"`
+	CampusId     *uint64 `json:"campusId" example:"2"`
 	Notes        string  `json:"notes" example:"Fluent in Latin and Greek."`
+	// Set when the notes are withheld because the user may not read them
+	NotesRedacted bool       `json:"notesRedacted,omitempty"`
+	DeletedAt     *time.Time `json:"deletedAt,omitempty"`
 } // @name MemberResponse
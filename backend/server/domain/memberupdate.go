@@ -1,13 +1,47 @@
 package domain
 
+import (
+	"fmt"
+	"net/mail"
+)
+
 type MemberUpdateDTO struct {
 	FirstName    *string `json:"firstName" example:"Augustinus"`
 	LastName     *string `json:"lastName" example:"Hipponensis"`
 	EmailAddress *string `json:"emailAddress" validate:"email" example:"aug.of.hippo@live.roma"`
 	PhoneNumber  *string `json:"phoneNumber" example:"0434579344"`
-	Notes        string  `json:"notes" example:"Fluent in Latin and Greek."`
+	Address      *string `json:"address" example:"1 Basilica Way\nHippo Regius"`
+	// The campus the member belongs to, if any
+	CampusId *uint64 `json:"campusId" example:"2"`
+	Notes    string  `json:"notes" example:"Fluent in Latin and Greek."`
 } // @name MemberUpdate
 
+// The maximum lengths of the member table's VARCHAR columns.
+const (
+	maxMemberNameLength         = 128
+	maxMemberEmailAddressLength = 256
+	maxMemberPhoneNumberLength  = 128
+)
+
 func (dto *MemberUpdateDTO) Validate() []error {
-	return []error{}
+	errs := make([]error, 0)
+
+	checkLength := func(field string, value *string, maxLength int) {
+		if value != nil && len(*value) > maxLength {
+			errs = append(errs, fmt.Errorf("field %s cannot be longer than %d bytes, got %d", field, maxLength, len(*value)))
+		}
+	}
+
+	checkLength("firstName", dto.FirstName, maxMemberNameLength)
+	checkLength("lastName", dto.LastName, maxMemberNameLength)
+	checkLength("emailAddress", dto.EmailAddress, maxMemberEmailAddressLength)
+	checkLength("phoneNumber", dto.PhoneNumber, maxMemberPhoneNumberLength)
+
+	if dto.EmailAddress != nil {
+		if address, err := mail.ParseAddress(*dto.EmailAddress); err != nil || address.Address != *dto.EmailAddress {
+			errs = append(errs, fmt.Errorf("field emailAddress must be a plain email address, got \"%s\"", *dto.EmailAddress))
+		}
+	}
+
+	return errs
 }
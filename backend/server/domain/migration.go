@@ -0,0 +1,30 @@
+package domain
+
+// The version of the schema, as recorded by the migrations. A dirty version
+// is one whose migration failed part of the way through, which has to be
+// fixed by hand before the database can be migrated again.
+type MigrationStatus struct {
+	Version uint
+	Dirty   bool
+}
+
+type MigrationStatusResponseDTO struct {
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	// The version the server was started with, which it expects the database
+	// to be at
+	ExpectedVersion uint `json:"expectedVersion"`
+}
+
+// Whether the database is at the expected version and may be used.
+func (status *MigrationStatus) IsAt(expectedVersion uint) bool {
+	return !status.Dirty && status.Version == expectedVersion
+}
+
+func (status *MigrationStatus) ToResponseDTO(expectedVersion uint) *MigrationStatusResponseDTO {
+	return &MigrationStatusResponseDTO{
+		Version:         status.Version,
+		Dirty:           status.Dirty,
+		ExpectedVersion: expectedVersion,
+	}
+}
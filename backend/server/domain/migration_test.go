@@ -0,0 +1,19 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+func TestMigrationStatusIsAt(t *testing.T) {
+	if !(&domain.MigrationStatus{Version: 13}).IsAt(13) {
+		t.Error("expected a clean database at the expected version to be usable")
+	}
+	if (&domain.MigrationStatus{Version: 12}).IsAt(13) {
+		t.Error("expected a database behind the expected version not to be usable")
+	}
+	if (&domain.MigrationStatus{Version: 13, Dirty: true}).IsAt(13) {
+		t.Error("expected a dirty database not to be usable")
+	}
+}
@@ -0,0 +1,21 @@
+package domain
+
+// A single sign-on login which has been started but not yet finished, kept to
+// check the provider's response against.
+type OIDCLogin struct {
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+}
+
+type OIDCStartResponseDTO struct {
+	// Where to send the user to log in at the provider
+	AuthorizationURL string `json:"authorizationUrl" example:"https://accounts.google.com/o/oauth2/v2/auth?client_id=..."`
+	// Passed back by the provider, to be given to the callback endpoint with the code
+	State string `json:"state" example:"Jc5xq3lW7bT0nYk2aPz8r1QmF4vH6sEoUdG9iLwXyBc"`
+} // @name OIDCStartResponse
+
+type OIDCCallbackDTO struct {
+	Code  string `json:"code" example:"4/0AQlEd8x..."`
+	State string `json:"state" example:"Jc5xq3lW7bT0nYk2aPz8r1QmF4vH6sEoUdG9iLwXyBc"`
+} // @name OIDCCallback
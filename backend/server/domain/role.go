@@ -0,0 +1,85 @@
+package domain
+
+import "slices"
+
+// A right to use part of the API, named <resource>:<action>.
+type Permission string
+
+const (
+	PermissionMembersRead      Permission = "members:read"
+	PermissionMembersWrite     Permission = "members:write"
+	PermissionMemberNotesRead  Permission = "members:notes:read"
+	PermissionMemberNotesWrite Permission = "members:notes:write"
+	PermissionSchedulesRead    Permission = "schedules:read"
+	PermissionSchedulesWrite   Permission = "schedules:write"
+	PermissionAuditRead        Permission = "audit:read"
+	PermissionUsersManage      Permission = "users:manage"
+	PermissionAPIKeysManage    Permission = "apikeys:manage"
+	PermissionCampusesManage   Permission = "campuses:manage"
+	PermissionMigrationsRead   Permission = "migrations:read"
+)
+
+// Every permission, e.g. for checking the scopes of an API key.
+var Permissions = []Permission{
+	PermissionMembersRead, PermissionMembersWrite,
+	PermissionMemberNotesRead, PermissionMemberNotesWrite,
+	PermissionSchedulesRead, PermissionSchedulesWrite,
+	PermissionAuditRead, PermissionUsersManage, PermissionAPIKeysManage,
+	PermissionCampusesManage, PermissionMigrationsRead,
+}
+
+// Something a request is authenticated as, such as a user or an API key.
+type Principal interface {
+	HasPermission(permission Permission) bool
+}
+
+// A set of permissions given to a user according to their part in the church.
+type Role string
+
+const (
+	RoleAdmin             Role = "admin"
+	RolePastor            Role = "pastor"
+	RoleOfficeAdmin       Role = "office_admin"
+	RoleRosterCoordinator Role = "roster_coordinator"
+	RoleWelcomeTeam       Role = "welcome_team"
+)
+
+// Only roles with pastoral access may read and write members' notes.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: Permissions,
+	RolePastor: {
+		PermissionMembersRead, PermissionMembersWrite,
+		PermissionMemberNotesRead, PermissionMemberNotesWrite,
+		PermissionSchedulesRead, PermissionSchedulesWrite, PermissionAuditRead,
+	},
+	RoleOfficeAdmin: {
+		PermissionMembersRead, PermissionMembersWrite,
+		PermissionSchedulesRead, PermissionSchedulesWrite, PermissionAuditRead,
+	},
+	RoleRosterCoordinator: {
+		PermissionMembersRead, PermissionSchedulesRead, PermissionSchedulesWrite,
+	},
+	RoleWelcomeTeam: {
+		PermissionMembersRead, PermissionMembersWrite, PermissionSchedulesRead,
+	},
+}
+
+// Every role, in order of decreasing access.
+var Roles = []Role{RoleAdmin, RolePastor, RoleOfficeAdmin, RoleRosterCoordinator, RoleWelcomeTeam}
+
+func (permission Permission) Valid() bool {
+	return slices.Contains(Permissions, permission)
+}
+
+func (role Role) Valid() bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func (role Role) Permissions() []Permission {
+	return slices.Clone(rolePermissions[role])
+}
+
+func (role Role) HasPermission(permission Permission) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
@@ -0,0 +1,52 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+func TestRolePermissions(t *testing.T) {
+	for _, role := range domain.Roles {
+		if !role.Valid() {
+			t.Errorf("expected role %s to be valid", role)
+		}
+		if !role.HasPermission(domain.PermissionMembersRead) {
+			t.Errorf("expected every role to be able to read members, but %s cannot", role)
+		}
+	}
+
+	if domain.Role("treasurer").Valid() {
+		t.Error("expected an unknown role to be invalid")
+	}
+
+	for _, role := range []domain.Role{domain.RoleOfficeAdmin, domain.RoleRosterCoordinator, domain.RoleWelcomeTeam} {
+		if role.HasPermission(domain.PermissionMemberNotesRead) {
+			t.Errorf("expected role %s not to have pastoral access to notes", role)
+		}
+	}
+
+	if !domain.RolePastor.HasPermission(domain.PermissionMemberNotesRead) {
+		t.Error("expected pastors to be able to read notes")
+	}
+}
+
+func TestUserPermissions(t *testing.T) {
+	row := domain.UserRow{Id: 1, Username: "roster", Roles: []string{"roster_coordinator", "welcome_team"}}
+	user, err := row.ToUser()
+	if err != nil {
+		t.Fatalf("error converting row to user: %v", err)
+	}
+
+	if !user.HasPermission(domain.PermissionSchedulesWrite) || !user.HasPermission(domain.PermissionMembersWrite) {
+		t.Error("expected a user to have the permissions of each of their roles")
+	}
+	if user.HasPermission(domain.PermissionUsersManage) {
+		t.Error("expected a user not to have permissions none of their roles have")
+	}
+
+	row.Roles = []string{"treasurer"}
+	if _, err := row.ToUser(); err == nil {
+		t.Error("expected a row with an unknown role to be rejected")
+	}
+}
@@ -3,6 +3,8 @@ package domain
 import (
 	"fmt"
 	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/util"
 )
 
 type Schedule struct {
@@ -11,6 +13,7 @@ type Schedule struct {
 	endDate             *time.Time
 	repeatInterval      *scheduleRepeatInterval
 	repeatNthDayOfMonth *scheduleRepeatNthDayOfMonth
+	campusId            *uint64
 }
 
 type scheduleRepeatInterval struct {
@@ -68,9 +71,24 @@ func (schedule *Schedule) ToResponseDTO() *ScheduleResponseDTO {
 		EndDate:             schedule.endDate,
 		RepeatInterval:      repeatInterval,
 		RepeatNthDayOfMonth: repeatNthDayOfMonth,
+		CampusId:            schedule.CampusId(),
 	}
 }
 
+func (schedule *Schedule) Id() uint64 {
+	return schedule.id
+}
+
+// The id of the campus the services are held at, or nil if they are not held
+// at any one campus.
+func (schedule *Schedule) CampusId() *uint64 {
+	if schedule.campusId == nil {
+		return nil
+	}
+
+	return util.NewPtr(*schedule.campusId)
+}
+
 type ScheduleRow struct {
 	Id                     *uint64
 	BeginDate              *time.Time
@@ -79,6 +97,7 @@ type ScheduleRow struct {
 	RepeatIntervalUnit     *ScheduleRepeatUnit
 	RepeatNthDayOfMonthDay *ScheduleDayOfWeek
 	RepeatNthDayOfMonthN   *int
+	CampusId               *uint64
 }
 
 func (row *ScheduleRow) ToSchedule() (*Schedule, error) {
@@ -125,6 +144,7 @@ func (row *ScheduleRow) ToSchedule() (*Schedule, error) {
 		endDate:             row.EndDate,
 		repeatInterval:      repeatInterval,
 		repeatNthDayOfMonth: repeatNthDayOfMonth,
+		campusId:            row.CampusId,
 	}
 
 	return schedule, nil
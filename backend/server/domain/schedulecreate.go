@@ -10,6 +10,8 @@ type ScheduleCreateDTO struct {
 	EndDate             *time.Time                            `json:"endDate"`
 	RepeatInterval      *ScheduleCreateDTORepeatInterval      `json:"repeatInterval"`
 	RepeatNthDayOfMonth *ScheduleCreateDTORepeatNthDayOfMonth `json:"repeatNthDayOfMonth"`
+	// The campus the services are held at, if any
+	CampusId *uint64 `json:"campusId"`
 }
 
 type ScheduleCreateDTORepeatInterval struct {
@@ -0,0 +1,8 @@
+package domain
+
+// Criteria for selecting schedules. Nil or empty fields do not filter the
+// results.
+type ScheduleFilter struct {
+	// The campuses the services must be held at one of
+	CampusIds []uint64
+}
@@ -10,6 +10,7 @@ type ScheduleResponseDTO struct {
 	EndDate             *time.Time                              `json:"endDate"`
 	RepeatInterval      *ScheduleResponseDTORepeatInterval      `json:"repeatInterval"`
 	RepeatNthDayOfMonth *ScheduleResponseDTORepeatNthDayOfMonth `json:"repeatNthDayOfMonth"`
+	CampusId            *uint64                                 `json:"campusId"`
 }
 
 type ScheduleResponseDTORepeatInterval struct {
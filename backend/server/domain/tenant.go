@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// A church served by the deployment, whose rows no other church may see.
+type Tenant struct {
+	id        uint64
+	slug      string
+	name      string
+	createdAt time.Time
+}
+
+func (tenant *Tenant) Id() uint64 {
+	return tenant.id
+}
+
+// The lowercase name which identifies the church in subdomains, the X-Tenant
+// header and tokens.
+func (tenant *Tenant) Slug() string {
+	return tenant.slug
+}
+
+func (tenant *Tenant) Name() string {
+	return tenant.name
+}
+
+func (tenant *Tenant) CreatedAt() time.Time {
+	return tenant.createdAt
+}
+
+type TenantRow struct {
+	Id        uint64
+	Slug      string
+	Name      string
+	CreatedAt time.Time
+}
+
+func (row *TenantRow) ToTenant() (*Tenant, error) {
+	tenant := &Tenant{
+		id:        row.Id,
+		slug:      row.Slug,
+		name:      row.Name,
+		createdAt: row.CreatedAt,
+	}
+
+	return tenant, nil
+}
+
+const maxTenantNameLength = 256
+
+// Slugs must be usable as a DNS label.
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+func ValidateTenant(slug string, name string) []error {
+	errs := make([]error, 0)
+
+	if !tenantSlugPattern.MatchString(slug) {
+		errs = append(errs, fmt.Errorf("slug must be 1 to 63 lowercase letters, digits and '-', not starting or ending with '-', got \"%s\"", slug))
+	}
+	if name == "" || len(name) > maxTenantNameLength {
+		errs = append(errs, fmt.Errorf("name must be from 1 to %d bytes long, got %d", maxTenantNameLength, len(name)))
+	}
+
+	return errs
+}
@@ -0,0 +1,26 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+func TestTenantValidation(t *testing.T) {
+	for _, slug := range []string{"grace", "st-pauls", "church2", strings.Repeat("a", 63)} {
+		if errs := domain.ValidateTenant(slug, "A church"); len(errs) > 0 {
+			t.Errorf("expected slug %q to be valid, got %v", slug, errs)
+		}
+	}
+
+	for _, slug := range []string{"", "Grace", "st.pauls", "-grace", "grace-", "st pauls", strings.Repeat("a", 64)} {
+		if errs := domain.ValidateTenant(slug, "A church"); len(errs) == 0 {
+			t.Errorf("expected slug %q to be invalid", slug)
+		}
+	}
+
+	if errs := domain.ValidateTenant("grace", ""); len(errs) == 0 {
+		t.Error("expected a tenant without a name to be invalid")
+	}
+}
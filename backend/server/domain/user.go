@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+type User struct {
+	id           uint64
+	username     string
+	passwordHash *string
+	roles        []Role
+	memberId     *uint64
+	campusIds    []uint64
+	createdAt    time.Time
+}
+
+func (user *User) ToResponseDTO() *UserResponseDTO {
+	return &UserResponseDTO{
+		Id:        user.id,
+		Username:  user.username,
+		Roles:     user.Roles(),
+		MemberId:  user.MemberId(),
+		CampusIds: user.CampusIds(),
+		CreatedAt: user.createdAt,
+	}
+}
+
+func (user *User) Id() uint64 {
+	return user.id
+}
+
+func (user *User) Username() string {
+	return user.username
+}
+
+// The argon2id hash of the user's password, in the PHC string format, or nil
+// if the user only logs in with single sign-on.
+func (user *User) PasswordHash() *string {
+	if user.passwordHash == nil {
+		return nil
+	}
+
+	return util.NewPtr(*user.passwordHash)
+}
+
+func (user *User) Roles() []Role {
+	return slices.Clone(user.roles)
+}
+
+// Whether any of the user's roles has the permission.
+func (user *User) HasPermission(permission Permission) bool {
+	for _, role := range user.roles {
+		if role.HasPermission(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// The id of the member the user is, or nil if the user is not linked to a
+// member.
+func (user *User) MemberId() *uint64 {
+	if user.memberId == nil {
+		return nil
+	}
+
+	return util.NewPtr(*user.memberId)
+}
+
+// The campuses whose members and schedules the user is limited to. Empty if
+// the user may see every campus.
+func (user *User) CampusIds() []uint64 {
+	return append(make([]uint64, 0, len(user.campusIds)), user.campusIds...)
+}
+
+func (user *User) CreatedAt() time.Time {
+	return user.createdAt
+}
+
+type UserRow struct {
+	Id           uint64
+	Username     string
+	PasswordHash *string
+	Roles        []string
+	MemberId     *uint64
+	CampusIds    []uint64
+	CreatedAt    time.Time
+}
+
+func (row *UserRow) ToUser() (*User, error) {
+	roles := make([]Role, len(row.Roles))
+	for i, name := range row.Roles {
+		if roles[i] = Role(name); !roles[i].Valid() {
+			return nil, fmt.Errorf("unknown role %q", name)
+		}
+	}
+
+	user := &User{
+		id:           row.Id,
+		username:     row.Username,
+		passwordHash: row.PasswordHash,
+		roles:        roles,
+		memberId:     row.MemberId,
+		campusIds:    row.CampusIds,
+		createdAt:    row.CreatedAt,
+	}
+
+	return user, nil
+}
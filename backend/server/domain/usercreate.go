@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+type UserCreateDTO struct {
+	Username string `json:"username" example:"ambrose"`
+	Password string `json:"password" example:"correct horse battery staple"`
+	Roles    []Role `json:"roles" example:"office_admin"`
+	// The member the user is, if any
+	MemberId *uint64 `json:"memberId" example:"81996"`
+} // @name UserCreate
+
+// Replaces the roles of a user.
+type UserRolesDTO struct {
+	Roles []Role `json:"roles" example:"pastor,office_admin"`
+} // @name UserRoles
+
+// Links a user to the member they are, or unlinks them when the id is null.
+type UserMemberDTO struct {
+	MemberId *uint64 `json:"memberId" example:"81996"`
+} // @name UserMember
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 128
+	minPasswordLength = 12
+	// Bounds the work done hashing a password.
+	maxPasswordLength = 1024
+)
+
+// Email addresses are allowed, as users who log in with single sign-on are
+// often named after theirs.
+var usernamePattern = regexp.MustCompile(`^[a-z0-9._@+-]+$`)
+
+// Usernames are case insensitive, and kept in lowercase.
+func NormaliseUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func (dto *UserCreateDTO) Validate() []error {
+	errs := make([]error, 0)
+
+	if err := ValidateUsername(NormaliseUsername(dto.Username)); err != nil {
+		errs = append(errs, err)
+	}
+
+	if length := utf8.RuneCountInString(dto.Password); length < minPasswordLength {
+		errs = append(errs, fmt.Errorf("field password must be at least %d characters long, got %d", minPasswordLength, length))
+	} else if len(dto.Password) > maxPasswordLength {
+		errs = append(errs, fmt.Errorf("field password cannot be longer than %d bytes, got %d", maxPasswordLength, len(dto.Password)))
+	}
+
+	return append(errs, validateRoles(dto.Roles)...)
+}
+
+// Checks a normalised username.
+func ValidateUsername(username string) error {
+	if len(username) < minUsernameLength || len(username) > maxUsernameLength {
+		return fmt.Errorf("field username must be from %d to %d characters long, got %d", minUsernameLength, maxUsernameLength, len(username))
+	}
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("field username may only contain letters, digits and any of '.', '_', '@', '+' and '-', got \"%s\"", username)
+	}
+	return nil
+}
+
+func (dto *UserRolesDTO) Validate() []error {
+	return validateRoles(dto.Roles)
+}
+
+func validateRoles(roles []Role) []error {
+	errs := make([]error, 0)
+
+	for _, role := range roles {
+		if !role.Valid() {
+			errs = append(errs, fmt.Errorf("unknown role %q, expected one of %v", role, Roles))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type UserResponseDTO struct {
+	Id       uint64 `json:"id" example:"3"`
+	Username string `json:"username" example:"ambrose"`
+	Roles    []Role `json:"roles" example:"pastor"`
+	// The member the user is, who they may view and update through /me
+	MemberId *uint64 `json:"memberId" example:"81996"`
+	// The campuses whose members and schedules the user is limited to, or
+	// empty if the user may see every campus
+	CampusIds []uint64  `json:"campusIds" example:"2"`
+	CreatedAt time.Time `json:"createdAt"`
+} // @name UserResponse
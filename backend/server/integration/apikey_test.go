@@ -0,0 +1,207 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestAPIKeys(t *testing.T) {
+	RunOnTestBackends(t, testAPIKeys)
+}
+
+func testAPIKeys(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	server := StartTestServer(t, stores, DefaultServerConfig(backend))
+	token := LoginTestUser(t, stores, server.URL, "apikey.tester", domain.RoleAdmin)
+
+	t.Run("API keys are limited to their scopes until revoked", func(t *testing.T) {
+		admin := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+		welcome := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     LoginTestUser(t, stores, server.URL, "key.maker", domain.RoleWelcomeTeam),
+		}
+
+		response := welcome.MakeRequest("POST", "/admin/api-keys", &domain.APIKeyCreateDTO{
+			Name:   "Not allowed",
+			Scopes: []domain.Permission{domain.PermissionMembersRead},
+		}, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected the welcome team creating an API key to be 403 Forbidden, but was %s", response.Status)
+		}
+
+		var created domain.APIKeyCreatedResponseDTO
+		response = admin.MakeRequest("POST", "/admin/api-keys", &domain.APIKeyCreateDTO{
+			Name:   "Directory sync",
+			Scopes: []domain.Permission{domain.PermissionMembersRead},
+		}, &created)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected creating an API key to be 201 Created, but was %s", response.Status)
+		}
+		if !strings.HasPrefix(created.Key, "default."+created.Prefix) || created.LastUsedAt != nil {
+			t.Errorf("expected an unused key starting with its church and prefix, got %v", created)
+		}
+
+		key := TestRestClient{t: t, serverUrl: server.URL, token: created.Key}
+		response = key.MakeRequest("GET", "/members", nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected the key to be able to read members, but was %s", response.Status)
+		}
+		for _, request := range []struct {
+			method string
+			path   string
+		}{
+			{"POST", "/members"},
+			{"GET", "/audit"},
+			{"GET", "/admin/api-keys"},
+		} {
+			response := key.MakeRequest(request.method, request.path, nil, nil)
+			if response.StatusCode != http.StatusForbidden {
+				t.Errorf("expected the key to get 403 Forbidden for %s %s, but was %s", request.method, request.path, response.Status)
+			}
+		}
+
+		response = welcome.MakeRequest("GET", "/admin/api-keys", nil, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected the welcome team listing API keys to be 403 Forbidden, but was %s", response.Status)
+		}
+
+		var keys []domain.APIKeyResponseDTO
+		admin.MakeRequest("GET", "/admin/api-keys", nil, &keys)
+		found := false
+		for _, listed := range keys {
+			if listed.Id == created.Id {
+				found = true
+				if listed.LastUsedAt == nil {
+					t.Errorf("expected the key to have been marked as used, got %v", listed)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected key %d to be listed, got %v", created.Id, keys)
+		}
+
+		response = admin.MakeRequest("DELETE", fmt.Sprintf("/admin/api-keys/%d", created.Id), nil, nil)
+		if response.StatusCode != http.StatusNoContent {
+			t.Fatalf("expected revoking the key to be 204 No Content, but was %s", response.Status)
+		}
+		response = key.MakeRequest("GET", "/members", nil, nil)
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected a revoked key to be 401 Unauthorized, but was %s", response.Status)
+		}
+
+		response = admin.MakeRequest("DELETE", "/admin/api-keys/999999", nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected revoking an unknown key to be 404 Not Found, but was %s", response.Status)
+		}
+	})
+
+	t.Run("A key scoped to schedules can read them", func(t *testing.T) {
+		admin := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		var schedule domain.ScheduleResponseDTO
+		response := admin.MakeRequest("POST", "/schedules", &domain.ScheduleCreateDTO{
+			BeginDate:      util.NewPtr(time.Date(2024, time.March, 3, 10, 0, 0, 0, time.UTC)),
+			RepeatInterval: &domain.ScheduleCreateDTORepeatInterval{Count: 1, Unit: domain.RepeatUnitWeek},
+		}, &schedule)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected creating a schedule to be 200 OK, but was %s", response.Status)
+		}
+
+		var created domain.APIKeyCreatedResponseDTO
+		response = admin.MakeRequest("POST", "/admin/api-keys", &domain.APIKeyCreateDTO{
+			Name:   "Roster sync",
+			Scopes: []domain.Permission{domain.PermissionSchedulesRead},
+		}, &created)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected creating an API key to be 201 Created, but was %s", response.Status)
+		}
+
+		key := TestRestClient{t: t, serverUrl: server.URL, token: created.Key}
+		var schedules []domain.ScheduleResponseDTO
+		response = key.MakeRequest("GET", "/schedules", nil, &schedules)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected the key to be able to read schedules, but was %s", response.Status)
+		}
+		found := false
+		for _, listed := range schedules {
+			found = found || listed.Id == schedule.Id
+		}
+		if !found {
+			t.Errorf("expected schedule %d to be listed, got %v", schedule.Id, schedules)
+		}
+
+		for _, request := range []struct {
+			method string
+			path   string
+		}{
+			{"POST", "/schedules"},
+			{"GET", "/members"},
+		} {
+			response := key.MakeRequest(request.method, request.path, nil, nil)
+			if response.StatusCode != http.StatusForbidden {
+				t.Errorf("expected the key to get 403 Forbidden for %s %s, but was %s", request.method, request.path, response.Status)
+			}
+		}
+	})
+	t.Run("Keys lose the scopes their creator loses", func(t *testing.T) {
+		admin := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+		owner := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     LoginTestUser(t, stores, server.URL, "key.owner", domain.RoleAdmin),
+		}
+
+		var me domain.MeResponseDTO
+		owner.MakeRequest("GET", "/me", nil, &me)
+
+		var created domain.APIKeyCreatedResponseDTO
+		response := owner.MakeRequest("POST", "/admin/api-keys", &domain.APIKeyCreateDTO{
+			Name:   "Reporting",
+			Scopes: []domain.Permission{domain.PermissionMembersRead, domain.PermissionAuditRead},
+		}, &created)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected creating an API key to be 201 Created, but was %s", response.Status)
+		}
+		key := TestRestClient{t: t, serverUrl: server.URL, token: created.Key}
+		response = key.MakeRequest("GET", "/audit", nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected the key to be able to read the audit log, but was %s", response.Status)
+		}
+
+		response = admin.MakeRequest("PUT", fmt.Sprintf("/users/%d/roles", me.User.Id), &domain.UserRolesDTO{
+			Roles: []domain.Role{domain.RoleWelcomeTeam},
+		}, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected demoting the key's creator to be 200 OK, but was %s", response.Status)
+		}
+		response = key.MakeRequest("GET", "/audit", nil, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected the key to lose the audit log with its creator, but was %s", response.Status)
+		}
+		response = key.MakeRequest("GET", "/members", nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected the key to keep reading members, which its creator still may, but was %s", response.Status)
+		}
+	})
+}
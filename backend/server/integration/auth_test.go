@@ -0,0 +1,182 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestAuth(t *testing.T) {
+	RunOnTestBackends(t, testAuth)
+}
+
+func testAuth(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	server := StartTestServer(t, stores, DefaultServerConfig(backend))
+	token := LoginTestUser(t, stores, server.URL, "auth.tester", domain.RoleAdmin)
+
+	t.Run("Requests without a valid token are rejected", func(t *testing.T) {
+		for _, token := range []string{"", "not a real token"} {
+			client := TestRestClient{
+				t:         t,
+				serverUrl: server.URL,
+				token:     token,
+			}
+
+			for _, path := range []string{"/members", "/members.vcf", "/audit", "/users"} {
+				response := client.MakeRequest("GET", path, nil, nil)
+				if response.StatusCode != http.StatusUnauthorized {
+					t.Errorf("expected GET %s with token %q to be 401 Unauthorized but was %s", path, token, response.Status)
+				}
+			}
+		}
+	})
+
+	t.Run("Create user, log in and log out", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		createDto := domain.UserCreateDTO{Username: "Ambrose", Password: "te deum laudamus", Roles: []domain.Role{domain.RolePastor}}
+		var user domain.UserResponseDTO
+		response := client.MakeRequest("POST", "/users", &createDto, &user)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected POST /users to be 201 Created but was %s", response.Status)
+		}
+		if user.Username != "ambrose" {
+			t.Errorf("expected the username to be stored in lowercase, got %s", user.Username)
+		}
+
+		response = client.MakeRequest("POST", "/users", &createDto, nil)
+		if response.StatusCode != http.StatusConflict {
+			t.Errorf("expected POST /users with a taken username to be 409 Conflict but was %s", response.Status)
+		}
+
+		response = client.MakeRequest("POST", "/users", &domain.UserCreateDTO{Username: "short", Password: "short"}, nil)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected POST /users with a short password to be 400 Bad Request but was %s", response.Status)
+		}
+
+		anonymous := TestRestClient{t: t, serverUrl: server.URL}
+		response = anonymous.MakeRequest("POST", "/auth/login", &domain.LoginDTO{Username: "ambrose", Password: "te deum laudamu"}, nil)
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected login with the wrong password to be 401 Unauthorized but was %s", response.Status)
+		}
+		response = anonymous.MakeRequest("POST", "/auth/login", &domain.LoginDTO{Username: "augustine", Password: "te deum laudamus"}, nil)
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected login as an unknown user to be 401 Unauthorized but was %s", response.Status)
+		}
+
+		var login domain.LoginResponseDTO
+		response = anonymous.MakeRequest("POST", "/auth/login", &domain.LoginDTO{Username: "AMBROSE", Password: "te deum laudamus"}, &login)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected login to be 200 OK but was %s", response.Status)
+		}
+		if login.User.Id != user.Id || !login.ExpiresAt.After(time.Now()) {
+			t.Errorf("expected a session for user %d which has not expired, got %v", user.Id, login)
+		}
+
+		session := TestRestClient{t: t, serverUrl: server.URL, token: login.Token}
+		var created domain.MemberResponseDTO
+		response = session.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Monica")}, &created)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected POST /members as the new user to be 201 Created but was %s", response.Status)
+		}
+
+		var history []domain.AuditEntryResponseDTO
+		session.MakeRequest("GET", fmt.Sprintf("/members/%d/history", created.Id), nil, &history)
+		if len(history) != 1 || history[0].Actor != "ambrose" {
+			t.Errorf("expected the creation to be audited against ambrose, got %v", history)
+		}
+
+		response = session.MakeRequest("POST", "/auth/logout", nil, nil)
+		if response.StatusCode != http.StatusNoContent {
+			t.Errorf("expected logout to be 204 No Content but was %s", response.Status)
+		}
+		response = session.MakeRequest("GET", "/members", nil, nil)
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected requests after logout to be 401 Unauthorized but was %s", response.Status)
+		}
+	})
+
+	t.Run("Roles limit access and redact notes", func(t *testing.T) {
+		admin := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+		welcome := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     LoginTestUser(t, stores, server.URL, "welcome.team", domain.RoleWelcomeTeam),
+		}
+		roster := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     LoginTestUser(t, stores, server.URL, "roster.coordinator", domain.RoleRosterCoordinator),
+		}
+
+		var created domain.MemberResponseDTO
+		admin.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{
+			FirstName: util.NewPtr("Gregory"),
+			Notes:     "Pastoral notes",
+		}, &created)
+		path := fmt.Sprintf("/members/%d", created.Id)
+
+		var seen domain.MemberResponseDTO
+		response := welcome.MakeRequest("GET", path, nil, &seen)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected welcome team to be able to read members, but was %s", response.Status)
+		}
+		if seen.Notes != "" || !seen.NotesRedacted {
+			t.Errorf("expected notes to be redacted for the welcome team, got %v", seen)
+		}
+
+		var history []domain.AuditEntryResponseDTO
+		welcome.MakeRequest("GET", path+"/history", nil, &history)
+		for _, entry := range history {
+			if _, ok := entry.After["notes"]; ok {
+				t.Errorf("expected notes to be left out of the history for the welcome team, got %v", entry)
+			}
+		}
+
+		response = welcome.MakeRequest("PUT", path, &domain.MemberUpdateDTO{FirstName: util.NewPtr("Gregory"), LastName: util.NewPtr("Magnus")}, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected welcome team to be able to update members, but was %s", response.Status)
+		}
+		admin.MakeRequest("GET", path, nil, &seen)
+		if seen.Notes != "Pastoral notes" || seen.LastName == nil || *seen.LastName != "Magnus" {
+			t.Errorf("expected an update by the welcome team to keep the notes, got %v", seen)
+		}
+
+		response = welcome.MakeRequest("GET", "/members/export?columns=firstName,notes", nil, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected the welcome team exporting notes to be 403 Forbidden, but was %s", response.Status)
+		}
+
+		for _, request := range []struct {
+			client *TestRestClient
+			method string
+			path   string
+		}{
+			{&welcome, "GET", "/audit"},
+			{&welcome, "GET", "/users"},
+			{&welcome, "POST", "/schedules"},
+			{&roster, "PUT", path},
+			{&roster, "DELETE", path},
+		} {
+			response := request.client.MakeRequest(request.method, request.path, nil, nil)
+			if response.StatusCode != http.StatusForbidden {
+				t.Errorf("expected %s %s to be 403 Forbidden, but was %s", request.method, request.path, response.Status)
+			}
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/backup"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestBackup(t *testing.T) {
+	RunOnTestBackends(t, testBackup)
+}
+
+func testBackup(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	// something to back up, should this be the only test run
+	tenant := EnsureTestTenant(t, stores, "default")
+	_, err := stores.Members.ForTenant(tenant.Id()).Create(context.Background(), "test", &domain.MemberUpdateDTO{
+		FirstName: util.NewPtr("Jerome"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create a member to back up: %v", err)
+	}
+
+	t.Run("A backup restores into an empty database", func(t *testing.T) {
+		database := backup.Database(backend.ConnectionString)
+		archive := bytes.Buffer{}
+		manifest, err := backup.Write(context.Background(), &archive, stores, database)
+		if err != nil {
+			t.Fatalf("failed to back up: %v", err)
+		}
+		if manifest.Rows() == 0 {
+			t.Fatal("expected the backup to have rows")
+		}
+		reader, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+		if err != nil {
+			t.Fatalf("failed to read the archive: %v", err)
+		}
+
+		empty := CreateEmptyTestBackend(t, backend)
+		restoredStores := OpenTestStores(t, empty, 0)
+		defer restoredStores.Close()
+		if _, err = backup.Restore(context.Background(), reader, restoredStores, database); err != nil {
+			t.Fatalf("failed to restore: %v", err)
+		}
+		if _, err = backup.Restore(context.Background(), reader, restoredStores, database); !errors.Is(err, store.ErrNotEmpty) {
+			t.Errorf("expected restoring twice to be refused, got %v", err)
+		}
+
+		// backing up what was restored gives the same rows again
+		again, err := backup.Write(context.Background(), io.Discard, restoredStores, database)
+		if err != nil {
+			t.Fatalf("failed to back up the restored database: %v", err)
+		}
+		for i, table := range manifest.Tables {
+			if again.Tables[i].SHA256 != table.SHA256 || again.Tables[i].Rows != table.Rows {
+				t.Errorf("expected the restored rows of %s to be those backed up", table.Name)
+			}
+		}
+
+		// rows created after the restore do not take the ids of restored ones
+		tenant, err := restoredStores.Tenants.FindBySlug(context.Background(), "default")
+		if err != nil || tenant == nil {
+			t.Fatalf("expected the default tenant to be restored, got %v", err)
+		}
+		_, err = restoredStores.Members.ForTenant(tenant.Id()).Create(context.Background(), "test", &domain.MemberUpdateDTO{
+			FirstName: util.NewPtr("Priscilla"),
+		})
+		if err != nil {
+			t.Errorf("failed to create a member after the restore: %v", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestCampuses(t *testing.T) {
+	RunOnTestBackends(t, testCampuses)
+}
+
+func testCampuses(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	server := StartTestServer(t, stores, DefaultServerConfig(backend))
+	token := LoginTestUser(t, stores, server.URL, "campus.tester", domain.RoleAdmin)
+
+	t.Run("Users limited to campuses only see their members", func(t *testing.T) {
+		admin := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+		welcome := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     LoginTestUser(t, stores, server.URL, "north.welcome", domain.RoleWelcomeTeam),
+		}
+
+		var north, south domain.CampusResponseDTO
+		response := admin.MakeRequest("POST", "/campuses", &domain.CampusUpdateDTO{Name: "North"}, &north)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected creating a campus to be 201 Created, but was %s", response.Status)
+		}
+		admin.MakeRequest("POST", "/campuses", &domain.CampusUpdateDTO{Name: "South"}, &south)
+		response = admin.MakeRequest("POST", "/campuses", &domain.CampusUpdateDTO{Name: "North"}, nil)
+		if response.StatusCode != http.StatusConflict {
+			t.Errorf("expected a second campus of the same name to be 409 Conflict, but was %s", response.Status)
+		}
+
+		var athanasius, basil domain.MemberResponseDTO
+		admin.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Athanasius"), CampusId: &north.Id}, &athanasius)
+		admin.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Basil"), CampusId: &south.Id}, &basil)
+		if athanasius.CampusId == nil || *athanasius.CampusId != north.Id {
+			t.Errorf("expected the member to be at the north campus, got %v", athanasius)
+		}
+
+		response = admin.MakeRequest("PUT", fmt.Sprintf("/members/%d", basil.Id), &domain.MemberUpdateDTO{
+			FirstName: util.NewPtr("Basil"),
+			CampusId:  util.NewPtr(uint64(999999)),
+		}, nil)
+		if response.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("expected moving a member to an unknown campus to be 422 Unprocessable Entity, but was %s", response.Status)
+		}
+
+		var users []domain.UserResponseDTO
+		admin.MakeRequest("GET", "/users", nil, &users)
+		var welcomeId uint64
+		for _, user := range users {
+			if user.Username == "north.welcome" {
+				welcomeId = user.Id
+			}
+		}
+		campusesPath := fmt.Sprintf("/users/%d/campuses", welcomeId)
+		response = admin.MakeRequest("PUT", campusesPath, &domain.UserCampusesDTO{CampusIds: []uint64{999999}}, nil)
+		if response.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("expected limiting a user to an unknown campus to be 422 Unprocessable Entity, but was %s", response.Status)
+		}
+		var limited domain.UserResponseDTO
+		response = admin.MakeRequest("PUT", campusesPath, &domain.UserCampusesDTO{CampusIds: []uint64{north.Id}}, &limited)
+		if response.StatusCode != http.StatusOK || len(limited.CampusIds) != 1 {
+			t.Fatalf("expected limiting a user to a campus to be 200 OK, but was %s with %v", response.Status, limited)
+		}
+
+		var members []domain.MemberResponseDTO
+		welcome.MakeRequest("GET", "/members?pageSize=500", nil, &members)
+		foundAthanasius := false
+		for _, member := range members {
+			if member.CampusId == nil || *member.CampusId != north.Id {
+				t.Errorf("expected only members of the north campus, got %v", member)
+			}
+			foundAthanasius = foundAthanasius || member.Id == athanasius.Id
+		}
+		if !foundAthanasius {
+			t.Errorf("expected the north campus's member to be listed, got %v", members)
+		}
+
+		admin.MakeRequest("GET", fmt.Sprintf("/members?pageSize=500&campusId=%d", south.Id), nil, &members)
+		if len(members) != 1 || members[0].Id != basil.Id {
+			t.Errorf("expected filtering by the south campus to give only its member, got %v", members)
+		}
+
+		basilPath := fmt.Sprintf("/members/%d", basil.Id)
+		for _, request := range []struct {
+			method string
+			path   string
+			body   any
+			status int
+		}{
+			{"GET", fmt.Sprintf("/members?campusId=%d", south.Id), nil, http.StatusForbidden},
+			{"GET", basilPath, nil, http.StatusNotFound},
+			{"PUT", basilPath, &domain.MemberUpdateDTO{FirstName: util.NewPtr("Basil"), CampusId: &north.Id}, http.StatusNotFound},
+			{"DELETE", basilPath, nil, http.StatusNotFound},
+			{"POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Cyril"), CampusId: &south.Id}, http.StatusForbidden},
+			{"POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Cyril")}, http.StatusForbidden},
+			{"POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Cyril"), CampusId: &north.Id}, http.StatusCreated},
+			{"GET", "/members/duplicates", nil, http.StatusForbidden},
+			{"POST", "/campuses", &domain.CampusUpdateDTO{Name: "East"}, http.StatusForbidden},
+		} {
+			response := welcome.MakeRequest(request.method, request.path, request.body, nil)
+			if response.StatusCode != request.status {
+				t.Errorf("expected %s %s by a user limited to the north campus to be %d, but was %s",
+					request.method, request.path, request.status, response.Status)
+			}
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+func TestHealth(t *testing.T) {
+	RunOnTestBackends(t, testHealth)
+}
+
+func testHealth(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	server := StartTestServer(t, stores, DefaultServerConfig(backend))
+	token := LoginTestUser(t, stores, server.URL, "health.tester", domain.RoleAdmin)
+
+	// a server expecting a newer schema than the database has
+	ahead := DefaultServerConfig(backend)
+	ahead.Health.MigrationVersion = backend.MigrationVersion + 1
+	aheadServer := StartTestServer(t, stores, ahead)
+
+	t.Run("Health checks and migration status", func(t *testing.T) {
+		anonymous := TestRestClient{t: t, serverUrl: server.URL}
+
+		for _, path := range []string{"/healthz", "/readyz"} {
+			response := anonymous.MakeRequest("GET", path, nil, nil)
+			if response.StatusCode != http.StatusOK {
+				t.Errorf("expected GET %s to be 200 OK without a login, but was %s", path, response.Status)
+			}
+		}
+
+		// a server expecting a newer schema than the database has is not ready
+		ahead := TestRestClient{t: t, serverUrl: aheadServer.URL}
+		response := ahead.MakeRequest("GET", "/readyz", nil, nil)
+		if response.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected a server ahead of the database not to be ready, but was %s", response.Status)
+		}
+
+		admin := TestRestClient{t: t, serverUrl: server.URL, token: token}
+		var status domain.MigrationStatusResponseDTO
+		response = admin.MakeRequest("GET", "/admin/migrations", nil, &status)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected GET /admin/migrations to be 200 OK, but was %s", response.Status)
+		}
+		if status.Version != backend.MigrationVersion || status.Dirty || status.ExpectedVersion != backend.MigrationVersion {
+			t.Errorf("expected the database at version %d and clean, got %+v", backend.MigrationVersion, status)
+		}
+
+		welcome := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     LoginTestUser(t, stores, server.URL, "health.welcome", domain.RoleWelcomeTeam),
+		}
+		response = welcome.MakeRequest("GET", "/admin/migrations", nil, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected the migration status to be 403 Forbidden to the welcome team, but was %s", response.Status)
+		}
+	})
+
+	t.Run("The schema has every table and column the stores use", func(t *testing.T) {
+		if err := store.CheckSchema(context.Background(), stores.Health); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("store.Schema lists the migrated database's tables and columns", func(t *testing.T) {
+		// read from information_schema, or from pragma_table_info on SQLite
+		columns, err := stores.Health.Columns(context.Background())
+		if err != nil {
+			t.Fatalf("could not read the schema: %v", err)
+		}
+
+		listed := make(map[string][]string)
+		for _, table := range store.Schema {
+			listed[table.Name] = table.Columns
+		}
+		for table, migrated := range columns {
+			if slices.Contains(bookkeepingTables, table) {
+				continue
+			}
+			if _, ok := listed[table]; !ok {
+				t.Errorf("the migrations create the table %s, which is not in store.Schema", table)
+				continue
+			}
+			for _, column := range migrated {
+				if !slices.Contains(listed[table], column) {
+					t.Errorf("the migrations create the column %s.%s, which is not in store.Schema", table, column)
+				}
+			}
+			for _, column := range listed[table] {
+				if !slices.Contains(migrated, column) {
+					t.Errorf("store.Schema lists the column %s.%s, which the migrations do not create", table, column)
+				}
+			}
+		}
+		for table := range listed {
+			if _, ok := columns[table]; !ok {
+				t.Errorf("store.Schema lists the table %s, which the migrations do not create", table)
+			}
+		}
+	})
+}
+
+// The tables of the migrations and the database themselves.
+var bookkeepingTables = []string{"schema_migrations", "sqlite_sequence"}
@@ -6,47 +6,213 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
 	"github.com/carsonalh/churchmanagerbackend/server/migration"
-	"github.com/carsonalh/churchmanagerbackend/server/util"
+	"github.com/carsonalh/churchmanagerbackend/server/server"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/sqlite"
 	"github.com/docker/go-connections/nat"
+	"github.com/jackc/pgx/v5"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
-var TestConnectionString *string = nil
+// A database the tests are run against, already migrated.
+type TestBackend struct {
+	Name             string
+	ConnectionString string
+	// The version the database was migrated to
+	MigrationVersion uint
+}
+
+// The databases the tests are run against, named by the comma-separated
+// CHURCHMANAGER_TEST_BACKENDS, or every backend if it is not set.
+var TestBackends []TestBackend
 
 func TestMain(m *testing.M) {
-	container, err := CreateTestContainer()
-	if err != nil {
-		panic(err)
+	names := "postgres,sqlite"
+	if value, ok := os.LookupEnv("CHURCHMANAGER_TEST_BACKENDS"); ok {
+		names = value
 	}
 
-	err = migration.PerformMigration("../../migrations", container.connectionString)
-	if err != nil {
-		panic(fmt.Errorf("database migration error: %v", err))
-	}
+	var container *TestPostgresContainer
+	for _, name := range strings.Split(names, ",") {
+		backend := TestBackend{Name: strings.TrimSpace(name)}
+		switch backend.Name {
+		case "postgres":
+			var err error
+			container, err = CreateTestContainer()
+			if err != nil {
+				panic(err)
+			}
+			backend.ConnectionString = container.connectionString
+		case "sqlite":
+			directory, err := os.MkdirTemp("", "churchmanager-test-")
+			if err != nil {
+				panic(fmt.Errorf("could not create directory for the SQLite database: %v", err))
+			}
+			defer os.RemoveAll(directory)
+			backend.ConnectionString = sqlite.Scheme + "://" + filepath.Join(directory, "churchmanager.db")
+		default:
+			panic(fmt.Errorf("unknown test backend %q", backend.Name))
+		}
 
-	TestConnectionString = util.NewPtr(container.connectionString)
+		migrationStatus, err := migration.PerformMigration("../../migrations", backend.ConnectionString)
+		if err != nil {
+			panic(fmt.Errorf("%s database migration error: %v", backend.Name, err))
+		}
+		backend.MigrationVersion = migrationStatus.Version
+
+		TestBackends = append(TestBackends, backend)
+	}
 
 	code := m.Run()
 
-	err = container.logs.Close()
+	if container != nil {
+		err := container.logs.Close()
+		if err != nil {
+			fmt.Printf("failure to close logs from container: %v\n", err)
+			code = 1
+		}
+
+		err = container.container.Stop(context.Background(), nil)
+		if err != nil {
+			fmt.Printf("failure to stop container: %v\n", err)
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
+
+// Runs the test against each of the test backends in turn.
+func RunOnTestBackends(t *testing.T, test func(t *testing.T, backend TestBackend)) {
+	if len(TestBackends) == 0 {
+		t.Fatal("there are no test backends; cannot proceed")
+	}
+	for _, backend := range TestBackends {
+		t.Run(backend.Name, func(t *testing.T) {
+			test(t, backend)
+		})
+	}
+}
+
+// The configuration of the servers the tests are run against, unless a test
+// needs something else configured.
+func DefaultServerConfig(backend TestBackend) server.ServerConfig {
+	return server.ServerConfig{
+		Tenants: controller.TenantConfig{DefaultTenant: "default"},
+		Members: controller.MemberControllerConfig{
+			DefaultPageSize: 50,
+			MaxPageSize:     500,
+		},
+		Audit: controller.AuditControllerConfig{
+			DefaultPageSize: 50,
+			MaxPageSize:     500,
+		},
+		Auth:   controller.AuthControllerConfig{SessionDuration: time.Hour},
+		Health: controller.HealthControllerConfig{MigrationVersion: backend.MigrationVersion},
+	}
+}
+
+// Starts a server over the stores, which is closed once the test finishes.
+func StartTestServer(t *testing.T, stores *store.Stores, config server.ServerConfig) *httptest.Server {
+	testServer := httptest.NewServer(server.CreateServer(stores, config))
+	t.Cleanup(testServer.Close)
+	return testServer
+}
+
+// Connects to the backend's database, cancelling each query once it has run
+// for the query timeout unless the timeout is 0.
+func OpenTestStores(t *testing.T, backend TestBackend, queryTimeout time.Duration) *store.Stores {
+	if sqlite.IsConnectionString(backend.ConnectionString) {
+		stores, err := sqlite.Open(context.Background(), backend.ConnectionString, queryTimeout)
+		if err != nil {
+			t.Fatalf("could not open the SQLite database: %v", err)
+		}
+		return stores
+	}
+	pool, err := store.CreatePool(context.Background(), backend.ConnectionString, queryTimeout)
 	if err != nil {
-		fmt.Printf("failure to close logs from container: %v\n", err)
-		code = 1
+		t.Fatalf("could not connect to the database: %v", err)
+	}
+	return store.CreateStores(pool)
+}
+
+// Creates a database of the same kind as the backend's, which has been
+// migrated and has nothing else in it.
+func CreateEmptyTestBackend(t *testing.T, backend TestBackend) TestBackend {
+	empty := TestBackend{Name: backend.Name}
+	if sqlite.IsConnectionString(backend.ConnectionString) {
+		empty.ConnectionString = sqlite.Scheme + "://" + filepath.Join(t.TempDir(), "churchmanager.db")
+	} else {
+		conn, err := pgx.Connect(context.Background(), backend.ConnectionString)
+		if err != nil {
+			t.Fatalf("could not connect to the database: %v", err)
+		}
+		defer conn.Close(context.Background())
+		name := fmt.Sprintf("churchmanager_%d", time.Now().UnixNano())
+		if _, err = conn.Exec(context.Background(), "CREATE DATABASE "+name+";"); err != nil {
+			t.Fatalf("could not create a database: %v", err)
+		}
+		empty.ConnectionString = backend.ConnectionString[:strings.LastIndex(backend.ConnectionString, "/")+1] + name
 	}
 
-	err = container.container.Stop(context.Background(), nil)
+	migrationStatus, err := migration.PerformMigration("../../migrations", empty.ConnectionString)
 	if err != nil {
-		fmt.Printf("failure to stop container: %v\n", err)
-		code = 1
+		t.Fatalf("%s database migration error: %v", empty.Name, err)
 	}
+	empty.MigrationVersion = migrationStatus.Version
+	return empty
+}
 
-	os.Exit(code)
+type TestRestClient struct {
+	t         *testing.T
+	serverUrl string
+	// Sent as a bearer token when not empty
+	token string
+	// Sent as the X-Tenant header when not empty
+	tenant string
+}
+
+// Gives the tenant with the slug, creating it if it does not exist yet.
+func EnsureTestTenant(t *testing.T, stores *store.Stores, slug string) *domain.Tenant {
+	tenant, err := stores.Tenants.Ensure(context.Background(), slug, slug)
+	if err != nil {
+		t.Fatalf("could not create test tenant: %v", err)
+	}
+	return tenant
+}
+
+// Creates a user directly in the database and logs in as it, giving the
+// session token.
+func LoginTestUser(t *testing.T, stores *store.Stores, serverUrl string, username string, roles ...domain.Role) string {
+	createDto := domain.UserCreateDTO{Username: username, Password: "test user password", Roles: roles}
+	userStore := stores.Users.ForTenant(EnsureTestTenant(t, stores, "default").Id())
+	if _, err := controller.CreateUser(context.Background(), userStore, domain.AuditActorSystem, &createDto); err != nil {
+		t.Fatalf("could not create test user: %v", err)
+	}
+
+	client := TestRestClient{t: t, serverUrl: serverUrl}
+	var loginResponse domain.LoginResponseDTO
+	response := client.MakeRequest("POST", "/auth/login", &domain.LoginDTO{
+		Username: createDto.Username,
+		Password: createDto.Password,
+	}, &loginResponse)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected test user login to be 200 OK but was %s", response.Status)
+	}
+	return loginResponse.Token
 }
 
 func (c *TestRestClient) MakeRequest(method string, url string, body any, responseBody any) *http.Response {
@@ -66,6 +232,70 @@ func (c *TestRestClient) MakeRequest(method string, url string, body any, respon
 	}
 
 	request.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.tenant != "" {
+		request.Header.Set(controller.TenantHeader, c.tenant)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		c.t.Fatalf("%s %s : failed to send http request: %v", method, url, err)
+	}
+
+	if responseBody != nil {
+		err = json.NewDecoder(response.Body).Decode(responseBody)
+		if err != nil {
+			c.t.Fatalf("%s %s : unable to read response json into object %v", method, url, responseBody)
+		}
+	}
+
+	return response
+}
+
+// Sends a multipart/form-data request with the given fields and a single file.
+func (c *TestRestClient) MakeMultipartRequest(
+	method string,
+	url string,
+	fields map[string]string,
+	fileField string,
+	fileName string,
+	file []byte,
+	responseBody any,
+) *http.Response {
+	requestData := bytes.Buffer{}
+	writer := multipart.NewWriter(&requestData)
+
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			c.t.Fatalf("%s %s : failed to write form field %s: %v", method, url, name, err)
+		}
+	}
+
+	part, err := writer.CreateFormFile(fileField, fileName)
+	if err != nil {
+		c.t.Fatalf("%s %s : failed to create form file: %v", method, url, err)
+	}
+	if _, err = part.Write(file); err != nil {
+		c.t.Fatalf("%s %s : failed to write form file: %v", method, url, err)
+	}
+	if err = writer.Close(); err != nil {
+		c.t.Fatalf("%s %s : failed to close multipart writer: %v", method, url, err)
+	}
+
+	request, err := http.NewRequest(method, c.serverUrl+url, &requestData)
+	if err != nil {
+		c.t.Fatalf("%s %s : failed to create http request: %v", method, url, err)
+	}
+
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.tenant != "" {
+		request.Header.Set(controller.TenantHeader, c.tenant)
+	}
 
 	response, err := http.DefaultClient.Do(request)
 	if err != nil {
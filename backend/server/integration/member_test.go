@@ -3,16 +3,19 @@ package integration
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/carsonalh/churchmanagerbackend/server/controller"
 	"github.com/carsonalh/churchmanagerbackend/server/domain"
-	"github.com/carsonalh/churchmanagerbackend/server/server"
+	"github.com/carsonalh/churchmanagerbackend/server/seed"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/sqlite"
 	"github.com/carsonalh/churchmanagerbackend/server/util"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func SetupTestSuite(tb testing.TB) func(testing.TB) {
@@ -20,34 +23,22 @@ func SetupTestSuite(tb testing.TB) func(testing.TB) {
 	}
 }
 
-type TestRestClient struct {
-	t         *testing.T
-	serverUrl string
+func TestMemberRest(t *testing.T) {
+	RunOnTestBackends(t, testMemberRest)
 }
 
-func TestMemberRest(t *testing.T) {
-	if TestConnectionString == nil {
-		t.Fatal("TestConnectionString is nil; cannot proceed")
-	}
-	connectionString := *TestConnectionString
-	pool, err := pgxpool.New(context.Background(), connectionString)
-	if err != nil {
-		t.Fatalf("could not connect to the database")
-	}
-	defer pool.Close()
+func testMemberRest(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
 
-	server := httptest.NewServer(server.CreateServer(pool, server.ServerConfig{
-		Members: controller.MemberControllerConfig{
-			DefaultPageSize: 50,
-			MaxPageSize:     500,
-		},
-	}))
-	defer server.Close()
+	server := StartTestServer(t, stores, DefaultServerConfig(backend))
+	token := LoginTestUser(t, stores, server.URL, "member.tester", domain.RoleAdmin)
 
 	t.Run("POST and GET again", func(t *testing.T) {
 		client := TestRestClient{
 			t:         t,
 			serverUrl: server.URL,
+			token:     token,
 		}
 
 		requestBody := domain.MemberUpdateDTO{
@@ -96,6 +87,7 @@ func TestMemberRest(t *testing.T) {
 		client := TestRestClient{
 			t:         t,
 			serverUrl: server.URL,
+			token:     token,
 		}
 
 		member := domain.MemberUpdateDTO{
@@ -139,6 +131,7 @@ func TestMemberRest(t *testing.T) {
 		client := TestRestClient{
 			t:         t,
 			serverUrl: server.URL,
+			token:     token,
 		}
 
 		member := domain.MemberUpdateDTO{
@@ -174,6 +167,7 @@ func TestMemberRest(t *testing.T) {
 		client := TestRestClient{
 			t:         t,
 			serverUrl: server.URL,
+			token:     token,
 		}
 
 		member := domain.MemberUpdateDTO{
@@ -209,6 +203,7 @@ func TestMemberRest(t *testing.T) {
 		client := TestRestClient{
 			t:         t,
 			serverUrl: server.URL,
+			token:     token,
 		}
 
 		updateDto := domain.MemberUpdateDTO{
@@ -225,6 +220,7 @@ func TestMemberRest(t *testing.T) {
 		client := TestRestClient{
 			t:         t,
 			serverUrl: server.URL,
+			token:     token,
 		}
 
 		member := domain.MemberRow{
@@ -254,6 +250,7 @@ func TestMemberRest(t *testing.T) {
 		client := TestRestClient{
 			t:         t,
 			serverUrl: server.URL,
+			token:     token,
 		}
 
 		member := domain.MemberUpdateDTO{
@@ -303,4 +300,441 @@ func TestMemberRest(t *testing.T) {
 			t.Errorf("expected to increase page count by 1, but increased it by %d", pages-prevPages)
 		}
 	})
+
+	t.Run("POST, DELETE, GET trash and restore", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		member := domain.MemberUpdateDTO{
+			FirstName: util.NewPtr("Ulrich"),
+			LastName:  util.NewPtr("Zwingli"),
+		}
+
+		var created domain.MemberResponseDTO
+		response := client.MakeRequest("POST", "/members", &member, &created)
+		location, err := response.Location()
+		if err != nil {
+			t.Fatalf("could not read Location header from response: %v", err)
+		}
+
+		response = client.MakeRequest("DELETE", location.Path, nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected DELETE to be 200 OK, but was %s", response.Status)
+		}
+
+		trash := make([]domain.MemberResponseDTO, 0)
+		response = client.MakeRequest("GET", "/members/trash", nil, &trash)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected GET /members/trash to be 200 OK, but was %s", response.Status)
+		}
+
+		var trashed *domain.MemberResponseDTO
+		for i := range trash {
+			if trash[i].Id == created.Id {
+				trashed = &trash[i]
+			}
+		}
+		if trashed == nil {
+			t.Fatal("expected to find the deleted member in the trash, but found none")
+		}
+		if trashed.DeletedAt == nil {
+			t.Error("expected the deleted member to have a deletedAt time")
+		}
+
+		response = client.MakeRequest("PUT", location.Path, &member, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected PUT of a deleted member to be 404 Not Found, but was %s", response.Status)
+		}
+
+		var restored domain.MemberResponseDTO
+		response = client.MakeRequest("POST", location.Path+"/restore", nil, &restored)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected POST restore to be 200 OK, but was %s", response.Status)
+		}
+		if restored.Id != created.Id || restored.DeletedAt != nil {
+			t.Errorf("expected restored member %d to not be deleted, got %v", created.Id, restored)
+		}
+
+		response = client.MakeRequest("GET", location.Path, nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected GET of a restored member to be 200 OK, but was %s", response.Status)
+		}
+
+		response = client.MakeRequest("POST", location.Path+"/restore", nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected restoring a member not in the trash to be 404 Not Found, but was %s", response.Status)
+		}
+	})
+
+	t.Run("purging the trash removes deleted members permanently", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		member := domain.MemberUpdateDTO{
+			FirstName: util.NewPtr("Heinrich"),
+			LastName:  util.NewPtr("Bullinger"),
+		}
+
+		response := client.MakeRequest("POST", "/members", &member, nil)
+		location, err := response.Location()
+		if err != nil {
+			t.Fatalf("could not read Location header from response: %v", err)
+		}
+		_ = client.MakeRequest("DELETE", location.Path, nil, nil)
+
+		memberStore := stores.Members.ForTenant(EnsureTestTenant(t, stores, "default").Id())
+		purged, err := memberStore.PurgeDeletedBefore(context.Background(), time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("error purging members: %v", err)
+		}
+		if purged != 0 {
+			t.Errorf("expected no members deleted over an hour ago, but purged %d", purged)
+		}
+
+		purged, err = memberStore.PurgeDeletedBefore(context.Background(), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("error purging members: %v", err)
+		}
+		if purged < 1 {
+			t.Errorf("expected to purge at least one member, but purged %d", purged)
+		}
+
+		response = client.MakeRequest("POST", location.Path+"/restore", nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected restoring a purged member to be 404 Not Found, but was %s", response.Status)
+		}
+	})
+
+	t.Run("changes to a member are recorded in its history and the audit log", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		member := domain.MemberUpdateDTO{
+			FirstName:    util.NewPtr("Katharina"),
+			LastName:     util.NewPtr("von Bora"),
+			EmailAddress: util.NewPtr("katie@wittenberg.de"),
+		}
+
+		var created domain.MemberResponseDTO
+		response := client.MakeRequest("POST", "/members", &member, &created)
+		location, err := response.Location()
+		if err != nil {
+			t.Fatalf("could not read Location header from response: %v", err)
+		}
+
+		member.EmailAddress = util.NewPtr("katharina.luther@wittenberg.de")
+		_ = client.MakeRequest("PUT", location.Path, &member, nil)
+
+		history := make([]domain.AuditEntryResponseDTO, 0)
+		response = client.MakeRequest("GET", location.Path+"/history", nil, &history)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected GET history to be 200 OK, but was %s", response.Status)
+		}
+
+		if len(history) != 2 {
+			t.Fatalf("expected 2 history entries, but got %d", len(history))
+		}
+		if history[0].Action != domain.AuditActionUpdate || history[1].Action != domain.AuditActionCreate {
+			t.Errorf("expected history to be an update then a create, but was %s then %s", history[0].Action, history[1].Action)
+		}
+		if history[0].Actor == "" {
+			t.Error("expected history entries to record an actor")
+		}
+
+		change, ok := history[0].Diff["emailAddress"]
+		if !ok {
+			t.Fatalf("expected the update to record a change of emailAddress, diff was %v", history[0].Diff)
+		}
+		if change.Before != "katie@wittenberg.de" || change.After != "katharina.luther@wittenberg.de" {
+			t.Errorf("expected emailAddress to change from the old to the new address, but got %v", change)
+		}
+		if _, ok := history[0].Diff["firstName"]; ok {
+			t.Error("expected unchanged fields to be left out of the diff")
+		}
+
+		entries := make([]domain.AuditEntryResponseDTO, 0)
+		response = client.MakeRequest("GET", fmt.Sprintf("/audit?entityType=member&entityId=%d&action=Update", created.Id), nil, &entries)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected GET /audit to be 200 OK, but was %s", response.Status)
+		}
+		if len(entries) != 1 || entries[0].EntityId != created.Id {
+			t.Errorf("expected exactly one update of member %d in the audit log, but got %v", created.Id, entries)
+		}
+	})
+
+	t.Run("GET /audit with an invalid filter gives a 400", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		response := client.MakeRequest("GET", "/audit?since=yesterday", nil, nil)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected GET /audit with an invalid since to be 400 Bad Request, but was %s", response.Status)
+		}
+	})
+
+	t.Run("POST /members/merge merges into the survivor and trashes the other", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		var survivor, merged domain.MemberResponseDTO
+		_ = client.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{
+			FirstName: util.NewPtr("William"),
+			LastName:  util.NewPtr("Tyndale"),
+			Notes:     "Translator",
+		}, &survivor)
+		_ = client.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{
+			FirstName:   util.NewPtr("Wiliam"),
+			LastName:    util.NewPtr("Tyndale"),
+			PhoneNumber: util.NewPtr("0434579344"),
+		}, &merged)
+
+		duplicates := make([]domain.MemberDuplicateResponseDTO, 0)
+		response := client.MakeRequest("GET", "/members/duplicates?minScore=0.4", nil, &duplicates)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected GET /members/duplicates to be 200 OK, but was %s", response.Status)
+		}
+		found := false
+		for _, duplicate := range duplicates {
+			found = found || (duplicate.First.Id == survivor.Id && duplicate.Second.Id == merged.Id)
+		}
+		if !found {
+			t.Errorf("expected members %d and %d to be listed as duplicates", survivor.Id, merged.Id)
+		}
+
+		var result domain.MemberResponseDTO
+		response = client.MakeRequest("POST", "/members/merge", &domain.MemberMergeDTO{
+			SurvivorId: survivor.Id,
+			MergedId:   merged.Id,
+		}, &result)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected POST /members/merge to be 200 OK, but was %s", response.Status)
+		}
+		if result.Id != survivor.Id || result.PhoneNumber == nil || *result.PhoneNumber != "0434579344" {
+			t.Errorf("expected the survivor to take the merged member's phone number, got %v", result)
+		}
+
+		response = client.MakeRequest("GET", fmt.Sprintf("/members/%d", merged.Id), nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected the merged member to be in the trash, but GET was %s", response.Status)
+		}
+
+		response = client.MakeRequest("POST", "/members/merge", &domain.MemberMergeDTO{
+			SurvivorId: survivor.Id,
+			MergedId:   merged.Id,
+		}, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected merging with a trashed member to be 404 Not Found, but was %s", response.Status)
+		}
+	})
+
+	t.Run("POST /members/import validates in a dry run and imports on commit", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		mapping := map[string]string{"mapping": `{"firstName": "Given name", "lastName": "Surname", "emailAddress": "Email"}`}
+		invalidFile := []byte("Given name,Surname,Email\n" +
+			"Martin,Bucer,bucer@strasbourg.fr\n" +
+			"Wolfgang,Capito,not an email\n")
+
+		var dryRun domain.MemberImportResponseDTO
+		response := client.MakeMultipartRequest("POST", "/members/import", mapping, "file", "members.csv", invalidFile, &dryRun)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected dry run to be 200 OK, but was %s", response.Status)
+		}
+		if !dryRun.DryRun || dryRun.Imported != 0 || len(dryRun.Rows) != 2 {
+			t.Fatalf("expected a dry run of 2 rows importing nothing, got %v", dryRun)
+		}
+		if dryRun.Rows[0].Status != domain.ImportRowValid || dryRun.Rows[1].Status != domain.ImportRowInvalid {
+			t.Errorf("expected the first row to be valid and the second invalid, got %s and %s", dryRun.Rows[0].Status, dryRun.Rows[1].Status)
+		}
+		if dryRun.Rows[1].Line != 3 || len(dryRun.Rows[1].Errors) == 0 {
+			t.Errorf("expected errors reported against line 3, got %v", dryRun.Rows[1])
+		}
+
+		response = client.MakeMultipartRequest("POST", "/members/import?mode=commit", mapping, "file", "members.csv", invalidFile, nil)
+		if response.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("expected committing an invalid file to be 422 Unprocessable Entity, but was %s", response.Status)
+		}
+
+		validFile := []byte("Given name,Surname,Email\n" +
+			"Martin,Bucer,bucer@strasbourg.fr\n" +
+			"Wolfgang,Capito,capito@strasbourg.fr\n")
+
+		var committed domain.MemberImportResponseDTO
+		response = client.MakeMultipartRequest("POST", "/members/import?mode=commit", mapping, "file", "members.csv", validFile, &committed)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected commit to be 201 Created, but was %s", response.Status)
+		}
+		if committed.Imported != 2 || committed.Rows[0].CreatedId == nil {
+			t.Fatalf("expected both rows to be imported, got %v", committed)
+		}
+
+		var member domain.MemberResponseDTO
+		response = client.MakeRequest("GET", fmt.Sprintf("/members/%d", *committed.Rows[0].CreatedId), nil, &member)
+		if response.StatusCode != http.StatusOK || *member.LastName != "Bucer" {
+			t.Errorf("expected the imported member to be found, got %s %v", response.Status, member)
+		}
+
+		var again domain.MemberImportResponseDTO
+		response = client.MakeMultipartRequest("POST", "/members/import?mode=commit", mapping, "file", "members.csv", validFile, &again)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected second commit to be 201 Created, but was %s", response.Status)
+		}
+		if again.Imported != 0 || again.Rows[0].Status != domain.ImportRowSkipped || len(again.Rows[0].Duplicates) == 0 {
+			t.Errorf("expected importing the same file again to skip every row as a duplicate, got %v", again)
+		}
+	})
+
+	t.Run("GET /members/export streams the selected columns", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		var created domain.MemberResponseDTO
+		_ = client.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{
+			FirstName: util.NewPtr("Peter"),
+			LastName:  util.NewPtr("Waldo"),
+		}, &created)
+
+		response := client.MakeRequest("GET", "/members/export?format=csv&columns=id,lastName&search=waldo", nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected GET /members/export to be 200 OK, but was %s", response.Status)
+		}
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			t.Fatalf("error reading export: %v", err)
+		}
+		if !strings.HasPrefix(string(body), "id,lastName\n") || !strings.Contains(string(body), fmt.Sprintf("%d,Waldo\n", created.Id)) {
+			t.Errorf("expected the export to contain the created member, got %s", body)
+		}
+
+		response = client.MakeRequest("GET", "/members/export?format=pdf", nil, nil)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected an unknown format to be 400 Bad Request, but was %s", response.Status)
+		}
+	})
+
+	t.Run("GET a member as a vCard and import it again", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		var created domain.MemberResponseDTO
+		_ = client.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{
+			FirstName:    util.NewPtr("Menno"),
+			LastName:     util.NewPtr("Simons"),
+			EmailAddress: util.NewPtr("menno@witmarsum.nl"),
+			Address:      util.NewPtr("Witmarsum\nFriesland"),
+		}, &created)
+
+		response := client.MakeRequest("GET", fmt.Sprintf("/members/%d.vcf", created.Id), nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected GET vCard to be 200 OK, but was %s", response.Status)
+		}
+		vCard, err := io.ReadAll(response.Body)
+		if err != nil {
+			t.Fatalf("error reading vCard: %v", err)
+		}
+		if !strings.Contains(string(vCard), "N:Simons;Menno;;;\r\n") {
+			t.Errorf("expected the vCard to contain the member's name, got %s", vCard)
+		}
+
+		response = client.MakeRequest("GET", "/members.vcf?search=menno", nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected GET /members.vcf to be 200 OK, but was %s", response.Status)
+		}
+
+		var result domain.MemberImportResponseDTO
+		response = client.MakeMultipartRequest("POST", "/members/import/vcard", nil, "file", "menno.vcf", vCard, &result)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected vCard dry run to be 200 OK, but was %s", response.Status)
+		}
+		if len(result.Rows) != 1 || result.Rows[0].Status != domain.ImportRowDuplicate {
+			t.Errorf("expected the exported member's vCard to be a duplicate of it, got %v", result)
+		}
+	})
+
+	t.Run("Queries stop when cancelled or out of time", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(context.Background())
+		cancel()
+		tenantId := EnsureTestTenant(t, stores, "default").Id()
+		members := stores.Members.ForTenant(tenantId)
+		if _, err := members.GetPage(cancelled, &domain.MemberFilter{}, 10, 0); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected a query with a cancelled context to fail with context.Canceled, got %v", err)
+		}
+
+		if sqlite.IsConnectionString(backend.ConnectionString) {
+			// SQLite has no way to sleep, so the timeout is made too short
+			// for any call to finish within it
+			timed := OpenTestStores(t, backend, time.Nanosecond)
+			defer timed.Close()
+			if _, err := timed.Members.ForTenant(tenantId).GetPage(context.Background(), &domain.MemberFilter{}, 10, 0); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected a call running past the query timeout to fail with context.DeadlineExceeded, got %v", err)
+			}
+			return
+		}
+
+		timedPool, err := store.CreatePool(context.Background(), backend.ConnectionString, 50*time.Millisecond)
+		if err != nil {
+			t.Fatalf("could not connect to the database: %v", err)
+		}
+		defer timedPool.Close()
+		if _, err = timedPool.Exec(context.Background(), "SELECT pg_sleep(1);"); err == nil {
+			t.Error("expected a query running past the query timeout to be cancelled")
+		}
+		if _, err = timedPool.Exec(context.Background(), "SELECT 1;"); err != nil {
+			t.Errorf("expected a quick query to finish within the query timeout, got %v", err)
+		}
+	})
+
+	t.Run("Streaming members is not cut off by the query timeout", func(t *testing.T) {
+		tenant := EnsureTestTenant(t, stores, "streamed")
+		// more members than either backend reads with a single query
+		config := seed.Config{Seed: 2, Members: 600}
+		if _, err := seed.Seed(context.Background(), stores, tenant.Id(), &config); err != nil {
+			t.Fatalf("failed to seed: %v", err)
+		}
+
+		timed := OpenTestStores(t, backend, 200*time.Millisecond)
+		defer timed.Close()
+		streamed := 0
+		previousId := uint64(0)
+		err := timed.Members.ForTenant(tenant.Id()).Stream(context.Background(), &domain.MemberFilter{}, func(member *domain.Member) error {
+			// a slow reader takes longer than the query timeout in all
+			if streamed < 3 {
+				time.Sleep(100 * time.Millisecond)
+			}
+			if member.Id() <= previousId {
+				t.Errorf("expected members in order of id, got %d after %d", member.Id(), previousId)
+			}
+			previousId = member.Id()
+			streamed += 1
+			return nil
+		})
+		if err != nil || streamed != 600 {
+			t.Errorf("expected all 600 members to be streamed, got %d and %v", streamed, err)
+		}
+	})
 }
@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestMetrics(t *testing.T) {
+	RunOnTestBackends(t, testMetrics)
+}
+
+// Scrapes the server's metrics with the token, giving them as text.
+func scrapeMetrics(t *testing.T, serverUrl string, token string) string {
+	scraper := TestRestClient{t: t, serverUrl: serverUrl, token: token}
+	response := scraper.MakeRequest("GET", "/metrics", nil, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected GET /metrics to be 200 OK with the token, but was %s", response.Status)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("failed to read the metrics: %v", err)
+	}
+	return string(body)
+}
+
+// Gives the line of the metrics with the sample of the series, e.g.
+// churchmanager_members, or the empty string if it has none.
+func metricsSample(metrics string, series string) string {
+	for _, line := range strings.Split(metrics, "\n") {
+		if strings.HasPrefix(line, series+" ") {
+			return line
+		}
+	}
+	return ""
+}
+
+func testMetrics(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	// without a token the metrics are not served at all
+	server := StartTestServer(t, stores, DefaultServerConfig(backend))
+	token := LoginTestUser(t, stores, server.URL, "metrics.tester", domain.RoleAdmin)
+
+	scraped := DefaultServerConfig(backend)
+	scraped.Metrics.Token = "scrape token"
+	scraped.Metrics.Database.RefreshInterval = time.Hour
+	metricsServer := StartTestServer(t, stores, scraped)
+
+	labelled := scraped
+	labelled.Metrics.Database.TenantLabels = true
+	labelledServer := StartTestServer(t, stores, labelled)
+
+	t.Run("Metrics are only served with the configured token", func(t *testing.T) {
+		for _, scraper := range []TestRestClient{
+			{t: t, serverUrl: server.URL},
+			{t: t, serverUrl: server.URL, token: "scrape token"},
+		} {
+			if response := scraper.MakeRequest("GET", "/metrics", nil, nil); response.StatusCode != http.StatusNotFound {
+				t.Errorf("expected metrics to be 404 Not Found without a configured token, but was %s", response.Status)
+			}
+		}
+		for _, scraper := range []TestRestClient{
+			{t: t, serverUrl: metricsServer.URL},
+			{t: t, serverUrl: metricsServer.URL, token: "not the scrape token"},
+		} {
+			if response := scraper.MakeRequest("GET", "/metrics", nil, nil); response.StatusCode != http.StatusUnauthorized {
+				t.Errorf("expected metrics to be 401 Unauthorized without the token, but was %s", response.Status)
+			}
+		}
+	})
+
+	t.Run("Metrics are scraped by route", func(t *testing.T) {
+		admin := TestRestClient{t: t, serverUrl: metricsServer.URL, token: token}
+		if response := admin.MakeRequest("GET", "/members", nil, nil); response.StatusCode != http.StatusOK {
+			t.Fatalf("expected GET /members to be 200 OK, but was %s", response.Status)
+		}
+		admin.MakeRequest("BREW", "/members", nil, nil)
+
+		metrics := scrapeMetrics(t, metricsServer.URL, "scrape token")
+		for _, expected := range []string{
+			`churchmanager_http_requests_total{method="GET",route="/members",status="200"} `,
+			`churchmanager_http_requests_total{method="OTHER",route="unmatched",status="404"} `,
+			`churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="+Inf"} `,
+			`churchmanager_database_connections{state="acquired"} `,
+			fmt.Sprintf("churchmanager_migration_version %d\n", backend.MigrationVersion),
+			"churchmanager_members ",
+			"churchmanager_schedules ",
+		} {
+			if !strings.Contains(metrics, expected) {
+				t.Errorf("expected the metrics to have %q, got:\n%s", expected, metrics)
+			}
+		}
+		if strings.Contains(metrics, `tenant="`) {
+			t.Errorf("expected churches not to be labelled unless configured, got:\n%s", metrics)
+		}
+	})
+
+	t.Run("Members are counted at most once each refresh interval", func(t *testing.T) {
+		before := metricsSample(scrapeMetrics(t, metricsServer.URL, "scrape token"), "churchmanager_members")
+		if before == "" {
+			t.Fatal("expected the members to be counted")
+		}
+
+		admin := TestRestClient{t: t, serverUrl: metricsServer.URL, token: token}
+		if response := admin.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Hilary")}, nil); response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected POST /members to be 201 Created, but was %s", response.Status)
+		}
+
+		after := metricsSample(scrapeMetrics(t, metricsServer.URL, "scrape token"), "churchmanager_members")
+		if after != before {
+			t.Errorf("expected the count from the last refresh until the interval has passed, got %q then %q", before, after)
+		}
+	})
+
+	t.Run("Churches are labelled when configured", func(t *testing.T) {
+		metrics := scrapeMetrics(t, labelledServer.URL, "scrape token")
+		for _, expected := range []string{
+			`churchmanager_members{tenant="default"} `,
+			`churchmanager_schedules{tenant="default"} `,
+		} {
+			if !strings.Contains(metrics, expected) {
+				t.Errorf("expected the metrics to have %q, got:\n%s", expected, metrics)
+			}
+		}
+	})
+}
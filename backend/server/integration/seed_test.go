@@ -0,0 +1,33 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/seed"
+)
+
+func TestSeed(t *testing.T) {
+	RunOnTestBackends(t, testSeed)
+}
+
+func testSeed(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	t.Run("A church can be seeded with made up members", func(t *testing.T) {
+		tenant := EnsureTestTenant(t, stores, "seeded")
+		config := seed.Config{Seed: 1, Campuses: 2, Members: 250, Schedules: 5}
+		result, err := seed.Seed(context.Background(), stores, tenant.Id(), &config)
+		if err != nil {
+			t.Fatalf("failed to seed: %v", err)
+		}
+		members, err := stores.Members.ForTenant(tenant.Id()).GetAll(context.Background())
+		if err != nil || uint(len(members)) != result.Members || result.Members != 250 {
+			t.Errorf("expected 250 seeded members, got %d stored of %d, %v", len(members), result.Members, err)
+		}
+		if _, err = seed.Seed(context.Background(), stores, tenant.Id(), &config); err == nil {
+			t.Error("expected seeding the church again to be refused")
+		}
+	})
+}
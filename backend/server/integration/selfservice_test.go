@@ -0,0 +1,198 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestSelfService(t *testing.T) {
+	RunOnTestBackends(t, testSelfService)
+}
+
+func testSelfService(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	config := DefaultServerConfig(backend)
+	server := StartTestServer(t, stores, config)
+	token := LoginTestUser(t, stores, server.URL, "selfservice.tester", domain.RoleAdmin)
+
+	// a second server over the same database, for when changes wait for approval
+	config.SelfService.RequireApproval = true
+	approvalServer := StartTestServer(t, stores, config)
+
+	t.Run("Members update their own details through /me", func(t *testing.T) {
+		admin := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		var member domain.MemberResponseDTO
+		admin.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{
+			FirstName:    util.NewPtr("Monica"),
+			EmailAddress: util.NewPtr("monica@tagaste.org"),
+			PhoneNumber:  util.NewPtr("0400000000"),
+			Notes:        "Prays for her son",
+		}, &member)
+
+		var user domain.UserResponseDTO
+		response := admin.MakeRequest("POST", "/users", &domain.UserCreateDTO{
+			Username: "monica",
+			Password: "test user password",
+			MemberId: &member.Id,
+		}, &user)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected creating a member's user to be 201 Created, but was %s", response.Status)
+		}
+		if user.MemberId == nil || *user.MemberId != member.Id {
+			t.Errorf("expected the user to be linked to member %d, got %v", member.Id, user)
+		}
+
+		var login domain.LoginResponseDTO
+		admin.MakeRequest("POST", "/auth/login", &domain.LoginDTO{Username: "monica", Password: "test user password"}, &login)
+		monica := TestRestClient{t: t, serverUrl: server.URL, token: login.Token}
+
+		var me map[string]any
+		response = monica.MakeRequest("GET", "/me", nil, &me)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected GET /me to be 200 OK, but was %s", response.Status)
+		}
+		profile, _ := me["member"].(map[string]any)
+		if profile["firstName"] != "Monica" {
+			t.Errorf("expected the member to be shown, got %v", me)
+		}
+		if _, ok := profile["notes"]; ok {
+			t.Errorf("expected the member's notes never to be shown, got %v", profile)
+		}
+
+		var updated domain.MeResponseDTO
+		response = monica.MakeRequest("PATCH", "/me", &domain.MeUpdateDTO{PhoneNumber: util.NewPtr("0411111111")}, &updated)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected PATCH /me to be 200 OK, but was %s", response.Status)
+		}
+		admin.MakeRequest("GET", fmt.Sprintf("/members/%d", member.Id), nil, &member)
+		if member.PhoneNumber == nil || *member.PhoneNumber != "0411111111" || member.Notes != "Prays for her son" {
+			t.Errorf("expected only the phone number to change, got %v", member)
+		}
+
+		response = monica.MakeRequest("PATCH", "/me", map[string]any{"notes": "Wrote her own notes"}, nil)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected a change to nothing but notes to be 400 Bad Request, but was %s", response.Status)
+		}
+		response = monica.MakeRequest("GET", "/members", nil, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected a member's user without roles to be 403 Forbidden from /members, but was %s", response.Status)
+		}
+
+		// with approval required, changes wait for a user who may write members
+		monica.serverUrl = approvalServer.URL
+		admin.serverUrl = approvalServer.URL
+
+		response = monica.MakeRequest("PATCH", "/me", &domain.MeUpdateDTO{EmailAddress: util.NewPtr("monica@ostia.org")}, &updated)
+		if response.StatusCode != http.StatusAccepted {
+			t.Fatalf("expected a change waiting for approval to be 202 Accepted, but was %s", response.Status)
+		}
+		monica.MakeRequest("PATCH", "/me", &domain.MeUpdateDTO{PhoneNumber: util.NewPtr("")}, &updated)
+		if updated.PendingChange == nil || updated.PendingChange.EmailAddress == nil || *updated.PendingChange.EmailAddress != "monica@ostia.org" {
+			t.Fatalf("expected the changes to be combined while waiting, got %v", updated.PendingChange)
+		}
+		if updated.Member == nil || updated.Member.EmailAddress == nil || *updated.Member.EmailAddress != "monica@tagaste.org" {
+			t.Errorf("expected the member to be unchanged until approval, got %v", updated.Member)
+		}
+
+		response = monica.MakeRequest("POST", fmt.Sprintf("/member-changes/%d/approve", updated.PendingChange.Id), nil, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected a member approving their own change to be 403 Forbidden, but was %s", response.Status)
+		}
+
+		var approved domain.MemberChangeResponseDTO
+		response = admin.MakeRequest("POST", fmt.Sprintf("/member-changes/%d/approve", updated.PendingChange.Id), nil, &approved)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected approving the change to be 200 OK, but was %s", response.Status)
+		}
+		if approved.Status != domain.MemberChangeApproved || approved.DecidedBy == nil || *approved.DecidedBy != "selfservice.tester" {
+			t.Errorf("expected the change to be approved by the admin, got %v", approved)
+		}
+		admin.MakeRequest("GET", fmt.Sprintf("/members/%d", member.Id), nil, &member)
+		if member.EmailAddress == nil || *member.EmailAddress != "monica@ostia.org" || member.PhoneNumber != nil {
+			t.Errorf("expected the approved change to be made, got %v", member)
+		}
+
+		response = admin.MakeRequest("POST", fmt.Sprintf("/member-changes/%d/reject", updated.PendingChange.Id), nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected rejecting a decided change to be 404 Not Found, but was %s", response.Status)
+		}
+	})
+	t.Run("Merging a member rejects the change it is waiting on", func(t *testing.T) {
+		admin := TestRestClient{
+			t:         t,
+			serverUrl: approvalServer.URL,
+			token:     token,
+		}
+
+		// Creates a member and a user who is them, giving the user's client.
+		memberWithUser := func(firstName string, username string) (domain.MemberResponseDTO, TestRestClient) {
+			var member domain.MemberResponseDTO
+			admin.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr(firstName)}, &member)
+			response := admin.MakeRequest("POST", "/users", &domain.UserCreateDTO{
+				Username: username,
+				Password: "test user password",
+				MemberId: &member.Id,
+			}, nil)
+			if response.StatusCode != http.StatusCreated {
+				t.Fatalf("expected creating a member's user to be 201 Created, but was %s", response.Status)
+			}
+			var login domain.LoginResponseDTO
+			admin.MakeRequest("POST", "/auth/login", &domain.LoginDTO{Username: username, Password: "test user password"}, &login)
+			return member, TestRestClient{t: t, serverUrl: approvalServer.URL, token: login.Token}
+		}
+		survivor, augustine := memberWithUser("Augustine", "augustine")
+		merged, aurelius := memberWithUser("Aurelius", "aurelius")
+
+		var survivorChange, mergedChange domain.MeResponseDTO
+		augustine.MakeRequest("PATCH", "/me", &domain.MeUpdateDTO{EmailAddress: util.NewPtr("augustine@hippo.org")}, &survivorChange)
+		aurelius.MakeRequest("PATCH", "/me", &domain.MeUpdateDTO{EmailAddress: util.NewPtr("aurelius@milan.org")}, &mergedChange)
+		if survivorChange.PendingChange == nil || mergedChange.PendingChange == nil {
+			t.Fatalf("expected both changes to wait for approval, got %v and %v", survivorChange.PendingChange, mergedChange.PendingChange)
+		}
+
+		response := admin.MakeRequest("POST", "/members/merge", &domain.MemberMergeDTO{
+			SurvivorId: survivor.Id,
+			MergedId:   merged.Id,
+		}, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected POST /members/merge to be 200 OK, but was %s", response.Status)
+		}
+
+		var pending []domain.MemberChangeResponseDTO
+		admin.MakeRequest("GET", "/member-changes", nil, &pending)
+		for _, change := range pending {
+			if change.Id == mergedChange.PendingChange.Id {
+				t.Errorf("expected the merged member's change to no longer wait for approval, got %v", change)
+			}
+		}
+		response = admin.MakeRequest("POST", fmt.Sprintf("/member-changes/%d/approve", mergedChange.PendingChange.Id), nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected approving the merged member's change to be 404 Not Found, but was %s", response.Status)
+		}
+
+		var me domain.MeResponseDTO
+		aurelius.MakeRequest("GET", "/me", nil, &me)
+		if me.Member == nil || me.Member.Id != survivor.Id {
+			t.Errorf("expected the merged member's user to be the survivor, got %v", me.Member)
+		}
+		if me.PendingChange == nil || me.PendingChange.Id != survivorChange.PendingChange.Id {
+			t.Errorf("expected the survivor's change to still wait for approval, got %v", me.PendingChange)
+		}
+
+		response = admin.MakeRequest("POST", fmt.Sprintf("/member-changes/%d/approve", survivorChange.PendingChange.Id), nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected approving the survivor's change to be 200 OK, but was %s", response.Status)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/oidc"
+	"github.com/carsonalh/churchmanagerbackend/server/oidc/oidctest"
+)
+
+func TestSingleSignOn(t *testing.T) {
+	RunOnTestBackends(t, testSingleSignOn)
+}
+
+func testSingleSignOn(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	oidcProvider, err := oidctest.NewProvider("church-manager", "client secret")
+	if err != nil {
+		t.Fatalf("could not start OIDC stand-in: %v", err)
+	}
+	defer oidcProvider.Close()
+
+	config := DefaultServerConfig(backend)
+	config.Auth.OIDCProviders = []oidc.ProviderConfig{{
+		Name:         "test",
+		Issuer:       oidcProvider.Issuer(),
+		ClientId:     "church-manager",
+		ClientSecret: "client secret",
+		RedirectURL:  "http://localhost:3000/login/callback",
+		RoleClaim:    "groups",
+		RoleMapping:  map[string]domain.Role{"pastors": domain.RolePastor, "welcome": domain.RoleWelcomeTeam},
+		Tenants:      []string{"default"},
+	}}
+	server := StartTestServer(t, stores, config)
+
+	// a user for those signing on to clash with
+	LoginTestUser(t, stores, server.URL, "sso.tester", domain.RolePastor)
+
+	t.Run("Single sign-on provisions users and maps roles", func(t *testing.T) {
+		client := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+		}
+
+		// Logs in at the stand-in as the user, giving the callback's response.
+		login := func(claims map[string]any, responseBody any) *http.Response {
+			var start domain.OIDCStartResponseDTO
+			response := client.MakeRequest("POST", "/auth/oidc/test/start", nil, &start)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("expected starting a login to be 200 OK but was %s", response.Status)
+			}
+			oidcProvider.SetUser(claims)
+			code, state, err := oidcProvider.Login(start.AuthorizationURL)
+			if err != nil {
+				t.Fatalf("error logging in at the OIDC stand-in: %v", err)
+			}
+			if state != start.State {
+				t.Fatalf("expected the stand-in to pass back state %s, got %s", start.State, state)
+			}
+			return client.MakeRequest("POST", "/auth/oidc/test/callback", &domain.OIDCCallbackDTO{Code: code, State: state}, responseBody)
+		}
+
+		var first domain.LoginResponseDTO
+		response := login(map[string]any{"sub": "sso-1", "email": "Basil@Caesarea.org", "email_verified": true, "groups": []string{"welcome"}}, &first)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected the first single sign-on login to be 200 OK but was %s", response.Status)
+		}
+		if first.User.Username != "basil@caesarea.org" || len(first.User.Roles) != 1 || first.User.Roles[0] != domain.RoleWelcomeTeam {
+			t.Errorf("expected a welcome team user named after their email address, got %v", first.User)
+		}
+
+		session := TestRestClient{t: t, serverUrl: server.URL, token: first.Token}
+		response = session.MakeRequest("GET", "/members", nil, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("expected the provisioned user to be able to read members, but was %s", response.Status)
+		}
+
+		var second domain.LoginResponseDTO
+		login(map[string]any{"sub": "sso-1", "email": "basil@caesarea.org", "email_verified": true, "groups": []string{"pastors"}}, &second)
+		if second.User.Id != first.User.Id || len(second.User.Roles) != 1 || second.User.Roles[0] != domain.RolePastor {
+			t.Errorf("expected the same user with their roles updated from their groups, got %v", second.User)
+		}
+
+		response = login(map[string]any{"sub": "sso-2", "email": "nobody@caesarea.org", "groups": []string{"choir"}}, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected a user without mapped roles to be 403 Forbidden, but was %s", response.Status)
+		}
+
+		response = login(map[string]any{"sub": "sso-5", "email": "sso.tester@caesarea.org", "groups": []string{"pastors"}}, nil)
+		if response.StatusCode != http.StatusForbidden {
+			t.Errorf("expected a user named only by an unverified email address to be 403 Forbidden, but was %s", response.Status)
+		}
+
+		response = login(map[string]any{"sub": "sso-3", "preferred_username": "sso.tester", "groups": []string{"pastors"}}, nil)
+		if response.StatusCode != http.StatusConflict {
+			t.Errorf("expected a user named after an existing user to be 409 Conflict, but was %s", response.Status)
+		}
+
+		response = client.MakeRequest("POST", "/auth/oidc/test/callback", &domain.OIDCCallbackDTO{Code: "code", State: "not a started login"}, nil)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected a callback for an unknown login to be 400 Bad Request, but was %s", response.Status)
+		}
+
+		response = client.MakeRequest("POST", "/auth/oidc/unknown/start", nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected starting a login with an unknown provider to be 404 Not Found, but was %s", response.Status)
+		}
+	})
+	t.Run("Single sign-on is refused on churches the provider is not for", func(t *testing.T) {
+		tenant := EnsureTestTenant(t, stores, "elsewhere")
+		client := TestRestClient{t: t, serverUrl: server.URL}
+		elsewhere := TestRestClient{t: t, serverUrl: server.URL, tenant: "elsewhere"}
+
+		var providers []string
+		elsewhere.MakeRequest("GET", "/auth/oidc/providers", nil, &providers)
+		if len(providers) != 0 {
+			t.Errorf("expected no providers to be listed for another church, got %v", providers)
+		}
+
+		response := elsewhere.MakeRequest("POST", "/auth/oidc/test/start", nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected starting a login on another church to be 404 Not Found, but was %s", response.Status)
+		}
+
+		// a login started on the provider's church and finished on another
+		var start domain.OIDCStartResponseDTO
+		client.MakeRequest("POST", "/auth/oidc/test/start", nil, &start)
+		oidcProvider.SetUser(map[string]any{"sub": "sso-4", "preferred_username": "intruder", "groups": []string{"pastors"}})
+		code, state, err := oidcProvider.Login(start.AuthorizationURL)
+		if err != nil {
+			t.Fatalf("error logging in at the OIDC stand-in: %v", err)
+		}
+		response = elsewhere.MakeRequest("POST", "/auth/oidc/test/callback", &domain.OIDCCallbackDTO{Code: code, State: state}, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected finishing a login on another church to be 404 Not Found, but was %s", response.Status)
+		}
+
+		users, err := stores.Users.ForTenant(tenant.Id()).GetAll(context.Background())
+		if err != nil {
+			t.Fatalf("could not list users: %v", err)
+		}
+		if len(users) != 0 {
+			t.Errorf("expected no users to be created on another church, got %v", users)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/sqlite"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestTenants(t *testing.T) {
+	RunOnTestBackends(t, testTenants)
+}
+
+func testTenants(t *testing.T, backend TestBackend) {
+	stores := OpenTestStores(t, backend, 0)
+	defer stores.Close()
+
+	server := StartTestServer(t, stores, DefaultServerConfig(backend))
+	token := LoginTestUser(t, stores, server.URL, "tenant.tester", domain.RoleAdmin)
+
+	t.Run("Churches only see their own data", func(t *testing.T) {
+		admin := TestRestClient{
+			t:         t,
+			serverUrl: server.URL,
+			token:     token,
+		}
+
+		grace := EnsureTestTenant(t, stores, "grace")
+		createDto := domain.UserCreateDTO{Username: "tenant.tester", Password: "test user password", Roles: []domain.Role{domain.RoleAdmin}}
+		if _, err := controller.CreateUser(context.Background(), stores.Users.ForTenant(grace.Id()), domain.AuditActorSystem, &createDto); err != nil {
+			t.Fatalf("expected a user of the same name to be created in another church, but got %v", err)
+		}
+
+		var login domain.LoginResponseDTO
+		client := TestRestClient{t: t, serverUrl: server.URL, tenant: "grace"}
+		response := client.MakeRequest("POST", "/auth/login", &domain.LoginDTO{
+			Username: createDto.Username,
+			Password: createDto.Password,
+		}, &login)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected logging in to the second church to be 200 OK, but was %s", response.Status)
+		}
+		if !strings.HasPrefix(login.Token, "grace.") {
+			t.Errorf("expected the token to name its church, got %s", login.Token)
+		}
+
+		// the token names the church, so no header is needed
+		graceAdmin := TestRestClient{t: t, serverUrl: server.URL, token: login.Token}
+
+		var members []domain.MemberResponseDTO
+		response = graceAdmin.MakeRequest("GET", "/members", nil, &members)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected listing members of the second church to be 200 OK, but was %s", response.Status)
+		}
+		if len(members) != 0 {
+			t.Errorf("expected the second church to see none of the first church's members, got %v", members)
+		}
+
+		var created domain.MemberResponseDTO
+		graceAdmin.MakeRequest("POST", "/members", &domain.MemberUpdateDTO{FirstName: util.NewPtr("Ephrem")}, &created)
+		response = admin.MakeRequest("GET", fmt.Sprintf("/members/%d", created.Id), nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected a member of another church to be 404 Not Found, but was %s", response.Status)
+		}
+
+		var history []domain.AuditEntryResponseDTO
+		admin.MakeRequest("GET", fmt.Sprintf("/audit?entityType=member&entityId=%d", created.Id), nil, &history)
+		if len(history) != 0 {
+			t.Errorf("expected the history of another church's member to be hidden, got %v", history)
+		}
+
+		admin.tenant = "grace"
+		response = admin.MakeRequest("GET", "/members", nil, nil)
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected a token used for another church to be 401 Unauthorized, but was %s", response.Status)
+		}
+
+		admin.tenant = "unknown"
+		response = admin.MakeRequest("GET", "/members", nil, nil)
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected an unknown church to be 404 Not Found, but was %s", response.Status)
+		}
+	})
+
+	t.Run("Queries cannot change the version of the schema", func(t *testing.T) {
+		if sqlite.IsConnectionString(backend.ConnectionString) {
+			t.Skip("SQLite has no roles to limit queries with")
+		}
+
+		pool, err := store.CreatePool(context.Background(), backend.ConnectionString, 0)
+		if err != nil {
+			t.Fatalf("could not connect to the database: %v", err)
+		}
+		defer pool.Close()
+		var version int64
+		if err = pool.QueryRow(context.Background(), "SELECT version FROM schema_migrations;").Scan(&version); err != nil {
+			t.Errorf("expected the version of the schema to be readable, got %v", err)
+		}
+		if _, err = pool.Exec(context.Background(), "UPDATE schema_migrations SET dirty = true;"); err == nil {
+			t.Error("expected changing the version of the schema to be refused")
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/logging"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type MemberPurgeConfig struct {
+	// How long a deleted member stays in the trash before it is permanently
+	// removed.
+	Retention time.Duration
+	// How often the trash is checked for members to purge.
+	Interval time.Duration
+}
+
+// Permanently removes members which have been in the trash for longer than
+// the configured retention period from every tenant, checking once
+// immediately and then once every interval. Blocks until the context is
+// cancelled.
+func RunMemberPurge(ctx context.Context, tenantStore store.TenantRepository, memberStore store.MemberRepository, config MemberPurgeConfig) {
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+	ctx = withJob(ctx, "member purge")
+
+	for {
+		forEachTenant(ctx, tenantStore, func(ctx context.Context, tenant *domain.Tenant) {
+			purged, err := memberStore.ForTenant(tenant.Id()).PurgeDeletedBefore(ctx, time.Now().Add(-config.Retention))
+			if err != nil {
+				logging.FromContext(ctx).Error("error purging deleted members", "error", err)
+			} else if purged > 0 {
+				logging.FromContext(ctx).Info("purged deleted members", "members", purged, "retention", config.Retention.String())
+			}
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
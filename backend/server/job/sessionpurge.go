@@ -0,0 +1,35 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/logging"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+// Removes expired sessions of every tenant, checking once immediately and then
+// once every interval. Blocks until the context is cancelled.
+func RunSessionPurge(ctx context.Context, tenantStore store.TenantRepository, sessionStore store.SessionRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	ctx = withJob(ctx, "session purge")
+
+	for {
+		forEachTenant(ctx, tenantStore, func(ctx context.Context, tenant *domain.Tenant) {
+			purged, err := sessionStore.ForTenant(tenant.Id()).DeleteExpired(ctx)
+			if err != nil {
+				logging.FromContext(ctx).Error("error purging expired sessions", "error", err)
+			} else if purged > 0 {
+				logging.FromContext(ctx).Info("purged expired sessions", "sessions", purged)
+			}
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
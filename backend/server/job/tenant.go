@@ -0,0 +1,30 @@
+package job
+
+import (
+	"context"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/logging"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+// Runs a job for each tenant in turn, as the rows of each tenant can only be
+// seen by a store for that tenant. The job is given a context whose logger
+// logs the tenant.
+func forEachTenant(ctx context.Context, tenantStore store.TenantRepository, fn func(ctx context.Context, tenant *domain.Tenant)) {
+	tenants, err := tenantStore.GetAll(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("error getting tenants", "error", err)
+		return
+	}
+
+	for i := range tenants {
+		logger := logging.FromContext(ctx).With("tenant", tenants[i].Slug())
+		fn(logging.WithLogger(ctx, logger), &tenants[i])
+	}
+}
+
+// Gives a context whose logger logs the name of the job.
+func withJob(ctx context.Context, name string) context.Context {
+	return logging.WithLogger(ctx, logging.FromContext(ctx).With("job", name))
+}
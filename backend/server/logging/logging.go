@@ -0,0 +1,57 @@
+// Package logging writes the server's logs as JSON, one record to a line, and
+// carries the logger of each request in its context, so that everything
+// logged while serving a request, by the handlers or by the stores, has the
+// request's id. Members' personal details are never written to the logs.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+)
+
+// The keys of attributes holding members' personal details, whose values are
+// redacted wherever they are logged, as the fields of the API and as the
+// columns of the database.
+var personalKeys = []string{
+	"firstName", "lastName", "emailAddress", "phoneNumber", "address", "notes",
+	"first_name", "last_name", "email_address", "phone_number",
+}
+
+// What a redacted value is logged as.
+const Redacted = "[redacted]"
+
+// Creates a logger writing records of the level and above to w as JSON, with
+// members' personal details redacted.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}))
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() != slog.KindGroup && slices.Contains(personalKeys, attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	return attr
+}
+
+type contextKey int
+
+const loggerContextKey contextKey = iota
+
+// Gives a context whose records are logged with the logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// Gives the logger of the context, or the default logger if the context has
+// none, e.g. outside of a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
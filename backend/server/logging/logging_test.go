@@ -0,0 +1,71 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/logging"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestLoggerRedactsPersonalDetails(t *testing.T) {
+	out := bytes.Buffer{}
+	logger := logging.New(&out, slog.LevelInfo)
+
+	logger.Info("member changed",
+		"memberId", 42,
+		"emailAddress", "ambrose@example.com",
+		slog.Group("after", "first_name", "Ambrose", "campus_id", 3),
+	)
+
+	if strings.Contains(out.String(), "ambrose") || strings.Contains(out.String(), "Ambrose") {
+		t.Errorf("expected the member's details to be redacted, got %s", out.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("expected a line of JSON, got %s", out.String())
+	}
+	if record["memberId"] != float64(42) || record["emailAddress"] != logging.Redacted {
+		t.Errorf("expected only the personal details to be redacted, got %v", record)
+	}
+	if after, _ := record["after"].(map[string]any); after["campus_id"] != float64(3) || after["first_name"] != logging.Redacted {
+		t.Errorf("expected details in groups to be redacted, got %v", record["after"])
+	}
+}
+
+func TestMembersAreLoggedWithoutDetails(t *testing.T) {
+	member, err := (&domain.MemberRow{Id: 7, FirstName: util.NewPtr("Lydia"), Notes: "Sells purple cloth"}).ToMember()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := bytes.Buffer{}
+	logging.New(&out, slog.LevelInfo).Info("member deleted", "member", member)
+
+	if strings.Contains(out.String(), "Lydia") || strings.Contains(out.String(), "purple") {
+		t.Errorf("expected the member to be logged without details, got %s", out.String())
+	}
+	if !strings.Contains(out.String(), `"member":{"id":7}`) {
+		t.Errorf("expected the member to be logged by its id, got %s", out.String())
+	}
+}
+
+func TestLoggerOfContext(t *testing.T) {
+	if logging.FromContext(context.Background()) != slog.Default() {
+		t.Error("expected the default logger for a context without one")
+	}
+
+	out := bytes.Buffer{}
+	logger := logging.New(&out, slog.LevelWarn).With("requestId", "abc")
+	ctx := logging.WithLogger(context.Background(), logger)
+	logging.FromContext(ctx).Info("ignored")
+	logging.FromContext(ctx).Warn("kept")
+
+	if strings.Contains(out.String(), "ignored") || !strings.Contains(out.String(), `"requestId":"abc"`) {
+		t.Errorf("expected records at the level and above with the context's attributes, got %s", out.String())
+	}
+}
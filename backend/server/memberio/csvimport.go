@@ -0,0 +1,139 @@
+package memberio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+// Maps member fields, by their JSON names, to the headers of the CSV columns
+// they are read from.
+type CSVMapping map[string]string
+
+// The member fields which can be imported, by their JSON names.
+var ImportFields = []string{"firstName", "lastName", "emailAddress", "phoneNumber", "address", "notes"}
+
+// Creates the mapping used when none is given, which reads each field from the
+// column with a header matching the field's name, ignoring case.
+func DefaultCSVMapping(header []string) CSVMapping {
+	mapping := CSVMapping{}
+	for _, field := range ImportFields {
+		for _, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), field) {
+				mapping[field] = column
+			}
+		}
+	}
+	return mapping
+}
+
+// A member read from a row of an import file.
+type ImportRow struct {
+	// The line of the row in the file, where the header is line 1
+	Line   int
+	Member domain.MemberUpdateDTO
+}
+
+// Reads the members from a CSV file with a header row. A nil mapping uses
+// DefaultCSVMapping. Empty cells are read as absent values, and rows with no
+// values are skipped.
+func ReadCSV(r io.Reader, mapping CSVMapping) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("the file is empty, expected a header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading header row: %v", err)
+	}
+
+	if mapping == nil {
+		mapping = DefaultCSVMapping(header)
+	}
+	if len(mapping) == 0 {
+		return nil, fmt.Errorf("no columns are mapped to member fields, expected headers among %v", ImportFields)
+	}
+
+	columns := make(map[string]int)
+	mappingErrs := make([]string, 0)
+	for field, column := range mapping {
+		known := false
+		for _, f := range ImportFields {
+			known = known || f == field
+		}
+		if !known {
+			mappingErrs = append(mappingErrs, fmt.Sprintf("unknown field %q, expected one of %v", field, ImportFields))
+			continue
+		}
+		index := -1
+		for i, h := range header {
+			if h == column {
+				index = i
+			}
+		}
+		if index < 0 {
+			mappingErrs = append(mappingErrs, fmt.Sprintf("field %q is mapped to column %q which is not in the header", field, column))
+			continue
+		}
+		columns[field] = index
+	}
+	if len(mappingErrs) > 0 {
+		sort.Strings(mappingErrs)
+		return nil, fmt.Errorf("invalid column mapping: %s", strings.Join(mappingErrs, "; "))
+	}
+
+	rows := make([]ImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		cell := func(field string) *string {
+			index, ok := columns[field]
+			if !ok || index >= len(record) {
+				return nil
+			}
+			value := strings.TrimSpace(record[index])
+			if value == "" {
+				return nil
+			}
+			return &value
+		}
+
+		row := ImportRow{
+			Line: line,
+			Member: domain.MemberUpdateDTO{
+				FirstName:    cell("firstName"),
+				LastName:     cell("lastName"),
+				EmailAddress: cell("emailAddress"),
+				PhoneNumber:  cell("phoneNumber"),
+				Address:      cell("address"),
+			},
+		}
+		if notes := cell("notes"); notes != nil {
+			row.Member.Notes = *notes
+		}
+
+		empty := row.Member == domain.MemberUpdateDTO{}
+		if empty {
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,65 @@
+package memberio_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/memberio"
+)
+
+func TestReadCSV(t *testing.T) {
+	t.Run("default mapping matches headers by field name", func(t *testing.T) {
+		file := "FirstName,lastName,emailAddress,phoneNumber,notes\n" +
+			"Jan,Hus,jan@prague.cz,,Preaches in Czech\n" +
+			",,,,\n" +
+			"Jerome,,,0434579344,\n"
+
+		rows, err := memberio.ReadCSV(strings.NewReader(file), nil)
+		if err != nil {
+			t.Fatalf("error reading CSV: %v", err)
+		}
+
+		if len(rows) != 2 {
+			t.Fatalf("expected the empty row to be skipped leaving 2 rows, got %d", len(rows))
+		}
+		if rows[0].Line != 2 || rows[1].Line != 4 {
+			t.Errorf("expected rows on lines 2 and 4, got %d and %d", rows[0].Line, rows[1].Line)
+		}
+		if *rows[0].Member.FirstName != "Jan" || rows[0].Member.Notes != "Preaches in Czech" {
+			t.Errorf("first row was not read correctly: %v", rows[0].Member)
+		}
+		if rows[0].Member.PhoneNumber != nil || rows[1].Member.LastName != nil {
+			t.Error("expected empty cells to be read as absent values")
+		}
+	})
+
+	t.Run("custom mapping", func(t *testing.T) {
+		file := "Given name,Surname,Mobile\n" +
+			"John,Wycliffe,0434 579 344\n"
+
+		rows, err := memberio.ReadCSV(strings.NewReader(file), memberio.CSVMapping{
+			"firstName":   "Given name",
+			"lastName":    "Surname",
+			"phoneNumber": "Mobile",
+		})
+		if err != nil {
+			t.Fatalf("error reading CSV: %v", err)
+		}
+
+		if len(rows) != 1 || *rows[0].Member.LastName != "Wycliffe" || *rows[0].Member.PhoneNumber != "0434 579 344" {
+			t.Errorf("expected one row read through the mapping, got %v", rows)
+		}
+	})
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		file := "Given name\nJohn\n"
+
+		_, err := memberio.ReadCSV(strings.NewReader(file), memberio.CSVMapping{
+			"firstName": "First name",
+			"birthday":  "Given name",
+		})
+		if err == nil {
+			t.Fatal("expected an error for an unknown field and a missing column")
+		}
+	})
+}
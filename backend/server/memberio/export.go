@@ -0,0 +1,198 @@
+package memberio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+// A column of an export, named after the member's JSON field.
+type ExportColumn struct {
+	Name string
+	// Gives the column's value for a member, which is a uint64, a string or a
+	// *string
+	value func(member *domain.Member) any
+}
+
+var ExportColumns = []ExportColumn{
+	{"id", func(member *domain.Member) any { return member.Id() }},
+	{"firstName", func(member *domain.Member) any { return member.FirstName() }},
+	{"lastName", func(member *domain.Member) any { return member.LastName() }},
+	{"emailAddress", func(member *domain.Member) any { return member.EmailAddress() }},
+	{"phoneNumber", func(member *domain.Member) any { return member.PhoneNumber() }},
+	{"address", func(member *domain.Member) any { return member.Address() }},
+	{"notes", func(member *domain.Member) any { return member.Notes() }},
+}
+
+// Finds the export columns with the given names, in the order given. No names
+// selects every column.
+func SelectExportColumns(names []string) ([]ExportColumn, error) {
+	if len(names) == 0 {
+		return ExportColumns, nil
+	}
+
+	columns := make([]ExportColumn, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, column := range ExportColumns {
+			if column.Name == name {
+				columns = append(columns, column)
+				found = true
+			}
+		}
+		if !found {
+			known := make([]string, len(ExportColumns))
+			for i, column := range ExportColumns {
+				known[i] = column.Name
+			}
+			return nil, fmt.Errorf("unknown column %q, expected one of %v", name, known)
+		}
+	}
+	return columns, nil
+}
+
+// Gives the column's value for a member as text, with absent values as the
+// empty string.
+func (column *ExportColumn) text(member *domain.Member) string {
+	switch value := column.value(member).(type) {
+	case uint64:
+		return strconv.FormatUint(value, 10)
+	case *string:
+		if value == nil {
+			return ""
+		}
+		return *value
+	case string:
+		return value
+	}
+	return ""
+}
+
+type ExportFormat string
+
+const (
+	ExportCSV   ExportFormat = "csv"
+	ExportXLSX  ExportFormat = "xlsx"
+	ExportJSONL ExportFormat = "jsonl"
+)
+
+func (format ExportFormat) ContentType() string {
+	switch format {
+	case ExportCSV:
+		return "text/csv"
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ExportJSONL:
+		return "application/jsonl"
+	}
+	return "application/octet-stream"
+}
+
+// Writes members one at a time, so that an export never holds every member in
+// memory.
+type ExportWriter interface {
+	Write(member *domain.Member) error
+	// Finishes the export. No more members may be written.
+	Close() error
+}
+
+func NewExportWriter(w io.Writer, format ExportFormat, columns []ExportColumn) (ExportWriter, error) {
+	switch format {
+	case ExportCSV:
+		return newCSVExportWriter(w, columns)
+	case ExportXLSX:
+		return newXLSXExportWriter(w, columns)
+	case ExportJSONL:
+		return newJSONLExportWriter(w, columns), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q, expected one of %q, %q or %q", format, ExportCSV, ExportXLSX, ExportJSONL)
+}
+
+type csvExportWriter struct {
+	writer  *csv.Writer
+	columns []ExportColumn
+	record  []string
+}
+
+func newCSVExportWriter(w io.Writer, columns []ExportColumn) (*csvExportWriter, error) {
+	writer := &csvExportWriter{
+		writer:  csv.NewWriter(w),
+		columns: columns,
+		record:  make([]string, len(columns)),
+	}
+	for i, column := range columns {
+		writer.record[i] = column.Name
+	}
+	if err := writer.writer.Write(writer.record); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Whether a spreadsheet opening a CSV file would take text starting with the
+// character as a formula.
+func startsFormula(text string) bool {
+	return text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0]))
+}
+
+func (writer *csvExportWriter) Write(member *domain.Member) error {
+	for i := range writer.columns {
+		text := writer.columns[i].text(member)
+		// members may set their own names and addresses, which must not open
+		// as formulas, so such text is quoted as spreadsheets quote text
+		if startsFormula(text) {
+			text = "'" + text
+		}
+		writer.record[i] = text
+	}
+	return writer.writer.Write(writer.record)
+}
+
+func (writer *csvExportWriter) Close() error {
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+type jsonlExportWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+	columns  []ExportColumn
+}
+
+func newJSONLExportWriter(w io.Writer, columns []ExportColumn) *jsonlExportWriter {
+	buffered := bufio.NewWriter(w)
+	return &jsonlExportWriter{
+		buffered: buffered,
+		encoder:  json.NewEncoder(buffered),
+		columns:  columns,
+	}
+}
+
+func (writer *jsonlExportWriter) Write(member *domain.Member) error {
+	object := make(map[string]any, len(writer.columns))
+	for _, column := range writer.columns {
+		object[column.Name] = column.value(member)
+	}
+	return writer.encoder.Encode(object)
+}
+
+func (writer *jsonlExportWriter) Close() error {
+	return writer.buffered.Flush()
+}
+
+// Splits a comma separated list of column names, ignoring empty names.
+func ParseColumnNames(list string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,146 @@
+package memberio_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/memberio"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func exportTestMembers(t *testing.T) []domain.Member {
+	return toMembers(t, []domain.MemberRow{
+		{Id: 7, FirstName: util.NewPtr("Jacobus"), LastName: util.NewPtr("Arminius"), Notes: "Remonstrant, \"mostly\""},
+		{Id: 8, FirstName: util.NewPtr("Franciscus"), LastName: util.NewPtr("Gomarus & co")},
+	})
+}
+
+func toMembers(t *testing.T, rows []domain.MemberRow) []domain.Member {
+	members := make([]domain.Member, len(rows))
+	for i, row := range rows {
+		member, err := row.ToMember()
+		if err != nil {
+			t.Fatalf("error converting row to member: %v", err)
+		}
+		members[i] = *member
+	}
+	return members
+}
+
+func export(t *testing.T, format memberio.ExportFormat, columnNames []string) []byte {
+	return exportMembers(t, format, columnNames, exportTestMembers(t))
+}
+
+func exportMembers(t *testing.T, format memberio.ExportFormat, columnNames []string, members []domain.Member) []byte {
+	columns, err := memberio.SelectExportColumns(columnNames)
+	if err != nil {
+		t.Fatalf("error selecting columns: %v", err)
+	}
+	output := bytes.Buffer{}
+	writer, err := memberio.NewExportWriter(&output, format, columns)
+	if err != nil {
+		t.Fatalf("error creating export writer: %v", err)
+	}
+	for _, member := range members {
+		if err = writer.Write(&member); err != nil {
+			t.Fatalf("error writing member: %v", err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("error closing export writer: %v", err)
+	}
+	return output.Bytes()
+}
+
+func TestExportCSV(t *testing.T) {
+	output := string(export(t, memberio.ExportCSV, []string{"lastName", "id", "notes"}))
+	expected := "lastName,id,notes\n" +
+		"Arminius,7,\"Remonstrant, \"\"mostly\"\"\"\n" +
+		"Gomarus & co,8,\n"
+	if output != expected {
+		t.Errorf("expected CSV\n%s\nbut got\n%s", expected, output)
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(string(export(t, memberio.ExportJSONL, []string{"id", "emailAddress"}))), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per member, got %d", len(lines))
+	}
+	var object map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &object); err != nil {
+		t.Fatalf("error decoding line: %v", err)
+	}
+	if len(object) != 2 || object["id"] != 7.0 || object["emailAddress"] != nil {
+		t.Errorf("expected only the selected columns, got %v", object)
+	}
+}
+
+// Reads the worksheet of an XLSX export.
+func xlsxSheet(t *testing.T, output []byte) string {
+	archive, err := zip.NewReader(bytes.NewReader(output), int64(len(output)))
+	if err != nil {
+		t.Fatalf("export is not a zip archive: %v", err)
+	}
+
+	for _, file := range archive.File {
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			reader, err := file.Open()
+			if err != nil {
+				t.Fatalf("error opening sheet: %v", err)
+			}
+			data, _ := io.ReadAll(reader)
+			return string(data)
+		}
+	}
+	t.Fatal("export has no worksheet")
+	return ""
+}
+
+func TestExportXLSX(t *testing.T) {
+	sheet := xlsxSheet(t, export(t, memberio.ExportXLSX, nil))
+
+	for _, expected := range []string{
+		`<c r="A2"><v>7</v></c>`,
+		`<c r="C3" t="inlineStr"><is><t xml:space="preserve">Gomarus &amp; co</t></is></c>`,
+		`<row r="3">`,
+	} {
+		if !strings.Contains(sheet, expected) {
+			t.Errorf("expected sheet to contain %s, sheet was %s", expected, sheet)
+		}
+	}
+}
+
+func TestExportedFormulasAreText(t *testing.T) {
+	members := toMembers(t, []domain.MemberRow{
+		{Id: 7, FirstName: util.NewPtr(`=HYPERLINK("http://example.com","Click")`), LastName: util.NewPtr("-Smith"), Notes: "+61 400 000 000"},
+	})
+
+	output := string(exportMembers(t, memberio.ExportCSV, []string{"firstName", "lastName", "notes"}, members))
+	expected := "firstName,lastName,notes\n" +
+		"\"'=HYPERLINK(\"\"http://example.com\"\",\"\"Click\"\")\",'-Smith,'+61 400 000 000\n"
+	if output != expected {
+		t.Errorf("expected CSV\n%s\nbut got\n%s", expected, output)
+	}
+
+	sheet := xlsxSheet(t, exportMembers(t, memberio.ExportXLSX, []string{"firstName"}, members))
+	expectedCell := `<c r="A2" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;http://example.com&#34;,&#34;Click&#34;)</t></is></c>`
+	if !strings.Contains(sheet, expectedCell) || strings.Contains(sheet, "<f>") {
+		t.Errorf("expected the name as an inline string rather than a formula, sheet was %s", sheet)
+	}
+}
+
+func TestSelectExportColumns(t *testing.T) {
+	if _, err := memberio.SelectExportColumns([]string{"id", "birthday"}); err == nil {
+		t.Error("expected an error selecting an unknown column")
+	}
+	columns, err := memberio.SelectExportColumns(memberio.ParseColumnNames(" firstName, ,id"))
+	if err != nil || len(columns) != 2 || columns[0].Name != "firstName" {
+		t.Errorf("expected firstName and id columns, got %v %v", columns, err)
+	}
+}
@@ -0,0 +1,310 @@
+package memberio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+const VCardContentType = "text/vcard"
+
+// Escapes a vCard property value or structured value component.
+func escapeVCardText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "\r\n", `\n`, "\n", `\n`, ",", `\,`, ";", `\;`).Replace(text)
+}
+
+// Splits a structured value on unescaped separators, unescaping each
+// component. A separator of 0 does not split the value.
+func splitVCardValue(value string, separator rune) []string {
+	components := make([]string, 0)
+	component := strings.Builder{}
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			if r == 'n' || r == 'N' {
+				component.WriteRune('\n')
+			} else {
+				component.WriteRune(r)
+			}
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == separator:
+			components = append(components, component.String())
+			component.Reset()
+		default:
+			component.WriteRune(r)
+		}
+	}
+	return append(components, component.String())
+}
+
+func unescapeVCardText(value string) string {
+	return splitVCardValue(value, 0)[0]
+}
+
+// Writes a content line, folding it so that no line is longer than 75 octets
+// as required by RFC 6350.
+func writeVCardLine(w *bufio.Writer, line string) {
+	const maxLineLength = 75
+	for first := true; ; first = false {
+		limit := maxLineLength
+		if !first {
+			w.WriteString(" ")
+			limit -= 1
+		}
+		if len(line) <= limit {
+			w.WriteString(line + "\r\n")
+			return
+		}
+		// never split a multi-byte character
+		split := limit
+		for split > 0 && line[split]&0xC0 == 0x80 {
+			split--
+		}
+		w.WriteString(line[:split] + "\r\n")
+		line = line[split:]
+	}
+}
+
+// Writes a member as a vCard 3.0, which is the version most widely understood
+// by phones.
+func WriteVCard(w io.Writer, member *domain.Member) error {
+	buffered := bufio.NewWriter(w)
+
+	text := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return escapeVCardText(*value)
+	}
+
+	firstName, lastName := member.FirstName(), member.LastName()
+	fullName := strings.TrimSpace(strings.Join([]string{text(firstName), text(lastName)}, " "))
+
+	writeVCardLine(buffered, "BEGIN:VCARD")
+	writeVCardLine(buffered, "VERSION:3.0")
+	writeVCardLine(buffered, fmt.Sprintf("UID:urn:churchmanager:member:%d", member.Id()))
+	writeVCardLine(buffered, "N:"+text(lastName)+";"+text(firstName)+";;;")
+	writeVCardLine(buffered, "FN:"+fullName)
+	if email := member.EmailAddress(); email != nil {
+		writeVCardLine(buffered, "EMAIL;TYPE=INTERNET:"+text(email))
+	}
+	if phone := member.PhoneNumber(); phone != nil {
+		writeVCardLine(buffered, "TEL;TYPE=VOICE:"+text(phone))
+	}
+	if address := member.Address(); address != nil {
+		// the whole address is kept in the street component, as members'
+		// addresses are not stored in parts
+		writeVCardLine(buffered, "ADR:;;"+text(address)+";;;;")
+	}
+	if notes := member.Notes(); notes != "" {
+		writeVCardLine(buffered, "NOTE:"+escapeVCardText(notes))
+	}
+	writeVCardLine(buffered, "END:VCARD")
+
+	return buffered.Flush()
+}
+
+type vCardProperty struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+func (property *vCardProperty) preferred() bool {
+	if pref, ok := property.params["PREF"]; ok {
+		return len(pref) == 0 || pref[0] == "1"
+	}
+	for _, t := range property.params["TYPE"] {
+		if strings.EqualFold(t, "pref") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseVCardProperty(line string) (*vCardProperty, error) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return nil, fmt.Errorf("expected a property of the form NAME:value, got %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	name := strings.ToUpper(parts[0])
+	// drop the group of grouped properties, e.g. item1.EMAIL
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+
+	params := make(map[string][]string)
+	for _, param := range parts[1:] {
+		key, value, hasValue := strings.Cut(param, "=")
+		key = strings.ToUpper(key)
+		if !hasValue {
+			// vCard 2.1 style bare types, e.g. TEL;CELL
+			params["TYPE"] = append(params["TYPE"], key)
+			continue
+		}
+		for _, v := range strings.Split(value, ",") {
+			params[key] = append(params[key], strings.Trim(v, `"`))
+		}
+	}
+
+	return &vCardProperty{name: name, params: params, value: line[colon+1:]}, nil
+}
+
+// Reads every vCard from a file of one or more vCards of version 3.0 or 4.0.
+// The N, EMAIL, TEL, ADR and NOTE properties are read into a member, preferring
+// properties marked as preferred where there are several of one kind.
+func ReadVCards(r io.Reader) ([]ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	// unfold lines, remembering the line number each unfolded line starts on
+	lines := make([]string, 0)
+	lineNumbers := make([]int, 0)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		lineNumbers = append(lineNumbers, number)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading vCard: %v", err)
+	}
+
+	rows := make([]ImportRow, 0)
+	var current *ImportRow
+	var properties map[string][]*vCardProperty
+
+	for i, line := range lines {
+		property, err := parseVCardProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumbers[i], err)
+		}
+
+		switch {
+		case property.name == "BEGIN" && strings.EqualFold(property.value, "VCARD"):
+			if current != nil {
+				return nil, fmt.Errorf("line %d: vCard begins before the previous vCard ends", lineNumbers[i])
+			}
+			current = &ImportRow{Line: lineNumbers[i]}
+			properties = make(map[string][]*vCardProperty)
+		case property.name == "END" && strings.EqualFold(property.value, "VCARD"):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: vCard ends before it begins", lineNumbers[i])
+			}
+			if err := readVCardMember(&current.Member, properties); err != nil {
+				return nil, fmt.Errorf("vCard beginning on line %d: %v", current.Line, err)
+			}
+			rows = append(rows, *current)
+			current = nil
+		case current == nil:
+			return nil, fmt.Errorf("line %d: property %s is outside of a vCard", lineNumbers[i], property.name)
+		default:
+			properties[property.name] = append(properties[property.name], property)
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("vCard beginning on line %d does not end", current.Line)
+	}
+
+	return rows, nil
+}
+
+func readVCardMember(member *domain.MemberUpdateDTO, properties map[string][]*vCardProperty) error {
+	if version := properties["VERSION"]; len(version) > 0 && version[0].value != "3.0" && version[0].value != "4.0" {
+		return fmt.Errorf("unsupported vCard version %s, expected 3.0 or 4.0", version[0].value)
+	}
+
+	first := func(name string) *vCardProperty {
+		candidates := properties[name]
+		for _, property := range candidates {
+			if property.preferred() {
+				return property
+			}
+		}
+		if len(candidates) > 0 {
+			return candidates[0]
+		}
+		return nil
+	}
+	nonEmpty := func(value string) *string {
+		if value = strings.TrimSpace(value); value == "" {
+			return nil
+		}
+		return &value
+	}
+
+	if name := first("N"); name != nil {
+		components := splitVCardValue(name.value, ';')
+		member.LastName = nonEmpty(components[0])
+		if len(components) > 1 {
+			member.FirstName = nonEmpty(components[1])
+		}
+	} else if fullName := first("FN"); fullName != nil {
+		// vCard 4.0 does not require N, so fall back to splitting FN
+		names := strings.Fields(unescapeVCardText(fullName.value))
+		if len(names) > 0 {
+			member.FirstName = nonEmpty(names[0])
+			member.LastName = nonEmpty(strings.Join(names[1:], " "))
+		}
+	}
+
+	if email := first("EMAIL"); email != nil {
+		member.EmailAddress = nonEmpty(unescapeVCardText(email.value))
+	}
+
+	if phone := first("TEL"); phone != nil {
+		// vCard 4.0 phone numbers are usually tel: URIs
+		member.PhoneNumber = nonEmpty(strings.TrimPrefix(unescapeVCardText(phone.value), "tel:"))
+	}
+
+	if address := first("ADR"); address != nil {
+		// components are PO box, extended address, street, locality, region,
+		// postal code and country
+		components := splitVCardValue(address.value, ';')
+		for len(components) < 7 {
+			components = append(components, "")
+		}
+		town := strings.Join(strings.Fields(strings.Join(components[3:6], " ")), " ")
+		lines := make([]string, 0)
+		for _, line := range []string{components[0], components[1], components[2], town, components[6]} {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		member.Address = nonEmpty(strings.Join(lines, "\n"))
+	}
+
+	if notes := properties["NOTE"]; len(notes) > 0 {
+		texts := make([]string, len(notes))
+		for i, note := range notes {
+			texts[i] = unescapeVCardText(note.value)
+		}
+		member.Notes = strings.Join(texts, "\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package memberio_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/memberio"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+func TestVCardRoundTrip(t *testing.T) {
+	row := domain.MemberRow{
+		Id:           12,
+		FirstName:    util.NewPtr("Marie"),
+		LastName:     util.NewPtr("Dentière"),
+		EmailAddress: util.NewPtr("marie@geneva.ch"),
+		PhoneNumber:  util.NewPtr("+41 22 310 00 00"),
+		Address:      util.NewPtr("Rue de la Cité 1\nGeneva, Switzerland"),
+		Notes:        "Wrote the Epistle to Marguerite de Navarre; a reformer in her own right. " + strings.Repeat("Long note. ", 10),
+	}
+	member, err := row.ToMember()
+	if err != nil {
+		t.Fatalf("error converting row to member: %v", err)
+	}
+
+	output := bytes.Buffer{}
+	if err = memberio.WriteVCard(&output, member); err != nil {
+		t.Fatalf("error writing vCard: %v", err)
+	}
+
+	for _, line := range strings.Split(output.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("expected lines to be folded to 75 octets, got %d: %s", len(line), line)
+		}
+	}
+
+	rows, err := memberio.ReadVCards(&output)
+	if err != nil {
+		t.Fatalf("error reading vCard: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected one vCard, got %d", len(rows))
+	}
+
+	read := rows[0].Member
+	if *read.FirstName != *row.FirstName || *read.LastName != *row.LastName ||
+		*read.EmailAddress != *row.EmailAddress || *read.PhoneNumber != *row.PhoneNumber ||
+		*read.Address != *row.Address || read.Notes != row.Notes {
+		t.Errorf("expected member to survive a round trip, wrote %v and read %v", row, read)
+	}
+}
+
+func TestReadVCards(t *testing.T) {
+	file := "BEGIN:VCARD\r\n" +
+		"VERSION:4.0\r\n" +
+		"FN:Guillaume Farel\r\n" +
+		"EMAIL;TYPE=work:farel@neuchatel.ch\r\n" +
+		"EMAIL;PREF=1:guillaume@farel.ch\r\n" +
+		"TEL;VALUE=uri;TYPE=\"voice,cell\":tel:+41-32-000-0000\r\n" +
+		"ADR;TYPE=home:;;Rue du Temple 2;Neuchâtel;;2000;\r\n" +
+		" Switzerland\r\n" +
+		"END:VCARD\r\n" +
+		"\r\n" +
+		"BEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		"N:Viret;Pierre;;;\r\n" +
+		"item1.TEL:021 000 00 00\r\n" +
+		"NOTE:Preached in Lausanne\\, then Lyon\r\n" +
+		"END:VCARD\r\n"
+
+	rows, err := memberio.ReadVCards(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("error reading vCards: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 vCards, got %d", len(rows))
+	}
+
+	farel := rows[0].Member
+	if rows[0].Line != 1 || *farel.FirstName != "Guillaume" || *farel.LastName != "Farel" {
+		t.Errorf("expected name to be read from FN on line 1, got line %d %v", rows[0].Line, farel)
+	}
+	if *farel.EmailAddress != "guillaume@farel.ch" {
+		t.Errorf("expected the preferred email address, got %s", *farel.EmailAddress)
+	}
+	if *farel.PhoneNumber != "+41-32-000-0000" {
+		t.Errorf("expected the tel: URI to be read as a phone number, got %s", *farel.PhoneNumber)
+	}
+	if *farel.Address != "Rue du Temple 2\nNeuchâtel 2000\nSwitzerland" {
+		t.Errorf("expected the folded address to be read as lines, got %q", *farel.Address)
+	}
+
+	viret := rows[1].Member
+	if rows[1].Line != 11 || *viret.LastName != "Viret" || *viret.PhoneNumber != "021 000 00 00" {
+		t.Errorf("expected the second vCard on line 11 to be read, got line %d %v", rows[1].Line, viret)
+	}
+	if viret.Notes != "Preached in Lausanne, then Lyon" || viret.EmailAddress != nil {
+		t.Errorf("expected an unescaped note and no email address, got %v", viret)
+	}
+
+	if _, err = memberio.ReadVCards(strings.NewReader("BEGIN:VCARD\r\nVERSION:2.1\r\nEND:VCARD\r\n")); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+	if _, err = memberio.ReadVCards(strings.NewReader("BEGIN:VCARD\r\nN:Zell;Matthew\r\n")); err == nil {
+		t.Error("expected an error for a vCard which does not end")
+	}
+}
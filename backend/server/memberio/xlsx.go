@@ -0,0 +1,141 @@
+package memberio
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+// The parts of a workbook with a single sheet, other than the sheet itself.
+// Strings are written inline in the sheet so that no shared string table,
+// which would need every value up front, is required.
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Members" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+type xlsxExportWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	columns []ExportColumn
+	row     int
+}
+
+func newXLSXExportWriter(w io.Writer, columns []ExportColumn) (*xlsxExportWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		partWriter, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = io.WriteString(partWriter, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetWriter, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &xlsxExportWriter{
+		archive: archive,
+		sheet:   bufio.NewWriter(sheetWriter),
+		columns: columns,
+	}
+	writer.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	if err = writer.writeRow(header); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// Gives the spreadsheet name of a zero-based column index, e.g. 0 is A and 26
+// is AA.
+func xlsxColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+func (writer *xlsxExportWriter) writeRow(values []any) error {
+	writer.row++
+	row := strconv.Itoa(writer.row)
+
+	writer.sheet.WriteString(`<row r="` + row + `">`)
+	for i, value := range values {
+		reference := xlsxColumnName(i) + row
+		switch value := value.(type) {
+		case uint64:
+			writer.sheet.WriteString(`<c r="` + reference + `"><v>` + strconv.FormatUint(value, 10) + `</v></c>`)
+		case string:
+			// text is always an inline string, which is never taken as a
+			// formula however it starts
+			writer.sheet.WriteString(`<c r="` + reference + `" t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(writer.sheet, []byte(value)); err != nil {
+				return err
+			}
+			writer.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := writer.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (writer *xlsxExportWriter) Write(member *domain.Member) error {
+	values := make([]any, len(writer.columns))
+	for i, column := range writer.columns {
+		switch value := column.value(member).(type) {
+		case uint64:
+			values[i] = value
+		default:
+			// absent values are left as empty cells
+			if text := column.text(member); text != "" {
+				values[i] = text
+			}
+		}
+	}
+	return writer.writeRow(values)
+}
+
+func (writer *xlsxExportWriter) Close() error {
+	if _, err := writer.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := writer.sheet.Flush(); err != nil {
+		return err
+	}
+	return writer.archive.Close()
+}
@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type DatabaseConfig struct {
+	// Whether members and schedules are counted for each church, labelled by
+	// its slug, rather than for every church at once. Whoever scrapes the
+	// metrics then sees the slug of every church.
+	TenantLabels bool
+	// How long what is read by Refresh is kept before it is read again
+	RefreshInterval time.Duration
+}
+
+// The members and schedules of a church, counted for its gauges.
+type churchCounts struct {
+	slug      string
+	members   int64
+	schedules int64
+}
+
+// The metrics of the database. How its connections are used is read at each
+// scrape, while the version of its schema and the members and schedules of the
+// churches are only read by Refresh, as counting takes queries for each
+// church. Safe to use from many goroutines.
+type Database struct {
+	tenants   store.TenantRepository
+	members   store.MemberRepository
+	schedules store.ScheduleRepository
+	health    store.HealthRepository
+	config    *DatabaseConfig
+
+	connections      *prometheus.Desc
+	maxConnections   *prometheus.Desc
+	waits            *prometheus.Desc
+	waitDuration     *prometheus.Desc
+	migrationVersion *prometheus.Desc
+	migrationDirty   *prometheus.Desc
+	memberCount      *prometheus.Desc
+	scheduleCount    *prometheus.Desc
+
+	mutex       sync.Mutex
+	refreshedAt time.Time
+	// What Refresh last read, each nil if it could not be read
+	status *domain.MigrationStatus
+	counts []churchCounts
+}
+
+var _ prometheus.Collector = (*Database)(nil)
+
+func NewDatabase(
+	tenants store.TenantRepository,
+	members store.MemberRepository,
+	schedules store.ScheduleRepository,
+	health store.HealthRepository,
+	config *DatabaseConfig,
+) *Database {
+	var countLabels []string
+	if config.TenantLabels {
+		countLabels = []string{"tenant"}
+	}
+
+	return &Database{
+		tenants:   tenants,
+		members:   members,
+		schedules: schedules,
+		health:    health,
+		config:    config,
+
+		connections: prometheus.NewDesc(Namespace+"_database_connections",
+			"Connections to the database, by whether they are in use.", []string{"state"}, nil),
+		maxConnections: prometheus.NewDesc(Namespace+"_database_connections_max",
+			"The most connections to the database which may be open at once, or 0 for no limit.", nil, nil),
+		waits: prometheus.NewDesc(Namespace+"_database_connection_waits_total",
+			"Times a query waited for a connection because none were idle.", nil, nil),
+		waitDuration: prometheus.NewDesc(Namespace+"_database_connection_wait_seconds_total",
+			"How long queries have waited for connections in all.", nil, nil),
+		migrationVersion: prometheus.NewDesc(Namespace+"_migration_version",
+			"The version of the database's schema.", nil, nil),
+		migrationDirty: prometheus.NewDesc(Namespace+"_migration_dirty",
+			"1 if a migration failed part of the way through and has to be fixed by hand.", nil, nil),
+		memberCount: prometheus.NewDesc(Namespace+"_members",
+			"Members of the churches, excluding those in the trash.", countLabels, nil),
+		scheduleCount: prometheus.NewDesc(Namespace+"_schedules",
+			"Service schedules of the churches.", countLabels, nil),
+	}
+}
+
+// Reads the version of the schema and counts the members and schedules of the
+// churches again, unless they were read within the refresh interval. What
+// cannot be read is left out of the metrics until it is read again.
+func (database *Database) Refresh(ctx context.Context) error {
+	database.mutex.Lock()
+	defer database.mutex.Unlock()
+
+	if !database.refreshedAt.IsZero() && time.Since(database.refreshedAt) < database.config.RefreshInterval {
+		return nil
+	}
+	// a database which cannot be read is not read again any sooner, so that
+	// scrapes do not pile queries onto it
+	database.refreshedAt = time.Now()
+
+	var statusErr, countErr error
+	database.status, statusErr = database.health.MigrationStatus(ctx)
+	database.counts, countErr = database.countChurches(ctx)
+	return errors.Join(statusErr, countErr)
+}
+
+// Counts the members and schedules of each church, as each church's rows can
+// only be seen by a store for that church.
+func (database *Database) countChurches(ctx context.Context) ([]churchCounts, error) {
+	tenants, err := database.tenants.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]churchCounts, len(tenants))
+	for i := range tenants {
+		counts[i].slug = tenants[i].Slug()
+		if counts[i].members, err = database.members.ForTenant(tenants[i].Id()).Count(ctx); err != nil {
+			return nil, err
+		}
+		if counts[i].schedules, err = database.schedules.ForTenant(tenants[i].Id()).Count(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+func (database *Database) Describe(descs chan<- *prometheus.Desc) {
+	descs <- database.connections
+	descs <- database.maxConnections
+	descs <- database.waits
+	descs <- database.waitDuration
+	descs <- database.migrationVersion
+	descs <- database.migrationDirty
+	descs <- database.memberCount
+	descs <- database.scheduleCount
+}
+
+func (database *Database) Collect(metrics chan<- prometheus.Metric) {
+	stats := database.health.ConnectionStats()
+	metrics <- prometheus.MustNewConstMetric(database.connections, prometheus.GaugeValue, float64(stats.Acquired), "acquired")
+	metrics <- prometheus.MustNewConstMetric(database.connections, prometheus.GaugeValue, float64(stats.Idle), "idle")
+	metrics <- prometheus.MustNewConstMetric(database.maxConnections, prometheus.GaugeValue, float64(stats.Max))
+	metrics <- prometheus.MustNewConstMetric(database.waits, prometheus.CounterValue, float64(stats.Waits))
+	metrics <- prometheus.MustNewConstMetric(database.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+
+	database.mutex.Lock()
+	defer database.mutex.Unlock()
+
+	if database.status != nil {
+		dirty := 0.0
+		if database.status.Dirty {
+			dirty = 1
+		}
+		metrics <- prometheus.MustNewConstMetric(database.migrationVersion, prometheus.GaugeValue, float64(database.status.Version))
+		metrics <- prometheus.MustNewConstMetric(database.migrationDirty, prometheus.GaugeValue, dirty)
+	}
+
+	if database.counts == nil {
+		return
+	}
+	if database.config.TenantLabels {
+		for _, count := range database.counts {
+			metrics <- prometheus.MustNewConstMetric(database.memberCount, prometheus.GaugeValue, float64(count.members), count.slug)
+			metrics <- prometheus.MustNewConstMetric(database.scheduleCount, prometheus.GaugeValue, float64(count.schedules), count.slug)
+		}
+		return
+	}
+	var members, schedules int64
+	for _, count := range database.counts {
+		members += count.members
+		schedules += count.schedules
+	}
+	metrics <- prometheus.MustNewConstMetric(database.memberCount, prometheus.GaugeValue, float64(members))
+	metrics <- prometheus.MustNewConstMetric(database.scheduleCount, prometheus.GaugeValue, float64(schedules))
+}
@@ -0,0 +1,7 @@
+// Package metrics counts and times the requests the server serves, and reads
+// the state of the database, for Prometheus to scrape so that slow or failing
+// routes can be alerted on.
+package metrics
+
+// Every metric is named with this prefix.
+const Namespace = "churchmanager"
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The upper bounds, in seconds, of the buckets the durations of requests are
+// counted in.
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// The route of requests which matched none, so that requests for made up paths
+// do not each become a series of their own.
+const UnmatchedRoute = "unmatched"
+
+// The method of requests with a method HTTP does not define, so that made up
+// methods do not each become a series of their own.
+const OtherMethod = "OTHER"
+
+var methods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// The requests served since the server started, by their route rather than by
+// their path so that ids in paths do not each become a series of their own.
+// Safe to use from many goroutines.
+type Requests struct {
+	total    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+var _ prometheus.Collector = (*Requests)(nil)
+
+func NewRequests() *Requests {
+	return &Requests{
+		total: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "http_requests_total",
+			Help:      "Requests served, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "How long requests took to serve, by method and route.",
+			Buckets:   DurationBuckets,
+		}, []string{"method", "route"}),
+	}
+}
+
+// Counts a request for the route, e.g. /members/:id, or for UnmatchedRoute if
+// route is empty, which was served with the status in the duration. Methods
+// HTTP does not define are counted as OtherMethod.
+func (requests *Requests) Observe(method string, route string, status int, duration time.Duration) {
+	if !methods[method] {
+		method = OtherMethod
+	}
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	requests.total.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	requests.duration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func (requests *Requests) Describe(descs chan<- *prometheus.Desc) {
+	requests.total.Describe(descs)
+	requests.duration.Describe(descs)
+}
+
+func (requests *Requests) Collect(metrics chan<- prometheus.Metric) {
+	requests.total.Collect(metrics)
+	requests.duration.Collect(metrics)
+}
@@ -0,0 +1,83 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRequestsAreCountedInBuckets(t *testing.T) {
+	requests := metrics.NewRequests()
+	requests.Observe("GET", "/members", 200, 20*time.Millisecond)
+	requests.Observe("GET", "/members", 200, 3*time.Second)
+	requests.Observe("GET", "/members", 500, 50*time.Millisecond)
+	requests.Observe("GET", "", 404, time.Millisecond)
+
+	expected := `
+# HELP churchmanager_http_requests_total Requests served, by method, route and status.
+# TYPE churchmanager_http_requests_total counter
+churchmanager_http_requests_total{method="GET",route="/members",status="200"} 2
+churchmanager_http_requests_total{method="GET",route="/members",status="500"} 1
+churchmanager_http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	if err := testutil.CollectAndCompare(requests, strings.NewReader(expected), "churchmanager_http_requests_total"); err != nil {
+		t.Error(err)
+	}
+
+	expected = `
+# HELP churchmanager_http_request_duration_seconds How long requests took to serve, by method and route.
+# TYPE churchmanager_http_request_duration_seconds histogram
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="0.005"} 0
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="0.01"} 0
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="0.025"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="0.05"} 2
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="0.1"} 2
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="0.25"} 2
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="0.5"} 2
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="1"} 2
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="2.5"} 2
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="5"} 3
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="10"} 3
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="/members",le="+Inf"} 3
+churchmanager_http_request_duration_seconds_sum{method="GET",route="/members"} 3.07
+churchmanager_http_request_duration_seconds_count{method="GET",route="/members"} 3
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="0.005"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="0.01"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="0.025"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="0.05"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="0.1"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="0.25"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="0.5"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="1"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="2.5"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="5"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="10"} 1
+churchmanager_http_request_duration_seconds_bucket{method="GET",route="unmatched",le="+Inf"} 1
+churchmanager_http_request_duration_seconds_sum{method="GET",route="unmatched"} 0.001
+churchmanager_http_request_duration_seconds_count{method="GET",route="unmatched"} 1
+`
+	// a duration on the bound of a bucket is counted in it
+	if err := testutil.CollectAndCompare(requests, strings.NewReader(expected), "churchmanager_http_request_duration_seconds"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnknownMethodsAreCountedTogether(t *testing.T) {
+	requests := metrics.NewRequests()
+	requests.Observe("BREW", "", 404, time.Millisecond)
+	requests.Observe("WHEN", "", 404, time.Millisecond)
+	requests.Observe("PATCH", "/me", 200, time.Millisecond)
+
+	expected := `
+# HELP churchmanager_http_requests_total Requests served, by method, route and status.
+# TYPE churchmanager_http_requests_total counter
+churchmanager_http_requests_total{method="OTHER",route="unmatched",status="404"} 2
+churchmanager_http_requests_total{method="PATCH",route="/me",status="200"} 1
+`
+	if err := testutil.CollectAndCompare(requests, strings.NewReader(expected), "churchmanager_http_requests_total"); err != nil {
+		t.Error(err)
+	}
+}
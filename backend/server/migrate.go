@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/carsonalh/churchmanagerbackend/server/config"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/migration"
+)
+
+// Changes or shows the version of the database's schema, for deployments which
+// migrate separately from starting the servers.
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "migrate needs a subcommand\n\n%s", usage)
+		os.Exit(2)
+	}
+	subcommand, args := args[0], args[1:]
+
+	// each subcommand but up and version takes a single number
+	var number uint
+	switch subcommand {
+	case "up", "version":
+		if len(args) != 0 {
+			fatal("migrate takes no arguments", "subcommand", subcommand)
+		}
+	case "down", "goto", "force":
+		if len(args) != 1 {
+			fatal("migrate takes a single number", "subcommand", subcommand)
+		}
+		parsed, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			fatal("migrate takes a single number", "subcommand", subcommand, "argument", args[0])
+		}
+		number = uint(parsed)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate subcommand %q\n\n%s", subcommand, usage)
+		os.Exit(2)
+	}
+
+	config, err := config.Load(os.LookupEnv)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+
+	migrator, err := migration.Open(config.Database.Migrations, config.Database.URL)
+	if err != nil {
+		fatal("failed to open the database", "error", err)
+	}
+
+	var status *domain.MigrationStatus
+	switch subcommand {
+	case "up":
+		status, err = migrator.Up()
+	case "down":
+		status, err = migrator.Down(number)
+	case "goto":
+		status, err = migrator.Goto(number)
+	case "force":
+		status, err = migrator.Force(number)
+	case "version":
+		status, err = migrator.Status()
+	}
+	closeErr := migrator.Close()
+	if err != nil {
+		fatal("failed to migrate", "subcommand", subcommand, "error", err)
+	}
+	if closeErr != nil {
+		fatal("failed to close the database", "error", closeErr)
+	}
+
+	latest, err := migration.LatestVersion(config.Database.Migrations, config.Database.URL)
+	if err != nil {
+		fatal("failed to read the latest version", "error", err)
+	}
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty; fix it by hand, then force the version it is at)"
+	}
+	fmt.Printf("database is at migration version %d%s; the latest is %d\n", status.Version, dirty, latest)
+}
+
+// Checks that the database has been migrated to the latest version, giving the
+// version it is at.
+func checkMigrated(migrationsPath string, connectionString string) (*domain.MigrationStatus, error) {
+	latest, err := migration.LatestVersion(migrationsPath, connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := migration.Open(migrationsPath, connectionString)
+	if err != nil {
+		return nil, err
+	}
+	status, err := migrator.Status()
+	closeErr := migrator.Close()
+	if err != nil {
+		return nil, err
+	}
+	if closeErr != nil {
+		return nil, closeErr
+	}
+
+	if !status.IsAt(latest) {
+		return nil, fmt.Errorf("database is at migration version %d rather than %d; run `churchmanager migrate up` first", status.Version, latest)
+	}
+	return status, nil
+}
@@ -3,31 +3,150 @@ package migration
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store/sqlite"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// Creates a database connection, migrates the database to the most current
-// version, and destroys the connection.
-func PerformMigration(migrationsPath string, connectionString string) error {
-	migration, err := migrate.New("file://"+migrationsPath, connectionString+"?sslmode=disable")
+// Changes the version of a database's schema by running its migrations.
+type Migrator struct {
+	migrate *migrate.Migrate
+}
 
-	if err != nil {
-		return fmt.Errorf("migration client failed to initialise: %v", err)
+// Gives the URLs golang-migrate reads the migrations from and opens the
+// database with. SQLite databases are migrated with the migrations in the
+// sqlite directory of the migrations path rather than those for postgres.
+func migrationURLs(migrationsPath string, connectionString string) (sourceURL string, databaseURL string) {
+	if sqlite.IsConnectionString(connectionString) {
+		return "file://" + filepath.Join(migrationsPath, "sqlite"), sqlite.MigrationURL(connectionString)
 	}
-	err = migration.Up()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("migration failed: %v", err)
+	return "file://" + migrationsPath, connectionString + "?sslmode=disable"
+}
+
+// Connects to the database to migrate it with the migrations in the path. The
+// migrator has to be closed once it is no longer used.
+func Open(migrationsPath string, connectionString string) (*Migrator, error) {
+	migration, err := migrate.New(migrationURLs(migrationsPath, connectionString))
+	if err != nil {
+		return nil, fmt.Errorf("migration client failed to initialise: %v", err)
 	}
-	fileErr, dbErr := migration.Close()
+	return &Migrator{migrate: migration}, nil
+}
+
+func (migrator *Migrator) Close() error {
+	fileErr, dbErr := migrator.migrate.Close()
 	if fileErr != nil {
 		return fmt.Errorf("failed to close files after migration: %v", fileErr)
 	}
 	if dbErr != nil {
 		return fmt.Errorf("failed to close database after migration: %v", dbErr)
 	}
-
 	return nil
 }
+
+// Gives the version the database is at, which is 0 if it has never been
+// migrated.
+func (migrator *Migrator) Status() (*domain.MigrationStatus, error) {
+	version, dirty, err := migrator.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, fmt.Errorf("failed to read the version: %v", err)
+	}
+	return &domain.MigrationStatus{Version: version, Dirty: dirty}, nil
+}
+
+// Runs the migration, giving the version the database is at afterwards. A
+// database already at the version it would be migrated to is not an error.
+func (migrator *Migrator) run(migration func() error) (*domain.MigrationStatus, error) {
+	err := migration()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return nil, fmt.Errorf("migration failed: %v", err)
+	}
+	return migrator.Status()
+}
+
+// Migrates the database to the most current version.
+func (migrator *Migrator) Up() (*domain.MigrationStatus, error) {
+	return migrator.run(migrator.migrate.Up)
+}
+
+// Rolls back the given number of the most recent migrations.
+func (migrator *Migrator) Down(steps uint) (*domain.MigrationStatus, error) {
+	if steps == 0 {
+		return nil, fmt.Errorf("the number of migrations to roll back must be at least 1")
+	}
+	return migrator.run(func() error {
+		return migrator.migrate.Steps(-int(steps))
+	})
+}
+
+// Migrates the database up or down to the version.
+func (migrator *Migrator) Goto(version uint) (*domain.MigrationStatus, error) {
+	return migrator.run(func() error {
+		return migrator.migrate.Migrate(version)
+	})
+}
+
+// Records the database as being cleanly at the version without running any
+// migrations, for once a dirty database has been fixed by hand. Version 0 is
+// a database which has never been migrated.
+func (migrator *Migrator) Force(version uint) (*domain.MigrationStatus, error) {
+	return migrator.run(func() error {
+		if version == 0 {
+			return migrator.migrate.Force(database.NilVersion)
+		}
+		return migrator.migrate.Force(int(version))
+	})
+}
+
+// Gives the version of the last of the migrations for the database, which it
+// is at once migrated up.
+func LatestVersion(migrationsPath string, connectionString string) (uint, error) {
+	sourceURL, _ := migrationURLs(migrationsPath, connectionString)
+	migrations, err := source.Open(sourceURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %v", err)
+	}
+	defer migrations.Close()
+
+	version, err := migrations.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	for err == nil {
+		var next uint
+		if next, err = migrations.Next(version); err == nil {
+			version = next
+		}
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to read migrations: %v", err)
+	}
+	return version, nil
+}
+
+// Creates a database connection, migrates the database to the most current
+// version, and destroys the connection. Gives the version the database is at
+// once migrated.
+func PerformMigration(migrationsPath string, connectionString string) (*domain.MigrationStatus, error) {
+	migrator, err := Open(migrationsPath, connectionString)
+	if err != nil {
+		return nil, err
+	}
+	status, err := migrator.Up()
+	if err != nil {
+		migrator.Close()
+		return nil, err
+	}
+	if err = migrator.Close(); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
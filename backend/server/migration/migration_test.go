@@ -0,0 +1,70 @@
+package migration_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/migration"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/sqlite"
+)
+
+const migrationsPath = "../../migrations"
+
+// Migrates a SQLite database up, back down and to a version, which needs no
+// database server.
+func TestMigratorUpDownAndGoto(t *testing.T) {
+	connectionString := "sqlite://" + filepath.Join(t.TempDir(), "churchmanager.db")
+	latest, err := migration.LatestVersion(migrationsPath, connectionString)
+	if err != nil {
+		t.Fatalf("failed to read the latest version: %v", err)
+	}
+	if latest == 0 {
+		t.Fatal("expected there to be migrations for SQLite")
+	}
+
+	migrator, err := migration.Open(migrationsPath, connectionString)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	defer migrator.Close()
+
+	if status, err := migrator.Status(); err != nil || status.Version != 0 {
+		t.Errorf("expected a new database to be at version 0, got %+v, %v", status, err)
+	}
+	if status, err := migrator.Up(); err != nil || !status.IsAt(latest) {
+		t.Errorf("expected migrating up to reach version %d, got %+v, %v", latest, status, err)
+	}
+	if status, err := migrator.Up(); err != nil || !status.IsAt(latest) {
+		t.Errorf("expected migrating an up to date database to change nothing, got %+v, %v", status, err)
+	}
+	if _, err := migrator.Down(0); err == nil {
+		t.Error("expected rolling back no migrations to be an error")
+	}
+	if status, err := migrator.Down(1); err != nil || !status.IsAt(latest-1) {
+		t.Errorf("expected rolling back one migration to reach version %d, got %+v, %v", latest-1, status, err)
+	}
+	if status, err := migrator.Goto(latest); err != nil || !status.IsAt(latest) {
+		t.Errorf("expected going to version %d to reach it, got %+v, %v", latest, status, err)
+	}
+	if status, err := migrator.Force(0); err != nil || !status.IsAt(0) {
+		t.Errorf("expected forcing version 0 to record the database as never migrated, got %+v, %v", status, err)
+	}
+}
+
+func TestSQLiteMigrationsCreateTheSchema(t *testing.T) {
+	connectionString := "sqlite://" + filepath.Join(t.TempDir(), "churchmanager.db")
+	if _, err := migration.PerformMigration(migrationsPath, connectionString); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	stores, err := sqlite.Open(context.Background(), connectionString, 0)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	defer stores.Close()
+	if err = store.CheckSchema(context.Background(), stores.Health); err != nil {
+		t.Error(err)
+	}
+}
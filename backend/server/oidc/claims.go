@@ -0,0 +1,68 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The claims of an ID token used to identify and provision a user.
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	PreferredUsername string   `json:"preferred_username"`
+
+	// Every claim, for reading the configured role claim
+	raw map[string]any
+}
+
+// The aud claim, which is either a single string or an array of strings.
+type audience []string
+
+func (aud *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+	*aud = many
+	return nil
+}
+
+func parseClaims(payload []byte) (*Claims, error) {
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("reading ID token claims: %v", err)
+	}
+	if err := json.Unmarshal(payload, &claims.raw); err != nil {
+		return nil, fmt.Errorf("reading ID token claims: %v", err)
+	}
+	return &claims, nil
+}
+
+// Gives the string values of a claim which is either a string or an array,
+// ignoring values of other types.
+func (claims *Claims) Strings(name string) []string {
+	values := make([]string, 0)
+	switch value := claims.raw[name].(type) {
+	case string:
+		values = append(values, value)
+	case []any:
+		for _, element := range value {
+			if s, ok := element.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
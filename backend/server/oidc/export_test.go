@@ -0,0 +1,18 @@
+package oidc
+
+import "time"
+
+// Sets how soon keys may be fetched again, giving a function which restores
+// it.
+func SetKeyRefetchInterval(interval time.Duration) (restore func()) {
+	previous := keyRefetchInterval
+	keyRefetchInterval = interval
+	return func() { keyRefetchInterval = previous }
+}
+
+// Sets how long keys are trusted for, giving a function which restores it.
+func SetKeyMaxAge(maxAge time.Duration) (restore func()) {
+	previous := keyMaxAge
+	keyMaxAge = maxAge
+	return func() { keyMaxAge = previous }
+}
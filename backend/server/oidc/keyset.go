@@ -0,0 +1,162 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// The smallest RSA keys ID tokens are accepted from.
+const minRSAKeyBits = 2048
+
+// How soon a provider's keys may be fetched again after they were last
+// fetched, so that tokens signed with unknown keys cannot make the server
+// fetch them on every login.
+var keyRefetchInterval = time.Minute
+
+// How long keys are trusted for after they are fetched, unless the provider
+// says they may only be cached for less. Keys the provider has revoked are
+// accepted until then.
+var keyMaxAge = time.Hour
+
+var errUnknownKey = errors.New("ID token is signed with an unknown key")
+
+// The signing keys of a provider, fetched again when a token is signed with a
+// key which is not known, as when the provider has rotated in a new key, and
+// once they expire.
+type keySet struct {
+	url    string
+	client *http.Client
+
+	mutex     sync.Mutex
+	keys      []jose.JSONWebKey
+	fetchedAt time.Time
+	expiresAt time.Time
+	// Why the keys could not be fetched when they were last fetched
+	err error
+}
+
+// Checks the RS256 signature of an ID token, giving its payload.
+func (set *keySet) VerifySignature(ctx context.Context, token string) ([]byte, error) {
+	// never trust "none" or symmetric algorithms chosen by the token
+	signed, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+	if err != nil {
+		return nil, fmt.Errorf("reading ID token: %v", err)
+	}
+	if len(signed.Signatures) != 1 {
+		return nil, fmt.Errorf("ID token has %d signatures, expected 1", len(signed.Signatures))
+	}
+
+	keys, err := set.keysWithId(ctx, signed.Signatures[0].Header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if payload, err := signed.Verify(key); err == nil {
+			return payload, nil
+		}
+	}
+	return nil, errors.New("ID token signature is invalid")
+}
+
+// Gives the keys with the id, or every key if the id is empty, fetching the
+// keys again if there are none or they have expired, unless they were fetched
+// too recently.
+func (set *keySet) keysWithId(ctx context.Context, keyId string) ([]jose.JSONWebKey, error) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	keys := set.withId(keyId)
+	if (len(keys) == 0 || set.expired()) && time.Since(set.fetchedAt) >= keyRefetchInterval {
+		set.err = set.fetch(ctx)
+		keys = set.withId(keyId)
+	}
+	// keys are only left to expire when they could not be fetched again
+	if set.expired() && set.err != nil {
+		return nil, set.err
+	}
+	if len(keys) == 0 {
+		return nil, errUnknownKey
+	}
+	return keys, nil
+}
+
+func (set *keySet) expired() bool {
+	return !time.Now().Before(set.expiresAt)
+}
+
+func (set *keySet) withId(keyId string) []jose.JSONWebKey {
+	keys := make([]jose.JSONWebKey, 0, 1)
+	for _, key := range set.keys {
+		if keyId == "" || key.KeyID == keyId {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Fetches the keys, keeping only RSA signing keys large enough to trust. A
+// failed fetch also counts as a fetch, so that a provider which is down is not
+// asked for its keys on every login.
+func (set *keySet) fetch(ctx context.Context) error {
+	set.fetchedAt = time.Now()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, set.url, nil)
+	if err != nil {
+		return err
+	}
+	response, err := set.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("getting keys: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("getting keys: GET %s : unexpected status %s", set.url, response.Status)
+	}
+	var keySet jose.JSONWebKeySet
+	if err = json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&keySet); err != nil {
+		return fmt.Errorf("reading keys: %v", err)
+	}
+
+	// keys are trusted for at least as long as they may not be fetched again,
+	// so that they do not expire while they cannot be replaced
+	maxAge := min(cacheMaxAge(response.Header), keyMaxAge)
+	set.expiresAt = set.fetchedAt.Add(max(maxAge, keyRefetchInterval))
+
+	set.keys = set.keys[:0]
+	for _, key := range keySet.Keys {
+		publicKey, ok := key.Key.(*rsa.PublicKey)
+		if !ok || !key.Valid() || key.Use != "" && key.Use != "sig" || publicKey.N.BitLen() < minRSAKeyBits {
+			continue
+		}
+		set.keys = append(set.keys, key)
+	}
+	return nil
+}
+
+// Gives the max-age directive of a Cache-Control header, or keyMaxAge if it
+// has none. Responses which may not be cached have a max-age of 0.
+func cacheMaxAge(header http.Header) time.Duration {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return keyMaxAge
+}
@@ -0,0 +1,322 @@
+// Package oidctest provides an in-process OpenID Connect provider for tests,
+// supporting discovery, the authorization code flow with PKCE and RS256 signed
+// ID tokens.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Provider struct {
+	server       *httptest.Server
+	clientId     string
+	clientSecret string
+
+	mutex sync.Mutex
+	key   *rsa.PrivateKey
+	keyId int
+	// Whether the key is no longer published, though tokens are still signed
+	// with it
+	keyRevoked bool
+	// The Cache-Control header the keys are served with
+	keyCacheControl string
+	// How many times the keys have been fetched
+	keyFetches int
+	// The claims of the user who logs in next
+	user  map[string]any
+	codes map[string]authorization
+}
+
+// A code given to the client, waiting to be exchanged for an ID token.
+type authorization struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	claims        map[string]any
+}
+
+// Starts a provider for a single client. The provider must be closed.
+func NewProvider(clientId string, clientSecret string) (*Provider, error) {
+	provider := &Provider{
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		codes:        make(map[string]authorization),
+	}
+	if err := provider.RotateKey(); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", provider.discovery)
+	mux.HandleFunc("GET /jwks", provider.jwks)
+	mux.HandleFunc("GET /authorize", provider.authorize)
+	mux.HandleFunc("POST /token", provider.token)
+	provider.server = httptest.NewServer(mux)
+
+	return provider, nil
+}
+
+func (provider *Provider) Close() {
+	provider.server.Close()
+}
+
+func (provider *Provider) Issuer() string {
+	return provider.server.URL
+}
+
+// Replaces the signing key, as providers do from time to time.
+func (provider *Provider) RotateKey() error {
+	return provider.RotateKeyOfSize(2048)
+}
+
+// Replaces the signing key with an RSA key of the size in bits, e.g. one too
+// small to be trusted.
+func (provider *Provider) RotateKeyOfSize(bits int) error {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return err
+	}
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.key = key
+	provider.keyId++
+	provider.keyRevoked = false
+	return nil
+}
+
+// Stops publishing the signing key while still signing tokens with it, as
+// someone who stole the key would.
+func (provider *Provider) RevokeKey() {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.keyRevoked = true
+}
+
+// Sets the Cache-Control header the keys are served with, e.g. max-age=0.
+func (provider *Provider) SetKeyCacheControl(value string) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.keyCacheControl = value
+}
+
+// Sets the claims of the user who logs in next, which must include sub.
+func (provider *Provider) SetUser(claims map[string]any) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.user = claims
+}
+
+// Follows an authorization URL as the user's browser would, giving the code
+// and state the provider redirects back with.
+func (provider *Provider) Login(authorizationURL string) (code string, state string, err error) {
+	client := http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	response, err := client.Get(authorizationURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("expected a redirect from the provider, got %s", response.Status)
+	}
+	location, err := response.Location()
+	if err != nil {
+		return "", "", err
+	}
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (provider *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                provider.Issuer(),
+		"authorization_endpoint":                provider.Issuer() + "/authorize",
+		"token_endpoint":                        provider.Issuer() + "/token",
+		"jwks_uri":                              provider.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// Gives how many times the keys have been fetched.
+func (provider *Provider) KeyFetches() int {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	return provider.keyFetches
+}
+
+func (provider *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	provider.keyFetches++
+
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	keys := make([]map[string]string, 0, 1)
+	if !provider.keyRevoked {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": strconv.Itoa(provider.keyId),
+			"use": "sig",
+			"alg": "RS256",
+			"n":   encode(provider.key.N),
+			"e":   encode(big.NewInt(int64(provider.key.E))),
+		})
+	}
+	if provider.keyCacheControl != "" {
+		w.Header().Set("Cache-Control", provider.keyCacheControl)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+func (provider *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch {
+	case query.Get("response_type") != "code":
+		http.Error(w, "unsupported response_type", http.StatusBadRequest)
+		return
+	case query.Get("client_id") != provider.clientId:
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	case query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "":
+		http.Error(w, "an S256 code_challenge is required", http.StatusBadRequest)
+		return
+	}
+
+	provider.mutex.Lock()
+	if provider.user == nil {
+		provider.mutex.Unlock()
+		http.Error(w, "no user is set to log in", http.StatusBadRequest)
+		return
+	}
+	code := randomString()
+	provider.codes[code] = authorization{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		claims:        provider.user,
+	}
+	provider.mutex.Unlock()
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (provider *Provider) token(w http.ResponseWriter, r *http.Request) {
+	clientId, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientId != provider.clientId || clientSecret != provider.clientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	provider.mutex.Lock()
+	code := r.PostFormValue("code")
+	authorization, found := provider.codes[code]
+	// codes may only be used once
+	delete(provider.codes, code)
+	key, keyId := provider.key, provider.keyId
+	provider.mutex.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	switch {
+	case !found:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "unknown code"})
+		return
+	case authorization.redirectURI != r.PostFormValue("redirect_uri"):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "redirect_uri does not match"})
+		return
+	case base64.RawURLEncoding.EncodeToString(challenge[:]) != authorization.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code_verifier does not match"})
+		return
+	}
+
+	claims := map[string]any{
+		"iss":   provider.Issuer(),
+		"aud":   provider.clientId,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(5 * time.Minute).Unix(),
+		"nonce": authorization.nonce,
+	}
+	for name, value := range authorization.claims {
+		claims[name] = value
+	}
+
+	idToken, err := sign(key, strconv.Itoa(keyId), claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// Signs the claims as a compact serialised RS256 JWT.
+func sign(key *rsa.PrivateKey, keyId string, claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyId})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func randomString() string {
+	data := make([]byte, 24)
+	_, _ = rand.Read(data)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
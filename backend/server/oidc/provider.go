@@ -0,0 +1,219 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// How far the clocks of the server and a provider may disagree when checking
+// the times in an ID token.
+const clockSkew = time.Minute
+
+type ProviderConfig struct {
+	// Identifies the provider in URLs, e.g. "google"
+	Name string `json:"name"`
+	// The issuer URL, from which the provider's metadata is discovered
+	Issuer       string `json:"issuer"`
+	ClientId     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	// Where the provider sends users back to after they log in, which passes
+	// the code and state on to the callback endpoint
+	RedirectURL string `json:"redirectUrl"`
+	// Scopes asked for as well as openid, email and profile
+	Scopes []string `json:"scopes"`
+	// The claim listing the user's groups or roles at the provider, e.g. groups
+	RoleClaim string `json:"roleClaim"`
+	// Maps values of the role claim to roles
+	RoleMapping map[string]domain.Role `json:"roleMapping"`
+	// Roles given to every user who logs in with the provider
+	DefaultRoles []domain.Role `json:"defaultRoles"`
+	// The slugs of the churches whose users log in with the provider. Users
+	// are only created in these churches.
+	Tenants []string `json:"tenants"`
+}
+
+// An OpenID Connect provider, logged in to with the authorization code flow
+// and PKCE. The provider is discovered when first needed, and its keys are
+// fetched as tokens signed with them are seen.
+type Provider struct {
+	config ProviderConfig
+	client *http.Client
+
+	mutex sync.Mutex
+	// Set once the provider has been discovered
+	oauth2   *oauth2.Config
+	keys     *keySet
+	verifier *gooidc.IDTokenVerifier
+}
+
+// Creates a provider which makes requests with the client, or with a client
+// with a short timeout if the client is nil.
+func NewProvider(config ProviderConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{config: config, client: client}
+}
+
+func (provider *Provider) Name() string {
+	return provider.config.Name
+}
+
+func (provider *Provider) Issuer() string {
+	return provider.config.Issuer
+}
+
+// Whether users of the church with the slug log in with the provider.
+func (provider *Provider) ServesTenant(slug string) bool {
+	return slices.Contains(provider.config.Tenants, slug)
+}
+
+func (provider *Provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	if provider.oauth2 != nil {
+		return provider.oauth2, provider.verifier, nil
+	}
+
+	discovered, err := gooidc.NewProvider(gooidc.ClientContext(ctx, provider.client), provider.config.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovering provider %s: %w", provider.config.Name, err)
+	}
+	var metadata struct {
+		JwksURI string `json:"jwks_uri"`
+	}
+	if err = discovered.Claims(&metadata); err != nil || metadata.JwksURI == "" {
+		return nil, nil, fmt.Errorf("provider %s metadata has no jwks_uri", provider.config.Name)
+	}
+
+	endpoint := discovered.Endpoint()
+	endpoint.AuthStyle = oauth2.AuthStyleInHeader
+	provider.oauth2 = &oauth2.Config{
+		ClientID:     provider.config.ClientId,
+		ClientSecret: provider.config.ClientSecret,
+		Endpoint:     endpoint,
+		RedirectURL:  provider.config.RedirectURL,
+		Scopes:       append([]string{gooidc.ScopeOpenID, "email", "profile"}, provider.config.Scopes...),
+	}
+	provider.keys = &keySet{url: metadata.JwksURI, client: provider.client}
+	provider.verifier = gooidc.NewVerifier(provider.config.Issuer, provider.keys, &gooidc.Config{
+		ClientID:             provider.config.ClientId,
+		SupportedSigningAlgs: []string{gooidc.RS256},
+		// tokens which have only just expired by the provider's clock are
+		// accepted, as the clocks may disagree
+		Now: func() time.Time { return time.Now().Add(-clockSkew) },
+	})
+	return provider.oauth2, provider.verifier, nil
+}
+
+// Gives the URL to send the user to to log in at the provider. The state,
+// nonce and code verifier must be kept to finish the login.
+func (provider *Provider) AuthorizationURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	config, _, err := provider.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return config.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+// Exchanges the code the provider sent back for an ID token, giving the
+// token's claims once it is verified to be signed by the provider, for this
+// client and for the login with the nonce.
+func (provider *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*Claims, error) {
+	config, verifier, err := provider.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, provider.client), code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return nil, &ExchangeError{Code: retrieveErr.ErrorCode, Description: retrieveErr.ErrorDescription}
+		}
+		return nil, fmt.Errorf("exchanging code with provider %s: %w", provider.config.Name, err)
+	}
+	rawIdToken, _ := token.Extra("id_token").(string)
+	if rawIdToken == "" {
+		return nil, fmt.Errorf("provider %s gave no ID token", provider.config.Name)
+	}
+
+	// the verifier does not wrap the errors of its key set, so the signature
+	// is checked first to tell callers when the keys could not be fetched
+	if _, err = provider.keys.VerifySignature(ctx, rawIdToken); err != nil {
+		return nil, err
+	}
+	idToken, err := verifier.Verify(ctx, rawIdToken)
+	if err != nil {
+		return nil, err
+	}
+	var payload json.RawMessage
+	if err = idToken.Claims(&payload); err != nil {
+		return nil, err
+	}
+	claims, err := parseClaims(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err = provider.checkClaims(claims, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Checks what the verifier of go-oidc leaves to its callers, having checked
+// the issuer, audience, expiry and signature.
+func (provider *Provider) checkClaims(claims *Claims, nonce string, now time.Time) error {
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != provider.config.ClientId {
+		return fmt.Errorf("ID token is authorised for another party")
+	}
+	if claims.Subject == "" {
+		return fmt.Errorf("ID token has no subject")
+	}
+	if claims.IssuedAt != 0 && now.Add(clockSkew).Before(time.Unix(claims.IssuedAt, 0)) {
+		return fmt.Errorf("ID token is issued in the future")
+	}
+	if claims.Nonce != nonce {
+		return fmt.Errorf("ID token nonce does not match the login")
+	}
+	return nil
+}
+
+// Gives the roles of a user with the claims, from the provider's default roles
+// and its mapping of the role claim.
+func (provider *Provider) Roles(claims *Claims) []domain.Role {
+	roles := slices.Clone(provider.config.DefaultRoles)
+	if provider.config.RoleClaim != "" {
+		for _, value := range claims.Strings(provider.config.RoleClaim) {
+			if role, ok := provider.config.RoleMapping[value]; ok && !slices.Contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// An error response from the provider's token endpoint, e.g. for a code which
+// has already been used.
+type ExchangeError struct {
+	Code        string
+	Description string
+}
+
+func (err *ExchangeError) Error() string {
+	if err.Description != "" {
+		return fmt.Sprintf("provider refused code exchange: %s: %s", err.Code, err.Description)
+	}
+	return fmt.Sprintf("provider refused code exchange: %s", err.Code)
+}
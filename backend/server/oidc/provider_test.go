@@ -0,0 +1,231 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/oidc"
+	"github.com/carsonalh/churchmanagerbackend/server/oidc/oidctest"
+)
+
+func TestProviderLogin(t *testing.T) {
+	standIn, err := oidctest.NewProvider("church-manager", "client secret")
+	if err != nil {
+		t.Fatalf("error starting OIDC stand-in: %v", err)
+	}
+	defer standIn.Close()
+
+	provider := oidc.NewProvider(oidc.ProviderConfig{
+		Name:         "test",
+		Issuer:       standIn.Issuer(),
+		ClientId:     "church-manager",
+		ClientSecret: "client secret",
+		RedirectURL:  "http://localhost:3000/login/callback",
+		RoleClaim:    "groups",
+		RoleMapping: map[string]domain.Role{
+			"staff":    domain.RoleOfficeAdmin,
+			"pastoral": domain.RolePastor,
+		},
+		DefaultRoles: []domain.Role{domain.RoleWelcomeTeam},
+	}, nil)
+
+	standIn.SetUser(map[string]any{
+		"sub":    "248289761001",
+		"email":  "jane.doe@example.org",
+		"groups": []string{"pastoral", "choir"},
+	})
+
+	// Starts a login, giving the code and state the stand-in redirects back
+	// with.
+	login := func(verifier string, nonce string) (string, string) {
+		authorizationURL, err := provider.AuthorizationURL(context.Background(), "the state", nonce, verifier)
+		if err != nil {
+			t.Fatalf("error getting authorization URL: %v", err)
+		}
+		parsed, _ := url.Parse(authorizationURL)
+		if parsed.Query().Get("code_challenge") != oidc.CodeChallengeS256(verifier) {
+			t.Errorf("expected the authorization URL to carry the S256 code challenge, got %s", authorizationURL)
+		}
+		code, state, err := standIn.Login(authorizationURL)
+		if err != nil {
+			t.Fatalf("error logging in at the stand-in: %v", err)
+		}
+		if state != "the state" {
+			t.Errorf("expected the state to be passed back, got %q", state)
+		}
+		return code, state
+	}
+
+	t.Run("Successful login", func(t *testing.T) {
+		verifier, _ := oidc.GenerateRandomValue()
+		code, _ := login(verifier, "nonce")
+
+		claims, err := provider.Exchange(context.Background(), code, verifier, "nonce")
+		if err != nil {
+			t.Fatalf("error exchanging code: %v", err)
+		}
+		if claims.Subject != "248289761001" || claims.Email != "jane.doe@example.org" {
+			t.Errorf("expected the user's claims, got %v", claims)
+		}
+
+		roles := provider.Roles(claims)
+		if len(roles) != 2 || !slices.Contains(roles, domain.RoleWelcomeTeam) || !slices.Contains(roles, domain.RolePastor) {
+			t.Errorf("expected the default role and the mapped pastoral group, got %v", roles)
+		}
+
+		if _, err = provider.Exchange(context.Background(), code, verifier, "nonce"); err == nil {
+			t.Error("expected a code not to be usable twice")
+		}
+	})
+
+	t.Run("Wrong code verifier", func(t *testing.T) {
+		verifier, _ := oidc.GenerateRandomValue()
+		code, _ := login(verifier, "nonce")
+
+		var exchangeErr *oidc.ExchangeError
+		_, err := provider.Exchange(context.Background(), code, verifier+"x", "nonce")
+		if !errors.As(err, &exchangeErr) || exchangeErr.Code != "invalid_grant" {
+			t.Errorf("expected the provider to refuse a wrong code verifier, got %v", err)
+		}
+	})
+
+	t.Run("Wrong nonce", func(t *testing.T) {
+		verifier, _ := oidc.GenerateRandomValue()
+		code, _ := login(verifier, "nonce")
+
+		if _, err := provider.Exchange(context.Background(), code, verifier, "another nonce"); err == nil {
+			t.Error("expected an ID token for another login to be rejected")
+		}
+	})
+
+	t.Run("Rotated key", func(t *testing.T) {
+		defer oidc.SetKeyRefetchInterval(0)()
+		if err := standIn.RotateKey(); err != nil {
+			t.Fatalf("error rotating key: %v", err)
+		}
+		verifier, _ := oidc.GenerateRandomValue()
+		code, _ := login(verifier, "nonce")
+
+		if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err != nil {
+			t.Errorf("expected a token signed with a new key to be accepted, got %v", err)
+		}
+	})
+
+	t.Run("Keys are not fetched again too soon", func(t *testing.T) {
+		if err := standIn.RotateKey(); err != nil {
+			t.Fatalf("error rotating key: %v", err)
+		}
+		fetches := standIn.KeyFetches()
+		for range 3 {
+			verifier, _ := oidc.GenerateRandomValue()
+			code, _ := login(verifier, "nonce")
+			if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err == nil {
+				t.Error("expected a token signed with a key rotated in since the keys were fetched to be rejected")
+			}
+		}
+		if standIn.KeyFetches() != fetches {
+			t.Errorf("expected the keys not to be fetched again, but they were fetched %d times", standIn.KeyFetches()-fetches)
+		}
+	})
+
+	t.Run("Small key", func(t *testing.T) {
+		defer oidc.SetKeyRefetchInterval(0)()
+		if err := standIn.RotateKeyOfSize(1024); err != nil {
+			t.Fatalf("error rotating key: %v", err)
+		}
+		verifier, _ := oidc.GenerateRandomValue()
+		code, _ := login(verifier, "nonce")
+
+		if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err == nil {
+			t.Error("expected a token signed with a 1024 bit key to be rejected")
+		}
+		if err := standIn.RotateKey(); err != nil {
+			t.Fatalf("error rotating key: %v", err)
+		}
+	})
+
+	t.Run("Revoked key", func(t *testing.T) {
+		defer oidc.SetKeyRefetchInterval(0)()
+		standIn.SetKeyCacheControl("public, max-age=0")
+		defer standIn.SetKeyCacheControl("")
+		if err := standIn.RotateKey(); err != nil {
+			t.Fatalf("error rotating key: %v", err)
+		}
+		verifier, _ := oidc.GenerateRandomValue()
+		code, _ := login(verifier, "nonce")
+		if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err != nil {
+			t.Fatalf("expected a token signed with a new key to be accepted, got %v", err)
+		}
+
+		standIn.RevokeKey()
+		verifier, _ = oidc.GenerateRandomValue()
+		code, _ = login(verifier, "nonce")
+		if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err == nil {
+			t.Error("expected a token signed with a key the provider no longer serves to be rejected once the keys expire")
+		}
+	})
+
+	t.Run("Keys expire without a max-age", func(t *testing.T) {
+		defer oidc.SetKeyRefetchInterval(0)()
+		defer oidc.SetKeyMaxAge(0)()
+		if err := standIn.RotateKey(); err != nil {
+			t.Fatalf("error rotating key: %v", err)
+		}
+		verifier, _ := oidc.GenerateRandomValue()
+		code, _ := login(verifier, "nonce")
+		if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err != nil {
+			t.Fatalf("expected a token signed with a new key to be accepted, got %v", err)
+		}
+
+		standIn.RevokeKey()
+		verifier, _ = oidc.GenerateRandomValue()
+		code, _ = login(verifier, "nonce")
+		if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err == nil {
+			t.Error("expected a token signed with a revoked key to be rejected once the keys expire")
+		}
+		if err := standIn.RotateKey(); err != nil {
+			t.Fatalf("error rotating key: %v", err)
+		}
+	})
+
+	t.Run("Wrong client", func(t *testing.T) {
+		other := oidc.NewProvider(oidc.ProviderConfig{
+			Name:         "other",
+			Issuer:       standIn.Issuer(),
+			ClientId:     "church-manager",
+			ClientSecret: "wrong secret",
+			RedirectURL:  "http://localhost:3000/login/callback",
+		}, nil)
+		verifier, _ := oidc.GenerateRandomValue()
+		code, _ := login(verifier, "nonce")
+
+		if _, err := other.Exchange(context.Background(), code, verifier, "nonce"); err == nil {
+			t.Error("expected a client with the wrong secret to be refused")
+		}
+	})
+}
+
+func TestUnreachableProvider(t *testing.T) {
+	standIn, err := oidctest.NewProvider("church-manager", "client secret")
+	if err != nil {
+		t.Fatalf("error starting OIDC stand-in: %v", err)
+	}
+	standIn.Close()
+
+	provider := oidc.NewProvider(oidc.ProviderConfig{
+		Name:         "test",
+		Issuer:       standIn.Issuer(),
+		ClientId:     "church-manager",
+		ClientSecret: "client secret",
+		RedirectURL:  "http://localhost:3000/login/callback",
+	}, nil)
+
+	var urlErr *url.Error
+	if _, err = provider.Exchange(context.Background(), "code", "verifier", "nonce"); !errors.As(err, &urlErr) {
+		t.Errorf("expected a provider which cannot be reached to give a *url.Error, got %v", err)
+	}
+}
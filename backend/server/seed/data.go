@@ -0,0 +1,82 @@
+package seed
+
+import (
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+// How many people live in a household, as often as they are drawn.
+var householdSizes = []int{1, 1, 1, 2, 2, 2, 2, 3, 3, 4, 4, 5, 6}
+
+var firstNames = []string{
+	"Olivia", "Charlotte", "Amelia", "Isla", "Mia", "Ava", "Grace", "Chloe", "Sophie", "Emily", "Ruby", "Zoe",
+	"Hannah", "Lily", "Ella", "Matilda", "Harper", "Evie", "Lucy", "Sarah", "Ruth", "Esther", "Naomi", "Abigail",
+	"Priya", "Mei", "Ngozi", "Fatima", "Maria", "Ana",
+	"Oliver", "Noah", "Jack", "William", "Leo", "Lucas", "Thomas", "Henry", "James", "Samuel", "Isaac", "Benjamin",
+	"Daniel", "Joshua", "Elijah", "Nathan", "Caleb", "Luke", "Matthew", "David", "Peter", "Andrew", "Timothy", "John",
+	"Arjun", "Wei", "Chinedu", "Omar", "José", "Minh",
+}
+
+var lastNames = []string{
+	"Smith", "Jones", "Williams", "Brown", "Wilson", "Taylor", "Johnson", "White", "Martin", "Anderson", "Thompson",
+	"Nguyen", "Thomas", "Walker", "Harris", "Lee", "Ryan", "Robinson", "Kelly", "King", "Davis", "Wright", "Evans",
+	"Roberts", "Green", "Hall", "Wood", "Jackson", "Clarke", "Patel", "Khan", "Lewis", "James", "Phillips", "Singh",
+	"Mitchell", "Campbell", "Murphy", "O'Brien", "Scott", "Young", "Turner", "Edwards", "Chen", "Wang", "Li", "Okafor",
+	"Garcia", "Rossi", "Papadopoulos", "Tran", "Kim", "Hughes", "Stewart", "Cook", "Morris", "Baker", "Bell",
+	"MacDonald", "Fraser",
+}
+
+var streets = []string{
+	"Church Street", "High Street", "Station Road", "Victoria Street", "King Street", "Queen Street", "George Street",
+	"Park Road", "Mill Lane", "Elm Grove", "Wattle Avenue", "Banksia Crescent", "Hill Street", "River Road",
+	"School Lane", "Chapel Street", "Bridge Road", "Orchard Way", "Acacia Drive", "Grace Close",
+}
+
+// Also the names of the campuses, so a church may have at most as many
+// campuses as there are suburbs.
+var suburbs = []string{
+	"Northcote", "Southbank", "Eastwood", "Westmeadows", "Hillside", "Riverside", "Greenvale", "Fairfield",
+	"Brookfield", "Lakeside", "Oakleigh", "Ashburton",
+}
+
+var emailDomains = []string{"example.com", "example.org", "example.net"}
+
+var notes = []string{
+	"Prefers to be called by a nickname",
+	"Happy to help with morning tea",
+	"Plays the piano",
+	"Has asked about baptism",
+	"New to the area",
+	"Visiting from overseas this year",
+	"Leads a small group on Tuesdays",
+	"Asked not to be contacted by phone",
+}
+
+// A kind of service and when it is held.
+type scheduleKind struct {
+	// Days after the first Sunday of the schedules the first service is on
+	day int
+	// The time of day the services begin
+	time                time.Duration
+	repeatInterval      *domain.ScheduleCreateDTORepeatInterval
+	repeatNthDayOfMonth *domain.ScheduleCreateDTORepeatNthDayOfMonth
+}
+
+var scheduleKinds = []scheduleKind{
+	// morning and evening services every Sunday
+	{day: 0, time: 9*time.Hour + 30*time.Minute, repeatInterval: weekly(1)},
+	{day: 0, time: 18 * time.Hour, repeatInterval: weekly(1)},
+	// a prayer meeting every second Wednesday
+	{day: 3, time: 19*time.Hour + 30*time.Minute, repeatInterval: weekly(2)},
+	// a breakfast on the first Saturday of each month
+	{day: 6, time: 8 * time.Hour, repeatNthDayOfMonth: &domain.ScheduleCreateDTORepeatNthDayOfMonth{Day: domain.DaySaturday, N: 1}},
+	// a youth night on the last Friday of each month
+	{day: 19, time: 19 * time.Hour, repeatNthDayOfMonth: &domain.ScheduleCreateDTORepeatNthDayOfMonth{Day: domain.DayFriday, N: -1}},
+	// a midweek service every Thursday
+	{day: 4, time: 10 * time.Hour, repeatInterval: weekly(1)},
+}
+
+func weekly(weeks uint) *domain.ScheduleCreateDTORepeatInterval {
+	return &domain.ScheduleCreateDTORepeatInterval{Count: weeks, Unit: domain.RepeatUnitWeek}
+}
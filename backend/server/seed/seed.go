@@ -0,0 +1,187 @@
+// Package seed fills a church with made up campuses, members and schedules, for
+// demonstrating the API and for tests. The same seed always makes the same
+// data. Members come in households, who share a last name, an address, a home
+// phone number and a campus, as there is no household of its own to put them
+// in. Attendance is not seeded, as there is nowhere to keep it yet.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+// The most members a church may be seeded with.
+const MaxMembers = 100_000
+
+// How many members are created at once.
+const batchSize = 1000
+
+type Config struct {
+	// Chooses the data, which is the same for the same seed
+	Seed      uint64
+	Campuses  uint
+	Members   uint
+	Schedules uint
+}
+
+func DefaultConfig() Config {
+	return Config{Seed: 1, Campuses: 2, Members: 500, Schedules: 6}
+}
+
+func (config *Config) Validate() []error {
+	errs := make([]error, 0)
+	if config.Members > MaxMembers {
+		errs = append(errs, fmt.Errorf("at most %d members may be seeded, got %d", MaxMembers, config.Members))
+	}
+	if config.Campuses > uint(len(suburbs)) {
+		errs = append(errs, fmt.Errorf("at most %d campuses may be seeded, got %d", len(suburbs), config.Campuses))
+	}
+	return errs
+}
+
+// What was created.
+type Result struct {
+	Campuses  []domain.Campus
+	Members   uint
+	Schedules []domain.Schedule
+}
+
+// Fills the tenant with the configured numbers of campuses, members and
+// schedules. Only a church without members or campuses may be seeded, so that
+// seeding twice does not mix two sets of made up people.
+func Seed(ctx context.Context, stores *store.Stores, tenantId uint64, config *Config) (*Result, error) {
+	if errs := config.Validate(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	campusStore := stores.Campuses.ForTenant(tenantId)
+	memberStore := stores.Members.ForTenant(tenantId)
+	scheduleStore := stores.Schedules.ForTenant(tenantId)
+
+	existingCampuses, err := campusStore.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existingMembers, err := memberStore.GetPage(ctx, &domain.MemberFilter{}, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(existingCampuses) > 0 || len(existingMembers) > 0 {
+		return nil, fmt.Errorf("only a church without members or campuses may be seeded")
+	}
+
+	random := rand.New(rand.NewPCG(config.Seed, 0))
+	result := &Result{
+		Campuses:  make([]domain.Campus, 0, config.Campuses),
+		Schedules: make([]domain.Schedule, 0, config.Schedules),
+	}
+
+	for _, name := range suburbs[:config.Campuses] {
+		campus, err := campusStore.Create(ctx, domain.AuditActorSystem, &domain.CampusUpdateDTO{Name: name})
+		if err != nil {
+			return nil, fmt.Errorf("creating campus %s: %v", name, err)
+		}
+		result.Campuses = append(result.Campuses, *campus)
+	}
+
+	batch := make([]domain.MemberUpdateDTO, 0, batchSize)
+	for result.Members+uint(len(batch)) < config.Members {
+		remaining := config.Members - result.Members - uint(len(batch))
+		batch = append(batch, household(random, result.Campuses, remaining)...)
+		if len(batch) >= batchSize || result.Members+uint(len(batch)) == config.Members {
+			if _, err = memberStore.CreateMany(ctx, domain.AuditActorSystem, batch); err != nil {
+				return nil, fmt.Errorf("creating members: %v", err)
+			}
+			result.Members += uint(len(batch))
+			batch = batch[:0]
+		}
+	}
+
+	for i := range config.Schedules {
+		createDto := schedule(i, result.Campuses)
+		created, err := scheduleStore.Create(ctx, domain.AuditActorSystem, createDto)
+		if err != nil {
+			return nil, fmt.Errorf("creating schedule: %v", err)
+		}
+		result.Schedules = append(result.Schedules, *created)
+	}
+
+	return result, nil
+}
+
+// Makes the members of a household of at most the given size. The first one
+// or two are adults with their own email addresses and mobile numbers, and the
+// rest are their children, who have neither.
+func household(random *rand.Rand, campuses []domain.Campus, maxSize uint) []domain.MemberUpdateDTO {
+	size := min(maxSize, uint(householdSizes[random.IntN(len(householdSizes))]))
+	adults := min(size, uint(1+random.IntN(2)))
+
+	lastName := pick(random, lastNames)
+	address := fmt.Sprintf("%d %s\n%s", 1+random.IntN(200), pick(random, streets), pick(random, suburbs))
+	homePhone := phoneNumber(random, "03")
+	var campusId *uint64
+	if len(campuses) > 0 {
+		campusId = util.NewPtr(campuses[random.IntN(len(campuses))].Id())
+	}
+
+	members := make([]domain.MemberUpdateDTO, 0, size)
+	for i := range size {
+		member := domain.MemberUpdateDTO{
+			FirstName:   util.NewPtr(pick(random, firstNames)),
+			LastName:    util.NewPtr(lastName),
+			PhoneNumber: util.NewPtr(homePhone),
+			Address:     util.NewPtr(address),
+			CampusId:    campusId,
+		}
+		if i < adults {
+			member.EmailAddress = util.NewPtr(fmt.Sprintf(
+				"%s.%s%d@%s",
+				strings.ToLower(*member.FirstName),
+				strings.ToLower(strings.ReplaceAll(lastName, "'", "")),
+				random.IntN(100),
+				pick(random, emailDomains),
+			))
+			if random.IntN(4) > 0 {
+				member.PhoneNumber = util.NewPtr(phoneNumber(random, "04"))
+			}
+			if random.IntN(10) == 0 {
+				member.Notes = pick(random, notes)
+			}
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
+// Makes the i-th schedule, going through the kinds of services in turn and
+// spreading them over the campuses.
+func schedule(i uint, campuses []domain.Campus) *domain.ScheduleCreateDTO {
+	kind := scheduleKinds[int(i)%len(scheduleKinds)]
+	// the first Sunday of 2024, so that the schedules do not depend on when
+	// they were seeded
+	firstSunday := time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)
+	createDto := &domain.ScheduleCreateDTO{
+		BeginDate:           util.NewPtr(firstSunday.AddDate(0, 0, kind.day).Add(kind.time)),
+		RepeatInterval:      kind.repeatInterval,
+		RepeatNthDayOfMonth: kind.repeatNthDayOfMonth,
+	}
+	if len(campuses) > 0 {
+		createDto.CampusId = util.NewPtr(campuses[int(i)%len(campuses)].Id())
+	}
+	return createDto
+}
+
+func phoneNumber(random *rand.Rand, prefix string) string {
+	return fmt.Sprintf("%s%08d", prefix, random.IntN(100_000_000))
+}
+
+func pick(random *rand.Rand, values []string) string {
+	return values[random.IntN(len(values))]
+}
@@ -0,0 +1,110 @@
+package seed_test
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/migration"
+	"github.com/carsonalh/churchmanagerbackend/server/seed"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/store/sqlite"
+)
+
+// Opens a new, migrated SQLite database and gives the id of its default tenant.
+func openStores(t *testing.T) (*store.Stores, uint64) {
+	connectionString := "sqlite://" + filepath.Join(t.TempDir(), "churchmanager.db")
+	if _, err := migration.PerformMigration("../../migrations", connectionString); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	stores, err := sqlite.Open(context.Background(), connectionString, 0)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	t.Cleanup(stores.Close)
+
+	tenant, err := stores.Tenants.FindBySlug(context.Background(), "default")
+	if err != nil || tenant == nil {
+		t.Fatalf("failed to find the default tenant: %v", err)
+	}
+	return stores, tenant.Id()
+}
+
+func orEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// Gives a line for each member of the tenant, in order.
+func names(t *testing.T, stores *store.Stores, tenantId uint64) []string {
+	members, err := stores.Members.ForTenant(tenantId).GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get the members: %v", err)
+	}
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, orEmpty(member.FirstName())+" "+orEmpty(member.LastName())+" "+orEmpty(member.EmailAddress()))
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestSeedMakesWhatIsConfigured(t *testing.T) {
+	stores, tenantId := openStores(t)
+	config := seed.Config{Seed: 7, Campuses: 3, Members: 1234, Schedules: 8}
+
+	result, err := seed.Seed(context.Background(), stores, tenantId, &config)
+	if err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	if len(result.Campuses) != 3 || result.Members != 1234 || len(result.Schedules) != 8 {
+		t.Errorf("expected 3 campuses, 1234 members and 8 schedules, got %d, %d and %d",
+			len(result.Campuses), result.Members, len(result.Schedules))
+	}
+	if members := names(t, stores, tenantId); len(members) != 1234 {
+		t.Errorf("expected 1234 members to be stored, got %d", len(members))
+	}
+	schedules, err := stores.Schedules.ForTenant(tenantId).GetAll(context.Background(), &domain.ScheduleFilter{})
+	if err != nil || len(schedules) != 8 {
+		t.Errorf("expected 8 schedules to be stored, got %d, %v", len(schedules), err)
+	}
+
+	if _, err = seed.Seed(context.Background(), stores, tenantId, &config); err == nil {
+		t.Error("expected seeding a church with members again to be refused")
+	}
+}
+
+func TestSeedIsTheSameForTheSameSeed(t *testing.T) {
+	config := seed.Config{Seed: 42, Campuses: 2, Members: 300}
+	first, firstTenant := openStores(t)
+	second, secondTenant := openStores(t)
+	if _, err := seed.Seed(context.Background(), first, firstTenant, &config); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	if _, err := seed.Seed(context.Background(), second, secondTenant, &config); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	if !slices.Equal(names(t, first, firstTenant), names(t, second, secondTenant)) {
+		t.Error("expected the same members from the same seed")
+	}
+
+	config.Seed = 43
+	other, otherTenant := openStores(t)
+	if _, err := seed.Seed(context.Background(), other, otherTenant, &config); err != nil {
+		t.Fatalf("failed to seed: %v", err)
+	}
+	if slices.Equal(names(t, first, firstTenant), names(t, other, otherTenant)) {
+		t.Error("expected different members from a different seed")
+	}
+}
+
+func TestSeedConfigLimits(t *testing.T) {
+	config := seed.Config{Members: seed.MaxMembers + 1, Campuses: 100}
+	if errs := config.Validate(); len(errs) != 2 {
+		t.Errorf("expected too many members and campuses to be errors, got %v", errs)
+	}
+}
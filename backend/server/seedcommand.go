@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/config"
+	"github.com/carsonalh/churchmanagerbackend/server/seed"
+)
+
+// Fills a church with made up campuses, members and schedules, for
+// demonstrating the API.
+func runSeed(args []string) {
+	seedConfig := seed.DefaultConfig()
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	flags.Uint64Var(&seedConfig.Seed, "seed", seedConfig.Seed, "chooses the data, which is the same for the same seed")
+	flags.UintVar(&seedConfig.Members, "members", seedConfig.Members, fmt.Sprintf("the number of members, at most %d", seed.MaxMembers))
+	flags.UintVar(&seedConfig.Campuses, "campuses", seedConfig.Campuses, "the number of campuses")
+	flags.UintVar(&seedConfig.Schedules, "schedules", seedConfig.Schedules, "the number of schedules")
+	tenantSlug := flags.String("tenant", "", "the slug of the church to seed, by default the default tenant")
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		fatal("seed takes no arguments", "arguments", flags.Args())
+	}
+	if errs := seedConfig.Validate(); len(errs) > 0 {
+		fatal("invalid seed", "error", errs[0])
+	}
+
+	config, err := config.Load(os.LookupEnv)
+	if err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	if *tenantSlug == "" {
+		*tenantSlug = config.DefaultTenant
+	}
+	if *tenantSlug == "" {
+		fatal("there is no default tenant, so seed needs -tenant")
+	}
+
+	if _, err = checkMigrated(config.Database.Migrations, config.Database.URL); err != nil {
+		fatal("the database is not migrated", "error", err)
+	}
+
+	ctx := context.Background()
+	stores, err := openStores(ctx, config.Database.URL, time.Duration(config.Database.QueryTimeout))
+	if err != nil {
+		fatal("failed to connect to database", "error", err)
+	}
+	defer stores.Close()
+
+	tenant, err := stores.Tenants.FindBySlug(ctx, *tenantSlug)
+	if err != nil {
+		fatal("failed to find tenant", "tenant", *tenantSlug, "error", err)
+	}
+	if tenant == nil {
+		fatal("there is no such tenant", "tenant", *tenantSlug)
+	}
+
+	result, err := seed.Seed(ctx, stores, tenant.Id(), &seedConfig)
+	if err != nil {
+		fatal("failed to seed", "tenant", *tenantSlug, "error", err)
+	}
+	fmt.Printf(
+		"seeded %s with %d campuses, %d members and %d schedules\n",
+		*tenantSlug, len(result.Campuses), result.Members, len(result.Schedules),
+	)
+}
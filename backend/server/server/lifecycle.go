@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/logging"
+)
+
+type LifecycleConfig struct {
+	ReadHeaderTimeout time.Duration
+	// How long a request, including its body, may take to be read
+	ReadTimeout time.Duration
+	// How long a response may take to be written, counted from the end of the
+	// request's headers
+	WriteTimeout time.Duration
+	// How long a kept alive connection may wait for its next request
+	IdleTimeout time.Duration
+	// How long the server reports it is not ready before it stops accepting
+	// connections, so load balancers can stop sending it requests
+	DrainDelay time.Duration
+	// How long requests in flight are given to finish once the server stops
+	// accepting connections
+	ShutdownTimeout time.Duration
+}
+
+// Serves the handler on the listener until the context is cancelled, marking
+// the server ready once it is accepting connections. Once cancelled, the
+// server is marked not ready, and after the drain delay stops accepting
+// connections and waits for the requests in flight to finish. Returns an
+// error if the server fails, or if the requests in flight do not finish within
+// the shutdown timeout, in which case their connections are closed.
+func Serve(ctx context.Context, listener net.Listener, handler http.Handler, readiness *controller.Readiness, config *LifecycleConfig) error {
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+	readiness.SetReady(true)
+	logging.FromContext(ctx).Info("listening", "listenAddress", listener.Addr().String())
+
+	select {
+	case err := <-served:
+		readiness.SetReady(false)
+		return err
+	case <-ctx.Done():
+	}
+
+	logging.FromContext(ctx).Info("shutting down, draining connections")
+	readiness.SetReady(false)
+	time.Sleep(config.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		httpServer.Close()
+		return fmt.Errorf("requests in flight did not finish in time: %v", err)
+	}
+
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
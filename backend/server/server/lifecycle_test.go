@@ -0,0 +1,105 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/server"
+)
+
+var testLifecycleConfig = server.LifecycleConfig{
+	ReadHeaderTimeout: time.Second,
+	ReadTimeout:       time.Second,
+	WriteTimeout:      5 * time.Second,
+	IdleTimeout:       time.Second,
+	DrainDelay:        50 * time.Millisecond,
+	ShutdownTimeout:   2 * time.Second,
+}
+
+// Serves a handler which responds once released, giving a channel on which a
+// value is sent when a request arrives, and one on which Serve returns.
+func startBlockingServer(t *testing.T, release chan struct{}, config server.LifecycleConfig) (string, *controller.Readiness, chan struct{}, context.CancelFunc, chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	arrived := make(chan struct{}, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	readiness := &controller.Readiness{}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, listener, handler, readiness, &config)
+	}()
+
+	return "http://" + listener.Addr().String(), readiness, arrived, cancel, served
+}
+
+func TestServeDrainsRequestsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	url, readiness, arrived, cancel, served := startBlockingServer(t, release, testLifecycleConfig)
+
+	responded := make(chan error, 1)
+	go func() {
+		response, err := http.Get(url)
+		if err == nil {
+			body, _ := io.ReadAll(response.Body)
+			response.Body.Close()
+			if string(body) != "done" {
+				t.Errorf("expected the request in flight to finish, got %q", body)
+			}
+		}
+		responded <- err
+	}()
+
+	<-arrived
+	if !readiness.Ready() {
+		t.Error("expected the server to be ready while serving")
+	}
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if readiness.Ready() {
+		t.Error("expected the server to stop being ready once shutting down")
+	}
+
+	close(release)
+	if err := <-responded; err != nil {
+		t.Errorf("expected the request in flight to finish, got %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestServeGivesUpAfterShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	config := testLifecycleConfig
+	config.ShutdownTimeout = 50 * time.Millisecond
+	url, _, arrived, cancel, served := startBlockingServer(t, release, config)
+
+	go http.Get(url)
+	<-arrived
+
+	cancel()
+	select {
+	case err := <-served:
+		if err == nil {
+			t.Error("expected an error when requests in flight do not finish in time")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the server to stop after the shutdown timeout")
+	}
+}
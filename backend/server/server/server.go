@@ -1,25 +1,85 @@
 package server
 
 import (
+	"log/slog"
+
 	"github.com/carsonalh/churchmanagerbackend/server/controller"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/metrics"
 	"github.com/carsonalh/churchmanagerbackend/server/store"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ServerConfig struct {
-	Schedules struct{}
-	Members   controller.MemberControllerConfig
+	// What requests are logged with, or the default logger if nil
+	Logger      *slog.Logger
+	Tenants     controller.TenantConfig
+	Schedules   struct{}
+	Members     controller.MemberControllerConfig
+	Audit       controller.AuditControllerConfig
+	Auth        controller.AuthControllerConfig
+	SelfService controller.SelfServiceControllerConfig
+	Health      controller.HealthControllerConfig
+	Metrics     controller.MetricsControllerConfig
 }
 
-func CreateServer(pool *pgxpool.Pool, config ServerConfig) *gin.Engine {
-	router := gin.Default()
+func CreateServer(stores *store.Stores, config ServerConfig) *gin.Engine {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	requests := metrics.NewRequests()
+	router := gin.New()
+	// requests which panic are counted once Recover has responded to them
+	router.Use(controller.LogRequests(logger), controller.CountRequests(requests), controller.Recover())
+
+	// health checks are made without a tenant or a login
+	healthController := controller.SetupHealthController(router, stores.Health, &controller.HealthControllerConfig{
+		Readiness:        config.Health.Readiness,
+		MigrationVersion: config.Health.MigrationVersion,
+	})
+
+	// metrics are scraped for the whole deployment rather than for a tenant
+	controller.SetupMetricsController(router, stores.Tenants, stores.Members, stores.Schedules, stores.Health, &controller.MetricsControllerConfig{
+		Requests: requests,
+		Token:    config.Metrics.Token,
+		Database: config.Metrics.Database,
+	})
+
+	// every request is for the tenant the middleware finds, and only sees its rows
+	tenanted := router.Group("", controller.ResolveTenant(stores.Tenants, &controller.TenantConfig{
+		BaseDomain:    config.Tenants.BaseDomain,
+		DefaultTenant: config.Tenants.DefaultTenant,
+	}))
 
-	controller.SetupScheduleHandler(router.Group("/schedules"), store.CreateScheduleStore(pool))
-	controller.SetupMemberController(router.Group("/members"), store.CreateMemberStore(pool), &controller.MemberControllerConfig{
+	controller.SetupAuthController(tenanted.Group("/auth"), stores.Users, stores.Sessions, stores.OIDCLogins, &controller.AuthControllerConfig{
+		SessionDuration: config.Auth.SessionDuration,
+		OIDCProviders:   config.Auth.OIDCProviders,
+	})
+
+	// everything else requires a logged in user or an API key
+	authenticated := tenanted.Group("", controller.RequireAuthentication(stores.Sessions, stores.APIKeys))
+	// users limited to some campuses could see or reach beyond them through these
+	everyCampus := authenticated.Group("", controller.RequireEveryCampus())
+
+	controller.SetupUserController(everyCampus.Group("/users"), stores.Users)
+	controller.SetupAPIKeyController(everyCampus.Group("/admin/api-keys"), stores.APIKeys)
+	everyCampus.GET("/admin/migrations", controller.RequirePermission(domain.PermissionMigrationsRead), healthController.GetMigrations)
+	controller.SetupCampusController(authenticated.Group("/campuses"), stores.Campuses)
+	controller.SetupScheduleHandler(authenticated.Group("/schedules"), stores.Schedules)
+	memberController := controller.SetupMemberController(authenticated.Group("/members"), stores.Members, stores.Audit, &controller.MemberControllerConfig{
 		DefaultPageSize: config.Members.DefaultPageSize,
 		MaxPageSize:     config.Members.MaxPageSize,
 	})
+	authenticated.GET("/members.vcf", controller.RequirePermission(domain.PermissionMembersRead), memberController.GetMembersVCard)
+	controller.SetupSelfServiceController(authenticated.Group("/me"), stores.Members, stores.MemberChanges, &controller.SelfServiceControllerConfig{
+		RequireApproval: config.SelfService.RequireApproval,
+	})
+	controller.SetupMemberChangeController(everyCampus.Group("/member-changes"), stores.MemberChanges)
+	controller.SetupAuditController(everyCampus.Group("/audit"), stores.Audit, &controller.AuditControllerConfig{
+		DefaultPageSize: config.Audit.DefaultPageSize,
+		MaxPageSize:     config.Audit.MaxPageSize,
+	})
 
 	return router
 }
@@ -0,0 +1,268 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Every API key starts with this, so that they are easily told apart from
+// session tokens and found by secret scanners.
+const APIKeyPrefix = "cmk_"
+
+// How many characters of a key are kept to show, including APIKeyPrefix.
+const APIKeyDisplayLength = len(APIKeyPrefix) + 8
+
+// How stale the last used time of a key may be, so that not every request
+// writes to the database.
+const APIKeyLastUsedResolution = time.Minute
+
+type APIKeyStore struct {
+	pool *pgxpool.Pool
+	tenantScope
+}
+
+func CreateAPIKeyStore(pool *pgxpool.Pool) *APIKeyStore {
+	return &APIKeyStore{pool: pool}
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *APIKeyStore) ForTenant(tenantId uint64) APIKeyRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the api_key table in the order expected by scanAPIKeyRow.
+const apiKeyColumns = "id, name, prefix, scopes, created_by, created_at, expires_at, last_used_at, revoked_at"
+
+func scanAPIKeyRow(row pgx.Row) (*domain.APIKeyRow, error) {
+	var keyRow domain.APIKeyRow
+	err := row.Scan(
+		&keyRow.Id,
+		&keyRow.Name,
+		&keyRow.Prefix,
+		&keyRow.Scopes,
+		&keyRow.CreatedBy,
+		&keyRow.CreatedAt,
+		&keyRow.ExpiresAt,
+		&keyRow.LastUsedAt,
+		&keyRow.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &keyRow, nil
+}
+
+func apiKeyAuditSnapshot(key *domain.APIKey) (domain.AuditSnapshot, error) {
+	return domain.NewAuditSnapshot(key.ToResponseDTO())
+}
+
+// Creates a key for the user, returning the key itself, which is not stored
+// and cannot be found again.
+func (store *APIKeyStore) Create(ctx context.Context, actor string, userId uint64, createDto *domain.APIKeyCreateDTO) (string, *domain.APIKey, error) {
+	token, _, err := auth.GenerateToken()
+	if err != nil {
+		return "", nil, err
+	}
+	secret := APIKeyPrefix + token
+
+	scopes := make([]string, len(createDto.Scopes))
+	for i, scope := range createDto.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	var expiresAt *time.Time
+	if createDto.ExpiresAt != nil {
+		utc := createDto.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	row, err := scanAPIKeyRow(tx.QueryRow(
+		ctx,
+		"INSERT INTO api_key (name, prefix, key_hash, scopes, created_by, created_at, expires_at)\n"+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7)\n"+
+			"RETURNING "+apiKeyColumns+";",
+		createDto.Name, secret[:APIKeyDisplayLength], auth.HashToken(secret), scopes, userId, time.Now().UTC(), expiresAt,
+	))
+	if err != nil {
+		return "", nil, err
+	}
+	key, err := row.ToAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	snapshot, err := apiKeyAuditSnapshot(key)
+	if err != nil {
+		return "", nil, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityAPIKey, key.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", nil, err
+	}
+
+	return secret, key, nil
+}
+
+// Finds the unexpired, unrevoked key, recording that it was used. The key is
+// limited to the scopes its creator still has permission for. Returns nil if
+// there is no such key, or if its creator has been deleted.
+func (store *APIKeyStore) FindByKey(ctx context.Context, secret string) (*domain.APIKey, error) {
+	now := time.Now().UTC()
+
+	row, err := scanAPIKeyRow(store.pool.QueryRow(
+		store.ctx(ctx),
+		"SELECT "+apiKeyColumns+" FROM api_key\n"+
+			"WHERE key_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2);",
+		auth.HashToken(secret), now,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+
+	if row.LastUsedAt == nil || now.Sub(*row.LastUsedAt) >= APIKeyLastUsedResolution {
+		_, err = store.pool.Exec(
+			store.ctx(ctx),
+			"UPDATE api_key SET last_used_at = $2 WHERE id = $1;",
+			row.Id, now,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("recording use of API key %d: %v", row.Id, err)
+		}
+		row.LastUsedAt = &now
+	}
+
+	if row.CreatedBy == nil {
+		return nil, nil
+	}
+	creatorRow, err := scanUserRow(store.pool.QueryRow(
+		store.ctx(ctx),
+		"SELECT "+userColumns+" FROM app_user WHERE id = $1;",
+		*row.CreatedBy,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	creator, err := creatorRow.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := row.ToAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	return key.LimitedTo(creator), nil
+}
+
+// Gives every key, including revoked and expired keys, most recent first.
+func (store *APIKeyStore) GetAll(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT "+apiKeyColumns+" FROM api_key ORDER BY id DESC;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]domain.APIKey, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanAPIKeyRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		key, err := row.ToAPIKey()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to API key at row %d: %v", i, err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Stops a key from working. Revoking a revoked key changes nothing. Returns
+// false if there is no such key.
+func (store *APIKeyStore) Revoke(ctx context.Context, actor string, id uint64) (bool, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(context.Background())
+
+	beforeRow, err := scanAPIKeyRow(tx.QueryRow(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_key WHERE id = $1 FOR UPDATE;",
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if beforeRow.RevokedAt != nil {
+		return true, nil
+	}
+
+	afterRow, err := scanAPIKeyRow(tx.QueryRow(
+		ctx,
+		"UPDATE api_key SET revoked_at = $2 WHERE id = $1\n"+
+			"RETURNING "+apiKeyColumns+";",
+		id, time.Now().UTC(),
+	))
+	if err != nil {
+		return false, err
+	}
+
+	before, err := beforeRow.ToAPIKey()
+	if err != nil {
+		return false, err
+	}
+	after, err := afterRow.ToAPIKey()
+	if err != nil {
+		return false, err
+	}
+	beforeSnapshot, err := apiKeyAuditSnapshot(before)
+	if err != nil {
+		return false, err
+	}
+	afterSnapshot, err := apiKeyAuditSnapshot(after)
+	if err != nil {
+		return false, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityAPIKey, id, domain.AuditActionDelete, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
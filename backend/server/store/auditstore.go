@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditStore struct {
+	pool *pgxpool.Pool
+	tenantScope
+}
+
+func CreateAuditStore(pool *pgxpool.Pool) *AuditStore {
+	return &AuditStore{pool: pool}
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *AuditStore) ForTenant(tenantId uint64) AuditRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// Encodes a snapshot for a JSONB column, leaving nil snapshots as NULL.
+func encodeAuditSnapshot(snapshot domain.AuditSnapshot) (any, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	return json.Marshal(snapshot)
+}
+
+// Records a change to an entity in the audit log as part of the transaction
+// making that change, so that a change is never made without being recorded.
+func recordAudit(
+	ctx context.Context,
+	tx pgx.Tx,
+	entityType domain.AuditEntityType,
+	entityId uint64,
+	action domain.AuditAction,
+	actor string,
+	before domain.AuditSnapshot,
+	after domain.AuditSnapshot,
+) error {
+	encodedBefore, err := encodeAuditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	encodedAfter, err := encodeAuditSnapshot(after)
+	if err != nil {
+		return err
+	}
+	diff, err := json.Marshal(domain.DiffAuditSnapshots(before, after))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"INSERT INTO audit_log (entity_type, entity_id, action, actor, occurred_at, before, after, diff)\n"+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8);",
+		entityType, entityId, action, actor, time.Now().UTC(), encodedBefore, encodedAfter, diff,
+	)
+	if err != nil {
+		return fmt.Errorf("recording %s of %s %d in audit log: %v", action, entityType, entityId, err)
+	}
+	return nil
+}
+
+// Gets a page of the audit log entries matching the filter, most recent first.
+func (store *AuditStore) GetPage(ctx context.Context, filter *domain.AuditFilter, pageSize uint, page uint) ([]domain.AuditEntry, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.EntityType != nil {
+		addCondition("entity_type = $%d", *filter.EntityType)
+	}
+	if filter.EntityId != nil {
+		addCondition("entity_id = $%d", *filter.EntityId)
+	}
+	if filter.Action != nil {
+		addCondition("action = $%d", *filter.Action)
+	}
+	if filter.Actor != nil {
+		addCondition("actor = $%d", *filter.Actor)
+	}
+	if filter.Since != nil {
+		addCondition("occurred_at >= $%d", filter.Since.UTC())
+	}
+	if filter.Until != nil {
+		addCondition("occurred_at < $%d", filter.Until.UTC())
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ") + "\n"
+	}
+
+	args = append(args, page*pageSize, pageSize)
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT id, entity_type, entity_id, action, actor, occurred_at, before, after, diff FROM audit_log\n"+
+			where+
+			fmt.Sprintf("ORDER BY occurred_at DESC, id DESC OFFSET $%d LIMIT $%d;", len(args)-1, len(args)),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0)
+	i := 0
+	for rows.Next() {
+		var row domain.AuditEntryRow
+		err = rows.Scan(
+			&row.Id, &row.EntityType, &row.EntityId, &row.Action, &row.Actor, &row.OccurredAt,
+			&row.Before, &row.After, &row.Diff,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		entry, err := row.ToAuditEntry()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to audit entry at row %d: %v", i, err)
+		}
+		entries = append(entries, *entry)
+		i += 1
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNotEmpty = errors.New("database has rows other than tenants")
+
+// Inserts a row of a table, given as a JSON object of the columns.
+type RowInserter func(table string, columns []string, row json.RawMessage) error
+
+// Reads and writes the rows of every tenant at once, for backing up and
+// restoring the whole database. The row-level security policies only let a
+// query see the rows of a single tenant, so the queries are run for each
+// tenant in turn.
+type BackupStore struct {
+	pool *pgxpool.Pool
+}
+
+func CreateBackupStore(pool *pgxpool.Pool) *BackupStore {
+	return &BackupStore{pool}
+}
+
+// The columns as a list for a query.
+func columnList(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = pgx.Identifier{column}.Sanitize()
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Sets the tenant the rest of the transaction's queries are run for, or no
+// tenant for 0.
+func setTransactionTenant(ctx context.Context, tx pgx.Tx, tenantId uint64) error {
+	tenant := ""
+	if tenantId != 0 {
+		tenant = strconv.FormatUint(tenantId, 10)
+	}
+	_, err := tx.Exec(ctx, "SELECT set_config($1, $2, true);", tenantSetting, tenant)
+	return err
+}
+
+func tenantIds(ctx context.Context, tx pgx.Tx) ([]uint64, error) {
+	rows, err := tx.Query(ctx, "SELECT id FROM tenant ORDER BY id;")
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[uint64])
+}
+
+// Calls fn with each row of the tables as a JSON object of the table's
+// columns, in the order the tables are given and then by tenant and by the
+// first column, which is each table's key. The rows are read in a single
+// transaction, so that they are as they were at a single moment.
+func (store *BackupStore) Export(ctx context.Context, tables []SchemaTable, fn func(table string, row json.RawMessage) error) error {
+	tx, err := store.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	tenants, err := tenantIds(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		query := "SELECT to_jsonb(exported) FROM (\n" +
+			"SELECT " + columnList(table.Columns) + " FROM " + pgx.Identifier{table.Name}.Sanitize() + "\n" +
+			"ORDER BY " + pgx.Identifier{table.Columns[0]}.Sanitize() + "\n" +
+			") AS exported;"
+		// tenants are not themselves of a tenant
+		scopes := tenants
+		if table.Name == "tenant" {
+			scopes = []uint64{0}
+		}
+
+		for _, tenantId := range scopes {
+			if err = setTransactionTenant(ctx, tx, tenantId); err != nil {
+				return err
+			}
+			rows, err := tx.Query(ctx, query)
+			if err != nil {
+				return err
+			}
+			for rows.Next() {
+				var row []byte
+				if err = rows.Scan(&row); err != nil {
+					rows.Close()
+					return err
+				}
+				if err = fn(table.Name, row); err != nil {
+					rows.Close()
+					return err
+				}
+			}
+			rows.Close()
+			if err = rows.Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Replaces the tenants of a database which has no other rows, such as one
+// which has only been migrated, with the rows insertAll inserts, keeping their
+// ids. Everything is restored in a single transaction, so that nothing is if
+// any row cannot be. Returns ErrNotEmpty if the database has rows other than
+// tenants.
+func (store *BackupStore) Restore(ctx context.Context, insertAll func(insert RowInserter) error) error {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	tenants, err := tenantIds(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, tenantId := range tenants {
+		if err = setTransactionTenant(ctx, tx, tenantId); err != nil {
+			return err
+		}
+		for _, table := range Schema {
+			if table.Name == "tenant" {
+				continue
+			}
+			var exists bool
+			err = tx.QueryRow(
+				ctx,
+				"SELECT EXISTS (SELECT 1 FROM "+pgx.Identifier{table.Name}.Sanitize()+");",
+			).Scan(&exists)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return ErrNotEmpty
+			}
+		}
+	}
+
+	if _, err = tx.Exec(ctx, "DELETE FROM tenant;"); err != nil {
+		return err
+	}
+
+	// the largest id inserted into each table, which its sequence is set to
+	// once every row is
+	maxIds := make(map[string]uint64)
+	currentTenant := uint64(0)
+	err = insertAll(func(table string, columns []string, row json.RawMessage) error {
+		var keys struct {
+			Id       *uint64 `json:"id"`
+			TenantId *uint64 `json:"tenant_id"`
+		}
+		if err := json.Unmarshal(row, &keys); err != nil {
+			return err
+		}
+		if keys.TenantId != nil && *keys.TenantId != currentTenant {
+			if err := setTransactionTenant(ctx, tx, *keys.TenantId); err != nil {
+				return err
+			}
+			currentTenant = *keys.TenantId
+		}
+
+		quotedTable := pgx.Identifier{table}.Sanitize()
+		_, err := tx.Exec(
+			ctx,
+			"INSERT INTO "+quotedTable+" ("+columnList(columns)+")\n"+
+				"SELECT "+columnList(columns)+" FROM jsonb_populate_record(NULL::"+quotedTable+", $1::jsonb);",
+			[]byte(row),
+		)
+		if err != nil {
+			return err
+		}
+		if keys.Id != nil {
+			maxIds[table] = max(maxIds[table], *keys.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for table, maxId := range maxIds {
+		_, err = tx.Exec(ctx, "SELECT setval(pg_get_serial_sequence($1, 'id'), $2);", table, maxId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,252 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrCampusNameTaken = errors.New("campus name is already taken")
+
+var ErrCampusNotFound = errors.New("campus does not exist")
+
+type CampusStore struct {
+	pool *pgxpool.Pool
+	tenantScope
+}
+
+func CreateCampusStore(pool *pgxpool.Pool) *CampusStore {
+	return &CampusStore{pool: pool}
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *CampusStore) ForTenant(tenantId uint64) CampusRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the campus table in the order expected by scanCampusRow.
+const campusColumns = "id, name, created_at"
+
+func scanCampusRow(row pgx.Row) (*domain.CampusRow, error) {
+	var campusRow domain.CampusRow
+	err := row.Scan(
+		&campusRow.Id,
+		&campusRow.Name,
+		&campusRow.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &campusRow, nil
+}
+
+func campusAuditSnapshot(campus *domain.Campus) (domain.AuditSnapshot, error) {
+	return domain.NewAuditSnapshot(campus.ToResponseDTO())
+}
+
+// Checks that a campus exists, for placing something at it. Returns
+// ErrCampusNotFound if it does not.
+func checkCampusExists(ctx context.Context, tx pgx.Tx, campusId uint64) error {
+	var exists bool
+	err := tx.QueryRow(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM campus WHERE id = $1);",
+		campusId,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrCampusNotFound
+	}
+	return nil
+}
+
+// Returns ErrCampusNameTaken if there is already a campus with the name.
+func (store *CampusStore) Create(ctx context.Context, actor string, createDto *domain.CampusUpdateDTO) (*domain.Campus, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	row, err := scanCampusRow(tx.QueryRow(
+		ctx,
+		"INSERT INTO campus (name, created_at)\n"+
+			"VALUES ($1, $2)\n"+
+			"ON CONFLICT (tenant_id, name) DO NOTHING\n"+
+			"RETURNING "+campusColumns+";",
+		createDto.Name, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCampusNameTaken
+		}
+		return nil, err
+	}
+	campus, err := row.ToCampus()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := campusAuditSnapshot(campus)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityCampus, campus.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return campus, nil
+}
+
+// Returns nil if there is no such campus, or ErrCampusNameTaken if another
+// campus already has the name.
+func (store *CampusStore) Rename(ctx context.Context, actor string, id uint64, updateDto *domain.CampusUpdateDTO) (*domain.Campus, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	before, err := findCampusForUpdate(ctx, tx, id)
+	if err != nil || before == nil {
+		return nil, err
+	}
+
+	var taken bool
+	err = tx.QueryRow(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM campus WHERE name = $1 AND id <> $2);",
+		updateDto.Name, id,
+	).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrCampusNameTaken
+	}
+
+	row, err := scanCampusRow(tx.QueryRow(
+		ctx,
+		"UPDATE campus SET name = $2 WHERE id = $1\n"+
+			"RETURNING "+campusColumns+";",
+		id, updateDto.Name,
+	))
+	if err != nil {
+		return nil, err
+	}
+	after, err := row.ToCampus()
+	if err != nil {
+		return nil, err
+	}
+
+	beforeSnapshot, err := campusAuditSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	afterSnapshot, err := campusAuditSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityCampus, id, domain.AuditActionUpdate, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// Removes a campus, leaving its members and schedules at no campus. Users
+// limited to the campus keep its id, rather than being allowed every campus
+// once they are limited to none, and so see nothing of it. Returns false if
+// there is no such campus.
+func (store *CampusStore) DeleteById(ctx context.Context, actor string, id uint64) (bool, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(context.Background())
+
+	campus, err := findCampusForUpdate(ctx, tx, id)
+	if err != nil || campus == nil {
+		return false, err
+	}
+
+	if _, err = tx.Exec(ctx, "DELETE FROM campus WHERE id = $1;", id); err != nil {
+		return false, err
+	}
+
+	snapshot, err := campusAuditSnapshot(campus)
+	if err != nil {
+		return false, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityCampus, id, domain.AuditActionDelete, actor, snapshot, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Returns nil if there is no such campus.
+func findCampusForUpdate(ctx context.Context, tx pgx.Tx, id uint64) (*domain.Campus, error) {
+	row, err := scanCampusRow(tx.QueryRow(
+		ctx,
+		"SELECT "+campusColumns+" FROM campus WHERE id = $1 FOR UPDATE;",
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.ToCampus()
+}
+
+// Gets every campus in order of name.
+func (store *CampusStore) GetAll(ctx context.Context) ([]domain.Campus, error) {
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT "+campusColumns+" FROM campus ORDER BY name, id;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campuses := make([]domain.Campus, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanCampusRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		campus, err := row.ToCampus()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to campus at row %d: %v", i, err)
+		}
+		campuses = append(campuses, *campus)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return campuses, nil
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Checks on the database as a whole, which are not for any tenant.
+type HealthStore struct {
+	pool *pgxpool.Pool
+}
+
+func CreateHealthStore(pool *pgxpool.Pool) *HealthStore {
+	return &HealthStore{pool: pool}
+}
+
+// Checks that a connection to the database can be made and used.
+func (store *HealthStore) Ping(ctx context.Context) error {
+	return store.pool.Ping(ctx)
+}
+
+// Gives how the connections of the pool are used.
+func (store *HealthStore) ConnectionStats() *domain.ConnectionStats {
+	stat := store.pool.Stat()
+	return &domain.ConnectionStats{
+		Acquired:     int64(stat.AcquiredConns()),
+		Idle:         int64(stat.IdleConns()),
+		Max:          int64(stat.MaxConns()),
+		Waits:        stat.EmptyAcquireCount(),
+		WaitDuration: stat.EmptyAcquireWaitTime(),
+	}
+}
+
+// Reads the version of the schema from the table golang-migrate records it in.
+// A database which has never been migrated is at version 0.
+func (store *HealthStore) MigrationStatus(ctx context.Context) (*domain.MigrationStatus, error) {
+	var status domain.MigrationStatus
+	var version int64
+	err := store.pool.QueryRow(
+		ctx,
+		"SELECT version, dirty FROM schema_migrations LIMIT 1;",
+	).Scan(&version, &status.Dirty)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &status, nil
+		}
+		return nil, err
+	}
+	status.Version = uint(version)
+	return &status, nil
+}
+
+// Gives the columns of each table of the database's schema, by the name of the
+// table.
+func (store *HealthStore) Columns(ctx context.Context) (map[string][]string, error) {
+	rows, err := store.pool.Query(
+		ctx,
+		"SELECT table_name, column_name FROM information_schema.columns\n"+
+			"WHERE table_schema = current_schema()\n"+
+			"ORDER BY table_name, ordinal_position;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err = rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+		columns[table] = append(columns[table], column)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
@@ -0,0 +1,221 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MemberChangeStore struct {
+	pool *pgxpool.Pool
+	tenantScope
+}
+
+func CreateMemberChangeStore(pool *pgxpool.Pool) *MemberChangeStore {
+	return &MemberChangeStore{pool: pool}
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *MemberChangeStore) ForTenant(tenantId uint64) MemberChangeRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the member_change table in the order expected by
+// scanMemberChangeRow.
+const memberChangeColumns = "id, member_id, requested_by, email_address, phone_number, status, created_at, decided_at, decided_by"
+
+func scanMemberChangeRow(row pgx.Row) (*domain.MemberChangeRow, error) {
+	var changeRow domain.MemberChangeRow
+	err := row.Scan(
+		&changeRow.Id,
+		&changeRow.MemberId,
+		&changeRow.RequestedBy,
+		&changeRow.EmailAddress,
+		&changeRow.PhoneNumber,
+		&changeRow.Status,
+		&changeRow.CreatedAt,
+		&changeRow.DecidedAt,
+		&changeRow.DecidedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &changeRow, nil
+}
+
+// Queues a change a user made to the member they are for approval. A member
+// has at most one pending change, so a later change is combined with the one
+// already pending, with the later values taking precedence.
+func (store *MemberChangeStore) Create(ctx context.Context, userId uint64, memberId uint64, updateDto *domain.MeUpdateDTO) (*domain.MemberChange, error) {
+	row, err := scanMemberChangeRow(store.pool.QueryRow(
+		store.ctx(ctx),
+		"INSERT INTO member_change (member_id, requested_by, email_address, phone_number, created_at)\n"+
+			"VALUES ($1, $2, $3, $4, $5)\n"+
+			"ON CONFLICT (member_id) WHERE status = 'pending' DO UPDATE SET\n"+
+			"requested_by = EXCLUDED.requested_by,\n"+
+			"email_address = COALESCE(EXCLUDED.email_address, member_change.email_address),\n"+
+			"phone_number = COALESCE(EXCLUDED.phone_number, member_change.phone_number),\n"+
+			"created_at = EXCLUDED.created_at\n"+
+			"RETURNING "+memberChangeColumns+";",
+		memberId, userId, updateDto.EmailAddress, updateDto.PhoneNumber, time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMemberChange()
+}
+
+// Returns nil if the member has no change waiting for approval.
+func (store *MemberChangeStore) FindPending(ctx context.Context, memberId uint64) (*domain.MemberChange, error) {
+	row, err := scanMemberChangeRow(store.pool.QueryRow(
+		store.ctx(ctx),
+		"SELECT "+memberChangeColumns+" FROM member_change WHERE member_id = $1 AND status = 'pending';",
+		memberId,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.ToMemberChange()
+}
+
+// Gets every change waiting for approval, oldest first.
+func (store *MemberChangeStore) GetPending(ctx context.Context) ([]domain.MemberChange, error) {
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT "+memberChangeColumns+" FROM member_change WHERE status = 'pending' ORDER BY created_at, id;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]domain.MemberChange, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanMemberChangeRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		change, err := row.ToMemberChange()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to member change at row %d: %v", i, err)
+		}
+		changes = append(changes, *change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Makes a pending change to its member, recording the update in the audit log
+// against the actor who approved it. Returns nil if there is no pending change
+// with the id, or ErrMemberNotFound if the member has since been moved to the
+// trash.
+func (store *MemberChangeStore) Approve(ctx context.Context, actor string, id uint64) (*domain.MemberChange, *domain.Member, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	change, err := findPendingMemberChangeForUpdate(ctx, tx, id)
+	if err != nil || change == nil {
+		return nil, nil, err
+	}
+
+	before, err := findMemberForUpdate(ctx, tx, change.MemberId())
+	if err != nil {
+		return nil, nil, err
+	}
+	if before == nil || before.DeletedAt() != nil {
+		return nil, nil, ErrMemberNotFound
+	}
+	beforeSnapshot, err := memberAuditSnapshot(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	after, err := updateMember(ctx, tx, before.Id(), change.ApplyTo(before))
+	if err != nil {
+		return nil, nil, err
+	}
+	afterSnapshot, err := memberAuditSnapshot(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityMember, after.Id(), domain.AuditActionUpdate, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if change, err = decideMemberChange(ctx, tx, id, domain.MemberChangeApproved, actor); err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return change, after, nil
+}
+
+// Returns nil if there is no pending change with the id.
+func (store *MemberChangeStore) Reject(ctx context.Context, actor string, id uint64) (*domain.MemberChange, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	change, err := findPendingMemberChangeForUpdate(ctx, tx, id)
+	if err != nil || change == nil {
+		return nil, err
+	}
+
+	if change, err = decideMemberChange(ctx, tx, id, domain.MemberChangeRejected, actor); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Returns nil if there is no pending change with the id.
+func findPendingMemberChangeForUpdate(ctx context.Context, tx pgx.Tx, id uint64) (*domain.MemberChange, error) {
+	row, err := scanMemberChangeRow(tx.QueryRow(
+		ctx,
+		"SELECT "+memberChangeColumns+" FROM member_change WHERE id = $1 AND status = 'pending' FOR UPDATE;",
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.ToMemberChange()
+}
+
+func decideMemberChange(ctx context.Context, tx pgx.Tx, id uint64, status domain.MemberChangeStatus, actor string) (*domain.MemberChange, error) {
+	row, err := scanMemberChangeRow(tx.QueryRow(
+		ctx,
+		"UPDATE member_change SET status = $2, decided_at = $3, decided_by = $4 WHERE id = $1\n"+
+			"RETURNING "+memberChangeColumns+";",
+		id, string(status), time.Now().UTC(), actor,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMemberChange()
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/carsonalh/churchmanagerbackend/server/domain"
 	"github.com/jackc/pgx/v5"
@@ -12,67 +15,367 @@ import (
 
 type MemberStore struct {
 	pool *pgxpool.Pool
+	tenantScope
 }
 
 func CreateMemberStore(pool *pgxpool.Pool) *MemberStore {
-	return &MemberStore{pool}
+	return &MemberStore{pool: pool}
 }
 
-// Ignores member's Id field
-func (store *MemberStore) Create(createDto *domain.MemberUpdateDTO) (*domain.Member, error) {
-	var row domain.MemberRow
-	err := store.pool.QueryRow(
-		context.Background(),
-		"INSERT INTO member (first_name, last_name, email_address, phone_number, notes)\n"+
-			"VALUES ($1, $2, $3, $4, $5)\n"+
-			"RETURNING id, first_name, last_name, email_address, phone_number, notes;",
-		createDto.FirstName, createDto.LastName, createDto.EmailAddress, createDto.PhoneNumber, createDto.Notes).
-		Scan(&row.Id, &row.FirstName, &row.LastName, &row.EmailAddress, &row.PhoneNumber, &row.Notes)
+// Gives the store limited to the rows of the tenant.
+func (store *MemberStore) ForTenant(tenantId uint64) MemberRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the member table in the order expected by scanMemberRow.
+const memberColumns = "id, first_name, last_name, email_address, phone_number, address, campus_id, notes, deleted_at"
+
+func scanMemberRow(row pgx.Row) (*domain.MemberRow, error) {
+	var memberRow domain.MemberRow
+	err := row.Scan(
+		&memberRow.Id,
+		&memberRow.FirstName,
+		&memberRow.LastName,
+		&memberRow.EmailAddress,
+		&memberRow.PhoneNumber,
+		&memberRow.Address,
+		&memberRow.CampusId,
+		&memberRow.Notes,
+		&memberRow.DeletedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
-	member, err := row.ToMember()
-	if err != nil {
+	return &memberRow, nil
+}
+
+func collectMembers(rows pgx.Rows) ([]domain.Member, error) {
+	defer rows.Close()
+	members := make([]domain.Member, 0)
+	i := 0
+	for rows.Next() {
+		row, err := scanMemberRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		member, err := row.ToMember()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to member at row %d: %v", i, err)
+		}
+		members = append(members, *member)
+		i += 1
+	}
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return member, nil
+	return members, nil
+}
+
+func memberAuditSnapshot(member *domain.Member) (domain.AuditSnapshot, error) {
+	return domain.NewAuditSnapshot(member.ToResponseDTO())
 }
 
-func (store *MemberStore) Update(id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
-	row := domain.MemberRow{}
-	err := store.pool.QueryRow(
-		context.Background(),
-		"UPDATE member SET first_name = $1, last_name = $2, email_address = $3, phone_number = $4, notes = $5 WHERE id = $6\n"+
-			"RETURNING id, first_name, last_name, email_address, phone_number, notes;",
-		updateDto.FirstName, updateDto.LastName, updateDto.EmailAddress, updateDto.PhoneNumber, updateDto.Notes,
+// Finds a member regardless of whether it is in the trash, locking its row
+// for the rest of the transaction. Returns nil if there is no such member.
+func findMemberForUpdate(ctx context.Context, tx pgx.Tx, id uint64) (*domain.Member, error) {
+	row, err := scanMemberRow(tx.QueryRow(
+		ctx,
+		"SELECT "+memberColumns+" FROM member WHERE id = $1 FOR UPDATE;",
 		id,
-	).Scan(
-		&row.Id, &row.FirstName, &row.LastName, &row.EmailAddress, &row.PhoneNumber, &row.Notes,
-	)
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+	return row.ToMember()
+}
+
+// Runs a change to a single member in a transaction, recording the change in
+// the audit log. The change is given the member as it was before the change
+// (nil for creations) and returns the member as it is after the change, or nil
+// if it made no change.
+func (store *MemberStore) changeMember(
+	ctx context.Context,
+	id *uint64,
+	action domain.AuditAction,
+	actor string,
+	change func(tx pgx.Tx, before *domain.Member) (*domain.Member, error),
+) (*domain.Member, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
 	if err != nil {
 		return nil, err
 	}
-	member, err := row.ToMember()
+	defer tx.Rollback(context.Background())
+
+	var before *domain.Member
+	var beforeSnapshot domain.AuditSnapshot
+	if id != nil {
+		if before, err = findMemberForUpdate(ctx, tx, *id); err != nil {
+			return nil, err
+		}
+		if before == nil {
+			return nil, nil
+		}
+		if beforeSnapshot, err = memberAuditSnapshot(before); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := change(tx, before)
+	if err != nil || after == nil {
+		return nil, err
+	}
+
+	afterSnapshot, err := memberAuditSnapshot(after)
 	if err != nil {
 		return nil, err
 	}
-	return member, nil
+	err = recordAudit(ctx, tx, domain.AuditEntityMember, after.Id(), action, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return after, nil
 }
 
-func (store *MemberStore) FindById(id uint64) (*domain.Member, error) {
-	var row domain.MemberRow
-	err := store.pool.QueryRow(
-		context.Background(),
-		"SELECT id, first_name, last_name, email_address, phone_number, notes FROM member WHERE id = $1;",
-		id,
-	).Scan(
-		&row.Id,
-		&row.FirstName,
-		&row.LastName,
-		&row.EmailAddress,
-		&row.PhoneNumber,
-		&row.Notes,
+// Returns ErrCampusNotFound if the member's campus does not exist.
+func insertMember(ctx context.Context, tx pgx.Tx, createDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	if createDto.CampusId != nil {
+		if err := checkCampusExists(ctx, tx, *createDto.CampusId); err != nil {
+			return nil, err
+		}
+	}
+	row, err := scanMemberRow(tx.QueryRow(
+		ctx,
+		"INSERT INTO member (first_name, last_name, email_address, phone_number, address, campus_id, notes)\n"+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7)\n"+
+			"RETURNING "+memberColumns+";",
+		createDto.FirstName, createDto.LastName, createDto.EmailAddress, createDto.PhoneNumber, createDto.Address, createDto.CampusId,
+		createDto.Notes))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMember()
+}
+
+// Returns ErrCampusNotFound if the member's campus does not exist.
+func updateMember(ctx context.Context, tx pgx.Tx, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	if updateDto.CampusId != nil {
+		if err := checkCampusExists(ctx, tx, *updateDto.CampusId); err != nil {
+			return nil, err
+		}
+	}
+	row, err := scanMemberRow(tx.QueryRow(
+		ctx,
+		"UPDATE member SET first_name = $1, last_name = $2, email_address = $3, phone_number = $4, address = $5,\n"+
+			"campus_id = $6, notes = $7\n"+
+			"WHERE id = $8\n"+
+			"RETURNING "+memberColumns+";",
+		updateDto.FirstName, updateDto.LastName, updateDto.EmailAddress, updateDto.PhoneNumber, updateDto.Address, updateDto.CampusId,
+		updateDto.Notes, id,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMember()
+}
+
+// Ignores member's Id field. Returns ErrCampusNotFound if the member's campus
+// does not exist.
+func (store *MemberStore) Create(ctx context.Context, actor string, createDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	return store.changeMember(ctx, nil, domain.AuditActionCreate, actor, func(tx pgx.Tx, _ *domain.Member) (*domain.Member, error) {
+		return insertMember(ctx, tx, createDto)
+	})
+}
+
+// Creates every member in a single transaction, so that either all of the
+// members are created or none are. The created members are returned in the
+// same order.
+func (store *MemberStore) CreateMany(ctx context.Context, actor string, createDtos []domain.MemberUpdateDTO) ([]domain.Member, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	members := make([]domain.Member, 0, len(createDtos))
+	for i := range createDtos {
+		member, err := insertMember(ctx, tx, &createDtos[i])
+		if err != nil {
+			return nil, fmt.Errorf("creating member %d: %v", i, err)
+		}
+		snapshot, err := memberAuditSnapshot(member)
+		if err != nil {
+			return nil, err
+		}
+		err = recordAudit(ctx, tx, domain.AuditEntityMember, member.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *member)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// Returns nil if there is no member with the given id, or if that member is in
+// the trash, or ErrCampusNotFound if the member's campus does not exist.
+func (store *MemberStore) Update(ctx context.Context, actor string, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	return store.changeMember(ctx, &id, domain.AuditActionUpdate, actor, func(tx pgx.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() != nil {
+			return nil, nil
+		}
+		return updateMember(ctx, tx, id, updateDto)
+	})
+}
+
+// Updates a member as Update does, but keeps the member's notes, for users who
+// may not write them.
+func (store *MemberStore) UpdateExceptNotes(ctx context.Context, actor string, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	return store.changeMember(ctx, &id, domain.AuditActionUpdate, actor, func(tx pgx.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() != nil {
+			return nil, nil
+		}
+		withNotes := *updateDto
+		withNotes.Notes = before.Notes()
+		return updateMember(ctx, tx, id, &withNotes)
+	})
+}
+
+// Updates the email address and phone number a member has given for
+// themselves, keeping every other field. Returns nil if there is no member
+// with the given id, or if that member is in the trash.
+func (store *MemberStore) UpdateContactDetails(ctx context.Context, actor string, id uint64, updateDto *domain.MeUpdateDTO) (*domain.Member, error) {
+	return store.changeMember(ctx, &id, domain.AuditActionUpdate, actor, func(tx pgx.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() != nil {
+			return nil, nil
+		}
+		return updateMember(ctx, tx, id, updateDto.ApplyTo(before))
+	})
+}
+
+// A column of another table which refers to a member by its id.
+type memberReference struct {
+	table  string
+	column string
+}
+
+// Every reference to a member from another table, which must be re-pointed to
+// the survivor when two members are merged. Tables which refer to members must
+// be added here.
+var memberReferences = []memberReference{
+	{table: "app_user", column: "member_id"},
+	{table: "member_change", column: "member_id"},
+}
+
+// Merges two members into the survivor, taking each field from the member
+// chosen by the merge, re-pointing every reference to the merged member to the
+// survivor and moving the merged member to the trash, all in one transaction.
+// Returns nil if either member does not exist or is in the trash.
+func (store *MemberStore) Merge(ctx context.Context, actor string, mergeDto *domain.MemberMergeDTO) (*domain.Member, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	// always lock in id order so that concurrent merges cannot deadlock
+	ids := []uint64{min(mergeDto.SurvivorId, mergeDto.MergedId), max(mergeDto.SurvivorId, mergeDto.MergedId)}
+	members := make(map[uint64]*domain.Member)
+	snapshots := make(map[uint64]domain.AuditSnapshot)
+	for _, id := range ids {
+		member, err := findMemberForUpdate(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil || member.DeletedAt() != nil {
+			return nil, nil
+		}
+		if snapshots[id], err = memberAuditSnapshot(member); err != nil {
+			return nil, err
+		}
+		members[id] = member
+	}
+	survivor, merged := members[mergeDto.SurvivorId], members[mergeDto.MergedId]
+
+	updateDto := mergeDto.Merge(survivor, merged)
+	if survivor, err = updateMember(ctx, tx, survivor.Id(), updateDto); err != nil {
+		return nil, err
+	}
+
+	// a change the merged member is waiting on was made against details it no
+	// longer has, and the survivor may only have one change waiting
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE member_change SET status = $2, decided_at = $3, decided_by = $4 WHERE member_id = $1 AND status = 'pending';",
+		merged.Id(), string(domain.MemberChangeRejected), time.Now().UTC(), actor,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("rejecting the merged member's pending change: %v", err)
+	}
+
+	for _, reference := range memberReferences {
+		_, err = tx.Exec(
+			ctx,
+			fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2;", reference.table, reference.column, reference.column),
+			survivor.Id(), merged.Id(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("re-pointing %s.%s to the survivor: %v", reference.table, reference.column, err)
+		}
+	}
+
+	row, err := scanMemberRow(tx.QueryRow(
+		ctx,
+		"UPDATE member SET deleted_at = $2 WHERE id = $1\n"+
+			"RETURNING "+memberColumns+";",
+		merged.Id(), time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	if merged, err = row.ToMember(); err != nil {
+		return nil, err
+	}
+
+	for _, member := range []*domain.Member{survivor, merged} {
+		action := domain.AuditActionMerge
+		if member == merged {
+			action = domain.AuditActionDelete
+		}
+		after, err := memberAuditSnapshot(member)
+		if err != nil {
+			return nil, err
+		}
+		if err = recordAudit(ctx, tx, domain.AuditEntityMember, member.Id(), action, actor, snapshots[member.Id()], after); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return survivor, nil
+}
+
+// Members in the trash are not found by this method.
+func (store *MemberStore) FindById(ctx context.Context, id uint64) (*domain.Member, error) {
+	row, err := scanMemberRow(store.pool.QueryRow(
+		store.ctx(ctx),
+		"SELECT "+memberColumns+" FROM member WHERE id = $1 AND deleted_at IS NULL;",
+		id,
+	))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -89,45 +392,201 @@ func (store *MemberStore) FindById(id uint64) (*domain.Member, error) {
 	return member, nil
 }
 
-func (store *MemberStore) GetPage(pageSize uint, page uint) ([]domain.Member, error) {
+// The conditions of a WHERE clause selecting the members matching the filter
+// outside of the trash, and the arguments they refer to.
+func memberFilterConditions(filter *domain.MemberFilter) ([]string, []any) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := make([]any, 0)
+
+	if filter.Search != nil {
+		args = append(args, "%"+EscapeLike(*filter.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf(
+			"(first_name ILIKE $%d OR last_name ILIKE $%d OR email_address ILIKE $%d)", len(args), len(args), len(args)))
+	}
+
+	if len(filter.CampusIds) > 0 {
+		args = append(args, filter.CampusIds)
+		conditions = append(conditions, fmt.Sprintf("campus_id = ANY ($%d)", len(args)))
+	}
+
+	return conditions, args
+}
+
+// Gets a page of the members matching the filter, excluding those in the
+// trash.
+func (store *MemberStore) GetPage(ctx context.Context, filter *domain.MemberFilter, pageSize uint, page uint) ([]domain.Member, error) {
+	conditions, args := memberFilterConditions(filter)
+	args = append(args, page*pageSize, pageSize)
 	rows, err := store.pool.Query(
-		context.Background(),
-		"SELECT id, first_name, last_name, email_address, phone_number, notes FROM member ORDER BY id OFFSET $1 LIMIT $2;",
-		page*pageSize, pageSize)
+		store.ctx(ctx),
+		"SELECT "+memberColumns+" FROM member WHERE "+strings.Join(conditions, " AND ")+
+			fmt.Sprintf(" ORDER BY id OFFSET $%d LIMIT $%d;", len(args)-1, len(args)),
+		args...)
 	if err != nil {
 		return nil, err
 	}
-	members := make([]domain.Member, 0)
-	i := 0
-	for rows.Next() {
-		var row domain.MemberRow
-		err = rows.Scan(&row.Id, &row.FirstName, &row.LastName, &row.EmailAddress, &row.PhoneNumber, &row.Notes)
+	return collectMembers(rows)
+}
+
+// Gets every member, excluding those in the trash.
+func (store *MemberStore) GetAll(ctx context.Context) ([]domain.Member, error) {
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT "+memberColumns+" FROM member WHERE deleted_at IS NULL ORDER BY id;")
+	if err != nil {
+		return nil, err
+	}
+	return collectMembers(rows)
+}
+
+// Counts the members, excluding those in the trash.
+func (store *MemberStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := store.pool.QueryRow(store.ctx(ctx), "SELECT count(*) FROM member WHERE deleted_at IS NULL;").Scan(&count)
+	return count, err
+}
+
+// The most members Stream reads with a single query.
+const streamBatchSize = 500
+
+// Calls fn with every member matching the filter, excluding those in the
+// trash, in order of id. Members are read from the database in batches as
+// they are needed rather than all at once, so this is suitable for very many
+// members. Each batch is read with a query of its own, which the statement
+// timeout applies to, so that the time fn takes does not count against it.
+// Stops at and returns the first error returned by fn.
+func (store *MemberStore) Stream(ctx context.Context, filter *domain.MemberFilter, fn func(member *domain.Member) error) error {
+	conditions, args := memberFilterConditions(filter)
+	afterId := uint64(0)
+	for {
+		members, err := store.streamBatch(ctx, conditions, args, afterId)
 		if err != nil {
-			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+			return err
 		}
-		member, err := row.ToMember()
-		if err != nil {
-			return nil, fmt.Errorf("converting row to member at row %d: %v", i, err)
+		for i := range members {
+			if err = fn(&members[i]); err != nil {
+				return err
+			}
 		}
-		members = append(members, *member)
-		i += 1
+		if len(members) < streamBatchSize {
+			return nil
+		}
+		afterId = members[len(members)-1].Id()
 	}
-	return members, nil
 }
 
-func (store *MemberStore) DeleteById(id uint64) (bool, error) {
-	rows, err := store.pool.Query(context.Background(), "DELETE FROM member WHERE id = $1;", id)
+// Reads the next batch of the members Stream calls fn with, which follow the
+// member with the id.
+func (store *MemberStore) streamBatch(ctx context.Context, conditions []string, args []any, afterId uint64) ([]domain.Member, error) {
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT "+memberColumns+" FROM member WHERE "+strings.Join(conditions, " AND ")+
+			fmt.Sprintf(" AND id > $%d ORDER BY id LIMIT $%d;", len(args)+1, len(args)+2),
+		slices.Concat(args, []any{afterId, streamBatchSize})...)
+	if err != nil {
+		return nil, err
+	}
+	return collectMembers(rows)
+}
+
+// Escapes the wildcards of a LIKE pattern so that text matches literally.
+func EscapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
+
+// Gets a page of the members in the trash, most recently deleted first.
+func (store *MemberStore) GetTrashPage(ctx context.Context, pageSize uint, page uint) ([]domain.Member, error) {
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT "+memberColumns+" FROM member WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id OFFSET $1 LIMIT $2;",
+		page*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return collectMembers(rows)
+}
+
+// Moves a member to the trash. The member is only removed permanently once
+// it is purged with PurgeDeletedBefore.
+// Returns false if there is no member with the given id outside of the trash.
+func (store *MemberStore) DeleteById(ctx context.Context, actor string, id uint64) (bool, error) {
+	member, err := store.changeMember(ctx, &id, domain.AuditActionDelete, actor, func(tx pgx.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() != nil {
+			return nil, nil
+		}
+		row, err := scanMemberRow(tx.QueryRow(
+			ctx,
+			"UPDATE member SET deleted_at = $2 WHERE id = $1\n"+
+				"RETURNING "+memberColumns+";",
+			id, time.Now().UTC(),
+		))
+		if err != nil {
+			return nil, err
+		}
+		return row.ToMember()
+	})
 	if err != nil {
 		return false, err
 	}
-	rows.Close()
-	deleted := rows.CommandTag().RowsAffected()
+	return member != nil, nil
+}
+
+// Takes a member out of the trash.
+// Returns the restored member, or nil if there is no member with the given id
+// in the trash.
+func (store *MemberStore) Restore(ctx context.Context, actor string, id uint64) (*domain.Member, error) {
+	return store.changeMember(ctx, &id, domain.AuditActionRestore, actor, func(tx pgx.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() == nil {
+			return nil, nil
+		}
+		row, err := scanMemberRow(tx.QueryRow(
+			ctx,
+			"UPDATE member SET deleted_at = NULL WHERE id = $1\n"+
+				"RETURNING "+memberColumns+";",
+			id,
+		))
+		if err != nil {
+			return nil, err
+		}
+		return row.ToMember()
+	})
+}
+
+// Permanently removes every member that was moved to the trash before the
+// given time. Returns the number of members removed.
+func (store *MemberStore) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(context.Background())
+
+	rows, err := tx.Query(
+		ctx,
+		"DELETE FROM member WHERE deleted_at IS NOT NULL AND deleted_at < $1\n"+
+			"RETURNING "+memberColumns+";",
+		cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	purged, err := collectMembers(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, member := range purged {
+		snapshot, err := memberAuditSnapshot(&member)
+		if err != nil {
+			return 0, err
+		}
+		err = recordAudit(ctx, tx, domain.AuditEntityMember, member.Id(), domain.AuditActionPurge, domain.AuditActorSystem, snapshot, nil)
+		if err != nil {
+			return 0, err
+		}
+	}
 
-	if deleted == 0 {
-		return false, nil
-	} else if deleted == 1 {
-		return true, nil
-	} else {
-		return false, fmt.Errorf("expected up to one row of table 'member' to be deleted but %d were deleted", deleted)
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
 	}
+	return int64(len(purged)), nil
 }
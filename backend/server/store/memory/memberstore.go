@@ -0,0 +1,380 @@
+// Package memory keeps members and schedules in memory rather than in the
+// database, for unit tests and for demonstrating the API. Everything is lost
+// when the server stops. Changes are not recorded in the audit log, campuses
+// are not checked to exist, and merging members does not re-point the users
+// linked to them, as these live in the database.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+// The members of every tenant, shared by the stores for each tenant.
+type memberData struct {
+	mutex  sync.Mutex
+	lastId uint64
+	rows   map[uint64]memberEntry
+}
+
+type memberEntry struct {
+	tenantId uint64
+	row      domain.MemberRow
+}
+
+type MemberStore struct {
+	data     *memberData
+	tenantId uint64
+}
+
+func CreateMemberStore() *MemberStore {
+	return &MemberStore{data: &memberData{rows: make(map[uint64]memberEntry)}}
+}
+
+// Gives the store limited to the members of the tenant.
+func (store *MemberStore) ForTenant(tenantId uint64) store.MemberRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// Runs fn with the store locked, unless the context is already done.
+func (store *MemberStore) locked(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	store.data.mutex.Lock()
+	defer store.data.mutex.Unlock()
+	return fn()
+}
+
+// Finds a member of the tenant regardless of whether it is in the trash.
+// Returns nil if there is no such member. Must be called with the store
+// locked.
+func (store *MemberStore) find(id uint64) *domain.MemberRow {
+	entry, ok := store.data.rows[id]
+	if !ok || entry.tenantId != store.tenantId {
+		return nil
+	}
+	return &entry.row
+}
+
+// Gives the members of the tenant matching fn, in order of id. Must be called
+// with the store locked.
+func (store *MemberStore) rowsWhere(fn func(row *domain.MemberRow) bool) []domain.MemberRow {
+	rows := make([]domain.MemberRow, 0)
+	for _, entry := range store.data.rows {
+		if entry.tenantId == store.tenantId && fn(&entry.row) {
+			rows = append(rows, entry.row)
+		}
+	}
+	slices.SortFunc(rows, func(a, b domain.MemberRow) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return rows
+}
+
+// Must be called with the store locked.
+func (store *MemberStore) save(row domain.MemberRow) (*domain.Member, error) {
+	store.data.rows[row.Id] = memberEntry{tenantId: store.tenantId, row: row}
+	return row.ToMember()
+}
+
+func memberRowFromDTO(id uint64, dto *domain.MemberUpdateDTO) domain.MemberRow {
+	return domain.MemberRow{
+		Id:           id,
+		FirstName:    dto.FirstName,
+		LastName:     dto.LastName,
+		EmailAddress: dto.EmailAddress,
+		PhoneNumber:  dto.PhoneNumber,
+		Address:      dto.Address,
+		CampusId:     dto.CampusId,
+		Notes:        dto.Notes,
+	}
+}
+
+func toMembers(rows []domain.MemberRow) ([]domain.Member, error) {
+	members := make([]domain.Member, 0, len(rows))
+	for i := range rows {
+		member, err := rows[i].ToMember()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *member)
+	}
+	return members, nil
+}
+
+// Gives the page of the rows, which is empty past the last page.
+func page[T any](rows []T, pageSize uint, page uint) []T {
+	start := min(uint(len(rows)), page*pageSize)
+	end := min(uint(len(rows)), start+pageSize)
+	return rows[start:end]
+}
+
+func (store *MemberStore) Create(ctx context.Context, actor string, createDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	members, err := store.CreateMany(ctx, actor, []domain.MemberUpdateDTO{*createDto})
+	if err != nil {
+		return nil, err
+	}
+	return &members[0], nil
+}
+
+func (store *MemberStore) CreateMany(ctx context.Context, actor string, createDtos []domain.MemberUpdateDTO) ([]domain.Member, error) {
+	members := make([]domain.Member, 0, len(createDtos))
+	err := store.locked(ctx, func() error {
+		for i := range createDtos {
+			store.data.lastId += 1
+			member, err := store.save(memberRowFromDTO(store.data.lastId, &createDtos[i]))
+			if err != nil {
+				return err
+			}
+			members = append(members, *member)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// Changes a member outside of the trash, giving nil if there is no such
+// member.
+func (store *MemberStore) change(ctx context.Context, id uint64, fn func(before *domain.Member) *domain.MemberUpdateDTO) (*domain.Member, error) {
+	var after *domain.Member
+	err := store.locked(ctx, func() error {
+		row := store.find(id)
+		if row == nil || row.DeletedAt != nil {
+			return nil
+		}
+		before, err := row.ToMember()
+		if err != nil {
+			return err
+		}
+		after, err = store.save(memberRowFromDTO(id, fn(before)))
+		return err
+	})
+	return after, err
+}
+
+func (store *MemberStore) Update(ctx context.Context, actor string, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	return store.change(ctx, id, func(_ *domain.Member) *domain.MemberUpdateDTO {
+		return updateDto
+	})
+}
+
+func (store *MemberStore) UpdateExceptNotes(ctx context.Context, actor string, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	return store.change(ctx, id, func(before *domain.Member) *domain.MemberUpdateDTO {
+		withNotes := *updateDto
+		withNotes.Notes = before.Notes()
+		return &withNotes
+	})
+}
+
+func (store *MemberStore) UpdateContactDetails(ctx context.Context, actor string, id uint64, updateDto *domain.MeUpdateDTO) (*domain.Member, error) {
+	return store.change(ctx, id, func(before *domain.Member) *domain.MemberUpdateDTO {
+		return updateDto.ApplyTo(before)
+	})
+}
+
+func (store *MemberStore) Merge(ctx context.Context, actor string, mergeDto *domain.MemberMergeDTO) (*domain.Member, error) {
+	var survivor *domain.Member
+	err := store.locked(ctx, func() error {
+		survivorRow, mergedRow := store.find(mergeDto.SurvivorId), store.find(mergeDto.MergedId)
+		if survivorRow == nil || survivorRow.DeletedAt != nil || mergedRow == nil || mergedRow.DeletedAt != nil {
+			return nil
+		}
+		before, err := survivorRow.ToMember()
+		if err != nil {
+			return err
+		}
+		merged, err := mergedRow.ToMember()
+		if err != nil {
+			return err
+		}
+
+		if survivor, err = store.save(memberRowFromDTO(before.Id(), mergeDto.Merge(before, merged))); err != nil {
+			return err
+		}
+		deleted := *mergedRow
+		deleted.DeletedAt = util.NewPtr(time.Now().UTC())
+		_, err = store.save(deleted)
+		return err
+	})
+	return survivor, err
+}
+
+func (store *MemberStore) FindById(ctx context.Context, id uint64) (*domain.Member, error) {
+	var member *domain.Member
+	err := store.locked(ctx, func() error {
+		row := store.find(id)
+		if row == nil || row.DeletedAt != nil {
+			return nil
+		}
+		var err error
+		member, err = row.ToMember()
+		return err
+	})
+	return member, err
+}
+
+// Whether the member matches the filter, as memberFilterConditions selects
+// members in the database.
+func matchesMemberFilter(row *domain.MemberRow, filter *domain.MemberFilter) bool {
+	if row.DeletedAt != nil {
+		return false
+	}
+	if filter.Search != nil {
+		search := strings.ToLower(*filter.Search)
+		found := false
+		for _, field := range []*string{row.FirstName, row.LastName, row.EmailAddress} {
+			if field != nil && strings.Contains(strings.ToLower(*field), search) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(filter.CampusIds) > 0 && (row.CampusId == nil || !slices.Contains(filter.CampusIds, *row.CampusId)) {
+		return false
+	}
+	return true
+}
+
+func (store *MemberStore) GetPage(ctx context.Context, filter *domain.MemberFilter, pageSize uint, pageNumber uint) ([]domain.Member, error) {
+	var members []domain.Member
+	err := store.locked(ctx, func() error {
+		rows := store.rowsWhere(func(row *domain.MemberRow) bool {
+			return matchesMemberFilter(row, filter)
+		})
+		var err error
+		members, err = toMembers(page(rows, pageSize, pageNumber))
+		return err
+	})
+	return members, err
+}
+
+func (store *MemberStore) GetAll(ctx context.Context) ([]domain.Member, error) {
+	var members []domain.Member
+	err := store.locked(ctx, func() error {
+		rows := store.rowsWhere(func(row *domain.MemberRow) bool {
+			return row.DeletedAt == nil
+		})
+		var err error
+		members, err = toMembers(rows)
+		return err
+	})
+	return members, err
+}
+
+func (store *MemberStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := store.locked(ctx, func() error {
+		count = int64(len(store.rowsWhere(func(row *domain.MemberRow) bool {
+			return row.DeletedAt == nil
+		})))
+		return nil
+	})
+	return count, err
+}
+
+// Calls fn with every member matching the filter as GetPage would find them.
+// The members are found before fn is first called, so fn may use the store.
+func (store *MemberStore) Stream(ctx context.Context, filter *domain.MemberFilter, fn func(member *domain.Member) error) error {
+	var rows []domain.MemberRow
+	err := store.locked(ctx, func() error {
+		rows = store.rowsWhere(func(row *domain.MemberRow) bool {
+			return matchesMemberFilter(row, filter)
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		member, err := rows[i].ToMember()
+		if err != nil {
+			return err
+		}
+		if err = fn(member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *MemberStore) GetTrashPage(ctx context.Context, pageSize uint, pageNumber uint) ([]domain.Member, error) {
+	var members []domain.Member
+	err := store.locked(ctx, func() error {
+		rows := store.rowsWhere(func(row *domain.MemberRow) bool {
+			return row.DeletedAt != nil
+		})
+		// most recently deleted first, as in the database
+		slices.SortStableFunc(rows, func(a, b domain.MemberRow) int {
+			return b.DeletedAt.Compare(*a.DeletedAt)
+		})
+		var err error
+		members, err = toMembers(page(rows, pageSize, pageNumber))
+		return err
+	})
+	return members, err
+}
+
+func (store *MemberStore) DeleteById(ctx context.Context, actor string, id uint64) (bool, error) {
+	deleted := false
+	err := store.locked(ctx, func() error {
+		row := store.find(id)
+		if row == nil || row.DeletedAt != nil {
+			return nil
+		}
+		row.DeletedAt = util.NewPtr(time.Now().UTC())
+		deleted = true
+		_, err := store.save(*row)
+		return err
+	})
+	return deleted, err
+}
+
+func (store *MemberStore) Restore(ctx context.Context, actor string, id uint64) (*domain.Member, error) {
+	var member *domain.Member
+	err := store.locked(ctx, func() error {
+		row := store.find(id)
+		if row == nil || row.DeletedAt == nil {
+			return nil
+		}
+		row.DeletedAt = nil
+		var err error
+		member, err = store.save(*row)
+		return err
+	})
+	return member, err
+}
+
+func (store *MemberStore) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := store.locked(ctx, func() error {
+		rows := store.rowsWhere(func(row *domain.MemberRow) bool {
+			return row.DeletedAt != nil && row.DeletedAt.Before(cutoff)
+		})
+		for _, row := range rows {
+			delete(store.data.rows, row.Id)
+		}
+		purged = int64(len(rows))
+		return nil
+	})
+	return purged, err
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+// The schedules of every tenant, shared by the stores for each tenant.
+type scheduleData struct {
+	mutex  sync.Mutex
+	lastId uint64
+	rows   []scheduleEntry
+}
+
+type scheduleEntry struct {
+	tenantId uint64
+	row      domain.ScheduleRow
+}
+
+type ScheduleStore struct {
+	data     *scheduleData
+	tenantId uint64
+}
+
+func CreateScheduleStore() *ScheduleStore {
+	return &ScheduleStore{data: &scheduleData{}}
+}
+
+// Gives the store limited to the schedules of the tenant.
+func (store *ScheduleStore) ForTenant(tenantId uint64) store.ScheduleRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+func (store *ScheduleStore) Create(ctx context.Context, actor string, createDto *domain.ScheduleCreateDTO) (*domain.Schedule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	row := domain.ScheduleRow{
+		BeginDate: createDto.BeginDate,
+		EndDate:   createDto.EndDate,
+		CampusId:  createDto.CampusId,
+	}
+	if createDto.RepeatInterval != nil {
+		row.RepeatIntervalCount = &createDto.RepeatInterval.Count
+		row.RepeatIntervalUnit = &createDto.RepeatInterval.Unit
+	}
+	if createDto.RepeatNthDayOfMonth != nil {
+		row.RepeatNthDayOfMonthDay = &createDto.RepeatNthDayOfMonth.Day
+		row.RepeatNthDayOfMonthN = &createDto.RepeatNthDayOfMonth.N
+	}
+
+	store.data.mutex.Lock()
+	defer store.data.mutex.Unlock()
+
+	id := store.data.lastId + 1
+	row.Id = &id
+	schedule, err := row.ToSchedule()
+	if err != nil {
+		return nil, err
+	}
+	store.data.lastId = id
+	store.data.rows = append(store.data.rows, scheduleEntry{tenantId: store.tenantId, row: row})
+	return schedule, nil
+}
+
+// Gets every schedule matching the filter, in order of their first service.
+func (store *ScheduleStore) GetAll(ctx context.Context, filter *domain.ScheduleFilter) ([]domain.Schedule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	store.data.mutex.Lock()
+	defer store.data.mutex.Unlock()
+
+	rows := make([]domain.ScheduleRow, 0)
+	for _, entry := range store.data.rows {
+		if entry.tenantId != store.tenantId {
+			continue
+		}
+		campusId := entry.row.CampusId
+		if len(filter.CampusIds) > 0 && (campusId == nil || !slices.Contains(filter.CampusIds, *campusId)) {
+			continue
+		}
+		rows = append(rows, entry.row)
+	}
+	slices.SortFunc(rows, func(a, b domain.ScheduleRow) int {
+		return cmp.Or(a.BeginDate.Compare(*b.BeginDate), cmp.Compare(*a.Id, *b.Id))
+	})
+
+	schedules := make([]domain.Schedule, 0, len(rows))
+	for i := range rows {
+		schedule, err := rows[i].ToSchedule()
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, nil
+}
+
+func (store *ScheduleStore) Count(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	store.data.mutex.Lock()
+	defer store.data.mutex.Unlock()
+
+	var count int64
+	for _, entry := range store.data.rows {
+		if entry.tenantId == store.tenantId {
+			count++
+		}
+	}
+	return count, nil
+}
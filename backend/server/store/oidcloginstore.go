@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OIDCLoginStore struct {
+	pool *pgxpool.Pool
+	tenantScope
+}
+
+func CreateOIDCLoginStore(pool *pgxpool.Pool) *OIDCLoginStore {
+	return &OIDCLoginStore{pool: pool}
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *OIDCLoginStore) ForTenant(tenantId uint64) OIDCLoginRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// Keeps a started login under its state until it is finished or expires. Only
+// the hash of the state is stored. Expired logins are removed.
+func (store *OIDCLoginStore) Create(ctx context.Context, state string, login *domain.OIDCLogin, duration time.Duration) error {
+	now := time.Now().UTC()
+
+	_, err := store.pool.Exec(
+		store.ctx(ctx),
+		"DELETE FROM oidc_login WHERE expires_at <= $1;",
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.pool.Exec(
+		store.ctx(ctx),
+		"INSERT INTO oidc_login (state_hash, provider, code_verifier, nonce, expires_at)\n"+
+			"VALUES ($1, $2, $3, $4, $5);",
+		auth.HashToken(state), login.Provider, login.CodeVerifier, login.Nonce, now.Add(duration),
+	)
+	return err
+}
+
+// Removes and returns the unexpired login with the state, so that each login
+// can only be finished once. Returns nil if there is no such login.
+func (store *OIDCLoginStore) Take(ctx context.Context, state string) (*domain.OIDCLogin, error) {
+	var login domain.OIDCLogin
+	err := store.pool.QueryRow(
+		store.ctx(ctx),
+		"DELETE FROM oidc_login WHERE state_hash = $1 AND expires_at > $2\n"+
+			"RETURNING provider, code_verifier, nonce;",
+		auth.HashToken(state), time.Now().UTC(),
+	).Scan(&login.Provider, &login.CodeVerifier, &login.Nonce)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+	return &login, nil
+}
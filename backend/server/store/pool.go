@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Connects to the database for the stores. Queries run as the tenant role, so
+// that each store only sees the rows of the tenant it is for, whichever user
+// the connection string logs in as. Each query is cancelled by the database
+// once it has run for the query timeout, unless the timeout is 0. Queries are
+// logged with the logger of the context they are run with.
+func CreatePool(ctx context.Context, connectionString string, queryTimeout time.Duration) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	if queryTimeout > 0 {
+		config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(queryTimeout.Milliseconds(), 10)
+	}
+
+	config.ConnConfig.Tracer = queryTracer{}
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, "SET ROLE "+tenantRole+";")
+		return err
+	}
+	config.BeforeAcquire = setConnectionTenant
+
+	return pgxpool.NewWithConfig(ctx, config)
+}
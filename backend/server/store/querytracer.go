@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Logs the queries of the stores with the logger of the context they are run
+// with, so that a query is logged with the request it was run for. Failed
+// queries are logged as errors, and every query is logged when debugging. The
+// arguments of queries are never logged, as they hold members' details.
+type queryTracer struct{}
+
+type queryStart struct {
+	sql  string
+	time time.Time
+}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartContextKey, queryStart{sql: data.SQL, time: time.Now()})
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, _ := ctx.Value(queryStartContextKey).(queryStart)
+	logger := logging.FromContext(ctx)
+
+	level := slog.LevelDebug
+	message := "query"
+	var pgErr *pgconn.PgError
+	switch {
+	case data.Err == nil:
+	case errors.As(data.Err, &pgErr) && strings.HasPrefix(pgErr.Code, "23"):
+		// the stores turn violated constraints into errors of their own, such
+		// as a username being taken
+		message = "query violated a constraint"
+	case errors.Is(data.Err, context.Canceled):
+		level = slog.LevelWarn
+		message = "query cancelled"
+	default:
+		level = slog.LevelError
+		message = "query failed"
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("sql", start.sql),
+		slog.Duration("duration", time.Since(start.time)),
+	}
+	if data.Err != nil {
+		attrs = append(attrs, slog.Any("error", data.Err))
+	} else {
+		attrs = append(attrs, slog.Int64("rows", data.CommandTag.RowsAffected()))
+	}
+	logger.LogAttrs(ctx, level, message, attrs...)
+}
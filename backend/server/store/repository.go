@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+// Where members are kept, e.g. a MemberStore in the database, or a
+// memory.MemberStore for tests and demonstrations. The methods behave as
+// those of MemberStore do.
+type MemberRepository interface {
+	// Gives the repository limited to the members of the tenant.
+	ForTenant(tenantId uint64) MemberRepository
+
+	Create(ctx context.Context, actor string, createDto *domain.MemberUpdateDTO) (*domain.Member, error)
+	CreateMany(ctx context.Context, actor string, createDtos []domain.MemberUpdateDTO) ([]domain.Member, error)
+	Update(ctx context.Context, actor string, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error)
+	UpdateExceptNotes(ctx context.Context, actor string, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error)
+	UpdateContactDetails(ctx context.Context, actor string, id uint64, updateDto *domain.MeUpdateDTO) (*domain.Member, error)
+	Merge(ctx context.Context, actor string, mergeDto *domain.MemberMergeDTO) (*domain.Member, error)
+
+	FindById(ctx context.Context, id uint64) (*domain.Member, error)
+	GetPage(ctx context.Context, filter *domain.MemberFilter, pageSize uint, page uint) ([]domain.Member, error)
+	GetAll(ctx context.Context) ([]domain.Member, error)
+	Stream(ctx context.Context, filter *domain.MemberFilter, fn func(member *domain.Member) error) error
+	GetTrashPage(ctx context.Context, pageSize uint, page uint) ([]domain.Member, error)
+	Count(ctx context.Context) (int64, error)
+
+	DeleteById(ctx context.Context, actor string, id uint64) (bool, error)
+	Restore(ctx context.Context, actor string, id uint64) (*domain.Member, error)
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// Where service schedules are kept. The methods behave as those of
+// ScheduleStore do.
+type ScheduleRepository interface {
+	// Gives the repository limited to the schedules of the tenant.
+	ForTenant(tenantId uint64) ScheduleRepository
+
+	Create(ctx context.Context, actor string, createDto *domain.ScheduleCreateDTO) (*domain.Schedule, error)
+	GetAll(ctx context.Context, filter *domain.ScheduleFilter) ([]domain.Schedule, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+// Where the churches served by the deployment are kept. The methods behave as
+// those of TenantStore do.
+type TenantRepository interface {
+	Ensure(ctx context.Context, slug string, name string) (*domain.Tenant, error)
+	FindBySlug(ctx context.Context, slug string) (*domain.Tenant, error)
+	GetAll(ctx context.Context) ([]domain.Tenant, error)
+}
+
+// Where users are kept. The methods behave as those of UserStore do.
+type UserRepository interface {
+	// Gives the repository limited to the users of the tenant.
+	ForTenant(tenantId uint64) UserRepository
+
+	Create(ctx context.Context, actor string, username string, passwordHash string, roles []domain.Role, memberId *uint64) (*domain.User, error)
+	SetRoles(ctx context.Context, actor string, id uint64, roles []domain.Role) (*domain.User, error)
+	SetMember(ctx context.Context, actor string, id uint64, memberId *uint64) (*domain.User, error)
+	SetCampuses(ctx context.Context, actor string, id uint64, campusIds []uint64) (*domain.User, error)
+	ProvisionOIDCUser(ctx context.Context, issuer string, subject string, username string, roles []domain.Role) (*domain.User, error)
+
+	FindByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetAll(ctx context.Context) ([]domain.User, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+// Where the sessions of logged in users are kept. The methods behave as those
+// of SessionStore do.
+type SessionRepository interface {
+	// Gives the repository limited to the sessions of the tenant.
+	ForTenant(tenantId uint64) SessionRepository
+
+	Create(ctx context.Context, userId uint64, duration time.Duration) (token string, expiresAt time.Time, err error)
+	FindUser(ctx context.Context, token string) (*domain.User, error)
+	Delete(ctx context.Context, token string) (bool, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Where single sign-on logins are kept until they are finished. The methods
+// behave as those of OIDCLoginStore do.
+type OIDCLoginRepository interface {
+	// Gives the repository limited to the logins of the tenant.
+	ForTenant(tenantId uint64) OIDCLoginRepository
+
+	Create(ctx context.Context, state string, login *domain.OIDCLogin, duration time.Duration) error
+	Take(ctx context.Context, state string) (*domain.OIDCLogin, error)
+}
+
+// Where API keys are kept. The methods behave as those of APIKeyStore do.
+type APIKeyRepository interface {
+	// Gives the repository limited to the keys of the tenant.
+	ForTenant(tenantId uint64) APIKeyRepository
+
+	Create(ctx context.Context, actor string, userId uint64, createDto *domain.APIKeyCreateDTO) (string, *domain.APIKey, error)
+	FindByKey(ctx context.Context, secret string) (*domain.APIKey, error)
+	GetAll(ctx context.Context) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, actor string, id uint64) (bool, error)
+}
+
+// Where campuses are kept. The methods behave as those of CampusStore do.
+type CampusRepository interface {
+	// Gives the repository limited to the campuses of the tenant.
+	ForTenant(tenantId uint64) CampusRepository
+
+	Create(ctx context.Context, actor string, createDto *domain.CampusUpdateDTO) (*domain.Campus, error)
+	Rename(ctx context.Context, actor string, id uint64, updateDto *domain.CampusUpdateDTO) (*domain.Campus, error)
+	DeleteById(ctx context.Context, actor string, id uint64) (bool, error)
+	GetAll(ctx context.Context) ([]domain.Campus, error)
+}
+
+// Where changes members make to their own details are kept. The methods
+// behave as those of MemberChangeStore do.
+type MemberChangeRepository interface {
+	// Gives the repository limited to the changes of the tenant.
+	ForTenant(tenantId uint64) MemberChangeRepository
+
+	Create(ctx context.Context, userId uint64, memberId uint64, updateDto *domain.MeUpdateDTO) (*domain.MemberChange, error)
+	FindPending(ctx context.Context, memberId uint64) (*domain.MemberChange, error)
+	GetPending(ctx context.Context) ([]domain.MemberChange, error)
+	Approve(ctx context.Context, actor string, id uint64) (*domain.MemberChange, *domain.Member, error)
+	Reject(ctx context.Context, actor string, id uint64) (*domain.MemberChange, error)
+}
+
+// Where the audit log is kept. Entries are recorded by the other repositories
+// as they make changes. The methods behave as those of AuditStore do.
+type AuditRepository interface {
+	// Gives the repository limited to the entries of the tenant.
+	ForTenant(tenantId uint64) AuditRepository
+
+	GetPage(ctx context.Context, filter *domain.AuditFilter, pageSize uint, page uint) ([]domain.AuditEntry, error)
+}
+
+// Checks on the database as a whole. The methods behave as those of
+// HealthStore do.
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	MigrationStatus(ctx context.Context) (*domain.MigrationStatus, error)
+	Columns(ctx context.Context) (map[string][]string, error)
+	ConnectionStats() *domain.ConnectionStats
+}
+
+// Reads and writes the rows of every tenant at once. The methods behave as
+// those of BackupStore do.
+type BackupRepository interface {
+	Export(ctx context.Context, tables []SchemaTable, fn func(table string, row json.RawMessage) error) error
+	Restore(ctx context.Context, insertAll func(insert RowInserter) error) error
+}
+
+var _ MemberRepository = (*MemberStore)(nil)
+var _ ScheduleRepository = (*ScheduleStore)(nil)
+var _ TenantRepository = (*TenantStore)(nil)
+var _ UserRepository = (*UserStore)(nil)
+var _ SessionRepository = (*SessionStore)(nil)
+var _ OIDCLoginRepository = (*OIDCLoginStore)(nil)
+var _ APIKeyRepository = (*APIKeyStore)(nil)
+var _ CampusRepository = (*CampusStore)(nil)
+var _ MemberChangeRepository = (*MemberChangeStore)(nil)
+var _ AuditRepository = (*AuditStore)(nil)
+var _ HealthRepository = (*HealthStore)(nil)
+var _ BackupRepository = (*BackupStore)(nil)
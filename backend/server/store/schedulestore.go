@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/carsonalh/churchmanagerbackend/server/domain"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -9,15 +10,22 @@ import (
 
 type ScheduleStore struct {
 	pool *pgxpool.Pool
+	tenantScope
 }
 
 func CreateScheduleStore(pool *pgxpool.Pool) *ScheduleStore {
-	return &ScheduleStore{
-		pool: pool,
-	}
+	return &ScheduleStore{pool: pool}
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *ScheduleStore) ForTenant(tenantId uint64) ScheduleRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
 }
 
-func (store *ScheduleStore) Create(createDto *domain.ScheduleCreateDTO) (*domain.Schedule, error) {
+// Returns ErrCampusNotFound if the schedule's campus does not exist.
+func (store *ScheduleStore) Create(ctx context.Context, actor string, createDto *domain.ScheduleCreateDTO) (*domain.Schedule, error) {
 	var count *uint
 	var unit *domain.ScheduleRepeatUnit
 	var day *domain.ScheduleDayOfWeek
@@ -40,18 +48,32 @@ func (store *ScheduleStore) Create(createDto *domain.ScheduleCreateDTO) (*domain
 		RepeatIntervalUnit:     unit,
 		RepeatNthDayOfMonthDay: day,
 		RepeatNthDayOfMonthN:   n,
+		CampusId:               createDto.CampusId,
+	}
+
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	if row.CampusId != nil {
+		if err = checkCampusExists(ctx, tx, *row.CampusId); err != nil {
+			return nil, err
+		}
 	}
 
-	err := store.pool.QueryRow(
-		context.Background(),
-		"INSERT INTO church_service_schedule (\n"+
+	err = tx.QueryRow(
+		ctx,
+		"INSERT INTO schedule (\n"+
 			"begin_date, end_date, repeat_interval_count, repeat_interval_unit,\n"+
-			"repeat_nth_day_of_month_day, repeat_nth_day_of_month_n)\n"+
-			"VALUES ($1, $2, $3, $4, $5, $6)\n"+
+			"repeat_nth_day_of_month_day, repeat_nth_day_of_month_n, campus_id)\n"+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7)\n"+
 			"RETURNING id;",
 		row.BeginDate, row.EndDate,
 		row.RepeatIntervalCount, row.RepeatIntervalUnit,
 		row.RepeatNthDayOfMonthDay, row.RepeatNthDayOfMonthN,
+		row.CampusId,
 	).Scan(&row.Id)
 	if err != nil {
 		return nil, err
@@ -62,5 +84,71 @@ func (store *ScheduleStore) Create(createDto *domain.ScheduleCreateDTO) (*domain
 		return nil, err
 	}
 
+	snapshot, err := domain.NewAuditSnapshot(schedule.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntitySchedule, schedule.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	return schedule, nil
 }
+
+// Gets every schedule matching the filter.
+func (store *ScheduleStore) GetAll(ctx context.Context, filter *domain.ScheduleFilter) ([]domain.Schedule, error) {
+	where := ""
+	args := make([]any, 0)
+	if len(filter.CampusIds) > 0 {
+		args = append(args, filter.CampusIds)
+		where = "WHERE campus_id = ANY ($1)\n"
+	}
+
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT id, begin_date, end_date, repeat_interval_count, repeat_interval_unit,\n"+
+			"repeat_nth_day_of_month_day, repeat_nth_day_of_month_n, campus_id\n"+
+			"FROM schedule\n"+
+			where+
+			"ORDER BY begin_date, id;",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := make([]domain.Schedule, 0)
+	for i := 0; rows.Next(); i++ {
+		var row domain.ScheduleRow
+		err := rows.Scan(
+			&row.Id, &row.BeginDate, &row.EndDate,
+			&row.RepeatIntervalCount, &row.RepeatIntervalUnit,
+			&row.RepeatNthDayOfMonthDay, &row.RepeatNthDayOfMonthN,
+			&row.CampusId,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		schedule, err := row.ToSchedule()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to schedule at row %d: %v", i, err)
+		}
+		schedules = append(schedules, *schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (store *ScheduleStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := store.pool.QueryRow(store.ctx(ctx), "SELECT count(*) FROM schedule;").Scan(&count)
+	return count, err
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A table the stores use, with the columns they read and write.
+type SchemaTable struct {
+	Name    string
+	Columns []string
+}
+
+// Every table and column the stores use, in either database, which the
+// migrations have to have created. The tests check that the stores' queries
+// use no other tables, and the integration tests that the migrated databases
+// have exactly these tables and columns.
+var Schema = []SchemaTable{
+	{"tenant", []string{"id", "slug", "name", "created_at"}},
+	{"campus", []string{"id", "tenant_id", "name", "created_at"}},
+	{"member", []string{
+		"id", "tenant_id", "first_name", "last_name", "email_address", "phone_number", "address", "campus_id", "notes",
+		"deleted_at",
+	}},
+	{"schedule", []string{
+		"id", "tenant_id", "begin_date", "end_date", "repeat_interval_count", "repeat_interval_unit",
+		"repeat_nth_day_of_month_day", "repeat_nth_day_of_month_n", "campus_id",
+	}},
+	{"audit_log", []string{
+		"id", "tenant_id", "entity_type", "entity_id", "action", "actor", "occurred_at", "before", "after", "diff",
+	}},
+	{"app_user", []string{
+		"id", "tenant_id", "username", "password_hash", "roles", "member_id", "campus_ids", "oidc_issuer",
+		"oidc_subject", "created_at",
+	}},
+	{"user_session", []string{"token_hash", "tenant_id", "user_id", "created_at", "expires_at"}},
+	{"oidc_login", []string{"state_hash", "tenant_id", "provider", "code_verifier", "nonce", "expires_at"}},
+	{"api_key", []string{
+		"id", "tenant_id", "name", "prefix", "key_hash", "scopes", "created_by", "created_at", "expires_at",
+		"last_used_at", "revoked_at",
+	}},
+	{"member_change", []string{
+		"id", "tenant_id", "member_id", "requested_by", "email_address", "phone_number", "status", "created_at",
+		"decided_at", "decided_by",
+	}},
+}
+
+// Checks that the database has every table and column in Schema, so that a
+// migration which is missing or names a table differently from the stores is
+// found when the server starts rather than by the first query to use it.
+func CheckSchema(ctx context.Context, health HealthRepository) error {
+	columns, err := health.Columns(ctx)
+	if err != nil {
+		return fmt.Errorf("reading the schema: %v", err)
+	}
+
+	missing := make([]string, 0)
+	for _, table := range Schema {
+		existing, ok := columns[table.Name]
+		if !ok {
+			missing = append(missing, "table "+table.Name)
+			continue
+		}
+		for _, column := range table.Columns {
+			if !slices.Contains(existing, column) {
+				missing = append(missing, "column "+table.Name+"."+column)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the database is missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
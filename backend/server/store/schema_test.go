@@ -0,0 +1,92 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+// The tables queries are made of which are not the stores' own.
+var foreignTables = []string{"schema_migrations", "information_schema", "sqlite_master", "pragma_table_info", "jsonb_populate_record"}
+
+// The table each query reads or writes, found by the keyword before it.
+var queriedTable = regexp.MustCompile(`\b(?:FROM|INTO|UPDATE|JOIN) ([a-z_]+)`)
+
+func TestStoresOnlyQueryTablesInSchema(t *testing.T) {
+	tables := make([]string, 0, len(store.Schema))
+	for _, table := range store.Schema {
+		tables = append(tables, table.Name)
+	}
+
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlitePaths, err := filepath.Glob(filepath.Join("sqlite", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range append(paths, sqlitePaths...) {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range queriedTable.FindAllStringSubmatch(string(source), -1) {
+			if !slices.Contains(tables, match[1]) && !slices.Contains(foreignTables, match[1]) {
+				t.Errorf("%s queries the table %s, which is not in store.Schema", path, match[1])
+			}
+		}
+	}
+}
+
+// Gives the columns it was made with, and nothing else.
+type columnsOnly map[string][]string
+
+func (columns columnsOnly) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (columns columnsOnly) MigrationStatus(ctx context.Context) (*domain.MigrationStatus, error) {
+	return &domain.MigrationStatus{}, nil
+}
+
+func (columns columnsOnly) Columns(ctx context.Context) (map[string][]string, error) {
+	return columns, nil
+}
+
+func (columns columnsOnly) ConnectionStats() *domain.ConnectionStats {
+	return &domain.ConnectionStats{}
+}
+
+func TestCheckSchemaFindsWhatIsMissing(t *testing.T) {
+	complete := make(columnsOnly)
+	for _, table := range store.Schema {
+		complete[table.Name] = append([]string{"unused"}, table.Columns...)
+	}
+	if err := store.CheckSchema(context.Background(), complete); err != nil {
+		t.Errorf("expected a schema with every column to pass, got %v", err)
+	}
+
+	incomplete := make(columnsOnly)
+	for name, columns := range complete {
+		incomplete[name] = columns
+	}
+	delete(incomplete, "schedule")
+	incomplete["member"] = slices.DeleteFunc(slices.Clone(incomplete["member"]), func(column string) bool {
+		return column == "deleted_at"
+	})
+	err := store.CheckSchema(context.Background(), incomplete)
+	if err == nil || !strings.Contains(err.Error(), "table schedule") || !strings.Contains(err.Error(), "column member.deleted_at") {
+		t.Errorf("expected the missing table and column to be named, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionStore struct {
+	pool *pgxpool.Pool
+	tenantScope
+}
+
+func CreateSessionStore(pool *pgxpool.Pool) *SessionStore {
+	return &SessionStore{pool: pool}
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *SessionStore) ForTenant(tenantId uint64) SessionRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// Starts a session for the user lasting the given duration, returning the
+// token which identifies it. Only the hash of the token is stored.
+func (store *SessionStore) Create(ctx context.Context, userId uint64, duration time.Duration) (token string, expiresAt time.Time, err error) {
+	token, tokenHash, err := auth.GenerateToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	createdAt := time.Now().UTC()
+	expiresAt = createdAt.Add(duration)
+
+	_, err = store.pool.Exec(
+		store.ctx(ctx),
+		"INSERT INTO user_session (token_hash, user_id, created_at, expires_at)\n"+
+			"VALUES ($1, $2, $3, $4);",
+		tokenHash, userId, createdAt, expiresAt,
+	)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// Finds the user a token belongs to. Returns nil if there is no unexpired
+// session with the token.
+func (store *SessionStore) FindUser(ctx context.Context, token string) (*domain.User, error) {
+	row, err := scanUserRow(store.pool.QueryRow(
+		store.ctx(ctx),
+		"SELECT app_user.id, app_user.username, app_user.password_hash, app_user.roles, app_user.member_id, app_user.campus_ids,\n"+
+			"app_user.created_at\n"+
+			"FROM user_session JOIN app_user ON app_user.id = user_session.user_id\n"+
+			"WHERE user_session.token_hash = $1 AND user_session.expires_at > $2;",
+		auth.HashToken(token), time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+
+	return row.ToUser()
+}
+
+// Ends the session with the token. Returns false if there was no such session.
+func (store *SessionStore) Delete(ctx context.Context, token string) (bool, error) {
+	tag, err := store.pool.Exec(
+		store.ctx(ctx),
+		"DELETE FROM user_session WHERE token_hash = $1;",
+		auth.HashToken(token),
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Removes every session which has expired, returning how many were removed.
+func (store *SessionStore) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := store.pool.Exec(
+		store.ctx(ctx),
+		"DELETE FROM user_session WHERE expires_at <= $1;",
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
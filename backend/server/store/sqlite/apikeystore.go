@@ -0,0 +1,270 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type APIKeyStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *APIKeyStore) ForTenant(tenantId uint64) store.APIKeyRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the api_key table in the order expected by scanAPIKeyRow.
+const apiKeyColumns = "id, name, prefix, scopes, created_by, created_at, expires_at, last_used_at, revoked_at"
+
+func scanAPIKeyRow(row scanner) (*domain.APIKeyRow, error) {
+	var keyRow domain.APIKeyRow
+	err := row.Scan(
+		&keyRow.Id,
+		&keyRow.Name,
+		&keyRow.Prefix,
+		jsonColumn{&keyRow.Scopes},
+		&keyRow.CreatedBy,
+		&keyRow.CreatedAt,
+		&keyRow.ExpiresAt,
+		&keyRow.LastUsedAt,
+		&keyRow.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &keyRow, nil
+}
+
+func apiKeyAuditSnapshot(key *domain.APIKey) (domain.AuditSnapshot, error) {
+	return domain.NewAuditSnapshot(key.ToResponseDTO())
+}
+
+// Creates a key for the user, returning the key itself, which is not stored
+// and cannot be found again.
+func (store *APIKeyStore) Create(ctx context.Context, actor string, userId uint64, createDto *domain.APIKeyCreateDTO) (string, *domain.APIKey, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	token, _, err := auth.GenerateToken()
+	if err != nil {
+		return "", nil, err
+	}
+	secret := apiKeyPrefix + token
+
+	scopes := make([]string, len(createDto.Scopes))
+	for i, scope := range createDto.Scopes {
+		scopes[i] = string(scope)
+	}
+	encodedScopes, err := encodeJSON(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var expiresAt *time.Time
+	if createDto.ExpiresAt != nil {
+		utc := createDto.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback()
+
+	row, err := scanAPIKeyRow(tx.QueryRowContext(
+		ctx,
+		"INSERT INTO api_key (tenant_id, name, prefix, key_hash, scopes, created_by, created_at, expires_at)\n"+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?)\n"+
+			"RETURNING "+apiKeyColumns+";",
+		store.tenantId, createDto.Name, secret[:apiKeyDisplayLength], auth.HashToken(secret), encodedScopes, userId,
+		time.Now().UTC(), expiresAt,
+	))
+	if err != nil {
+		return "", nil, err
+	}
+	key, err := row.ToAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	snapshot, err := apiKeyAuditSnapshot(key)
+	if err != nil {
+		return "", nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityAPIKey, key.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", nil, err
+	}
+
+	return secret, key, nil
+}
+
+// Finds the unexpired, unrevoked key, recording that it was used. The key is
+// limited to the scopes its creator still has permission for. Returns nil if
+// there is no such key, or if its creator has been deleted.
+func (store *APIKeyStore) FindByKey(ctx context.Context, secret string) (*domain.APIKey, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+
+	row, err := scanAPIKeyRow(store.db.QueryRowContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_key\n"+
+			"WHERE tenant_id = ? AND key_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?);",
+		store.tenantId, auth.HashToken(secret), now,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if row.LastUsedAt == nil || now.Sub(*row.LastUsedAt) >= apiKeyLastUsedResolution {
+		_, err = store.db.ExecContext(
+			ctx,
+			"UPDATE api_key SET last_used_at = ? WHERE tenant_id = ? AND id = ?;",
+			now, store.tenantId, row.Id,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("recording use of API key %d: %v", row.Id, err)
+		}
+		row.LastUsedAt = &now
+	}
+
+	if row.CreatedBy == nil {
+		return nil, nil
+	}
+	creatorRow, err := scanUserRow(store.db.QueryRowContext(
+		ctx,
+		"SELECT "+userColumns+" FROM app_user WHERE tenant_id = ? AND id = ?;",
+		store.tenantId, *row.CreatedBy,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	creator, err := creatorRow.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := row.ToAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	return key.LimitedTo(creator), nil
+}
+
+// Gives every key, including revoked and expired keys, most recent first.
+func (store *APIKeyStore) GetAll(ctx context.Context) ([]domain.APIKey, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_key WHERE tenant_id = ? ORDER BY id DESC;",
+		store.tenantId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]domain.APIKey, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanAPIKeyRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		key, err := row.ToAPIKey()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to API key at row %d: %v", i, err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Stops a key from working. Revoking a revoked key changes nothing. Returns
+// false if there is no such key.
+func (store *APIKeyStore) Revoke(ctx context.Context, actor string, id uint64) (bool, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	beforeRow, err := scanAPIKeyRow(tx.QueryRowContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_key WHERE tenant_id = ? AND id = ?;",
+		store.tenantId, id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	if beforeRow.RevokedAt != nil {
+		return true, nil
+	}
+
+	afterRow, err := scanAPIKeyRow(tx.QueryRowContext(
+		ctx,
+		"UPDATE api_key SET revoked_at = ? WHERE tenant_id = ? AND id = ?\n"+
+			"RETURNING "+apiKeyColumns+";",
+		time.Now().UTC(), store.tenantId, id,
+	))
+	if err != nil {
+		return false, err
+	}
+
+	before, err := beforeRow.ToAPIKey()
+	if err != nil {
+		return false, err
+	}
+	after, err := afterRow.ToAPIKey()
+	if err != nil {
+		return false, err
+	}
+	beforeSnapshot, err := apiKeyAuditSnapshot(before)
+	if err != nil {
+		return false, err
+	}
+	afterSnapshot, err := apiKeyAuditSnapshot(after)
+	if err != nil {
+		return false, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityAPIKey, id, domain.AuditActionDelete, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
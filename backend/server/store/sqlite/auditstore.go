@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type AuditStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *AuditStore) ForTenant(tenantId uint64) store.AuditRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// Records a change to an entity of the tenant in the audit log as part of the
+// transaction making that change, so that a change is never made without being
+// recorded.
+func recordAudit(
+	ctx context.Context,
+	tx queryer,
+	tenantId uint64,
+	entityType domain.AuditEntityType,
+	entityId uint64,
+	action domain.AuditAction,
+	actor string,
+	before domain.AuditSnapshot,
+	after domain.AuditSnapshot,
+) error {
+	// nil snapshots are left as NULL rather than encoded as null
+	var encodedBefore, encodedAfter any
+	var err error
+	if before != nil {
+		if encodedBefore, err = encodeJSON(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if encodedAfter, err = encodeJSON(after); err != nil {
+			return err
+		}
+	}
+	diff, err := json.Marshal(domain.DiffAuditSnapshots(before, after))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO audit_log (tenant_id, entity_type, entity_id, action, actor, occurred_at, before, after, diff)\n"+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		tenantId, entityType, entityId, action, actor, time.Now().UTC(), encodedBefore, encodedAfter, string(diff),
+	)
+	if err != nil {
+		return fmt.Errorf("recording %s of %s %d in audit log: %v", action, entityType, entityId, err)
+	}
+	return nil
+}
+
+// Gets a page of the audit log entries matching the filter, most recent first.
+func (store *AuditStore) GetPage(ctx context.Context, filter *domain.AuditFilter, pageSize uint, page uint) ([]domain.AuditEntry, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	conditions := []string{"tenant_id = ?"}
+	args := []any{store.tenantId}
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, condition)
+	}
+
+	if filter.EntityType != nil {
+		addCondition("entity_type = ?", *filter.EntityType)
+	}
+	if filter.EntityId != nil {
+		addCondition("entity_id = ?", *filter.EntityId)
+	}
+	if filter.Action != nil {
+		addCondition("action = ?", *filter.Action)
+	}
+	if filter.Actor != nil {
+		addCondition("actor = ?", *filter.Actor)
+	}
+	if filter.Since != nil {
+		addCondition("occurred_at >= ?", filter.Since.UTC())
+	}
+	if filter.Until != nil {
+		addCondition("occurred_at < ?", filter.Until.UTC())
+	}
+
+	args = append(args, pageSize, page*pageSize)
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT id, entity_type, entity_id, action, actor, occurred_at, before, after, diff FROM audit_log\n"+
+			"WHERE "+strings.Join(conditions, " AND ")+"\n"+
+			"ORDER BY occurred_at DESC, id DESC LIMIT ? OFFSET ?;",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0)
+	i := 0
+	for rows.Next() {
+		var row domain.AuditEntryRow
+		err = rows.Scan(
+			&row.Id, &row.EntityType, &row.EntityId, &row.Action, &row.Actor, &row.OccurredAt,
+			jsonColumn{&row.Before}, jsonColumn{&row.After}, jsonColumn{&row.Diff},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		entry, err := row.ToAuditEntry()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to audit entry at row %d: %v", i, err)
+		}
+		entries = append(entries, *entry)
+		i += 1
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
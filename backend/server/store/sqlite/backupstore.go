@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+// The columns which hold JSON, in place of the arrays and JSONB of postgres.
+// They are exported as the JSON they hold rather than as text, as postgres
+// exports its arrays and JSONB.
+var jsonColumns = []string{"roles", "campus_ids", "scopes", "before", "after", "diff"}
+
+// Reads and writes the rows of every tenant at once, for backing up and
+// restoring the whole database.
+type BackupStore struct {
+	db *db
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func columnList(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func isJSONColumn(column string) bool {
+	for _, jsonColumn := range jsonColumns {
+		if column == jsonColumn {
+			return true
+		}
+	}
+	return false
+}
+
+// Calls fn with each row of the tables as a JSON object of the table's
+// columns, in the order the tables are given and then by tenant and by the
+// first column, which is each table's key. The rows are read in a single
+// transaction, so that they are as they were at a single moment.
+func (store *BackupStore) Export(ctx context.Context, tables []store.SchemaTable, fn func(table string, row json.RawMessage) error) error {
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		fields := make([]string, len(table.Columns))
+		for i, column := range table.Columns {
+			value := quoteIdentifier(column)
+			if isJSONColumn(column) {
+				value = "json(" + value + ")"
+			}
+			fields[i] = "'" + column + "', " + value
+		}
+		orderBy := quoteIdentifier(table.Columns[0])
+		// tenants are not themselves of a tenant
+		if table.Name != "tenant" {
+			orderBy = "tenant_id, " + orderBy
+		}
+
+		rows, err := tx.QueryContext(
+			ctx,
+			"SELECT json_object("+strings.Join(fields, ", ")+") FROM "+quoteIdentifier(table.Name)+"\n"+
+				"ORDER BY "+orderBy+";",
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var row string
+			if err = rows.Scan(&row); err != nil {
+				rows.Close()
+				return err
+			}
+			if err = fn(table.Name, json.RawMessage(row)); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Replaces the tenants of a database which has no other rows, such as one
+// which has only been migrated, with the rows insertAll inserts, keeping their
+// ids. Everything is restored in a single transaction, so that nothing is if
+// any row cannot be. Returns ErrNotEmpty if the database has rows other than
+// tenants.
+func (store *BackupStore) Restore(ctx context.Context, insertAll func(insert store.RowInserter) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range schema {
+		if table.Name == "tenant" {
+			continue
+		}
+		var exists bool
+		err = tx.QueryRowContext(
+			ctx,
+			"SELECT EXISTS (SELECT 1 FROM "+quoteIdentifier(table.Name)+");",
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errNotEmpty
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM tenant;"); err != nil {
+		return err
+	}
+
+	// AUTOINCREMENT keeps the largest id inserted into each table, so the ids
+	// of rows created after the restore follow on from those restored
+	err = insertAll(func(table string, columns []string, row json.RawMessage) error {
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = "json_extract(?1, '$." + quoteIdentifier(column) + "')"
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO "+quoteIdentifier(table)+" ("+columnList(columns)+")\n"+
+				"VALUES ("+strings.Join(values, ", ")+");",
+			string(row),
+		)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,257 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type CampusStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *CampusStore) ForTenant(tenantId uint64) store.CampusRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the campus table in the order expected by scanCampusRow.
+const campusColumns = "id, name, created_at"
+
+func scanCampusRow(row scanner) (*domain.CampusRow, error) {
+	var campusRow domain.CampusRow
+	err := row.Scan(
+		&campusRow.Id,
+		&campusRow.Name,
+		&campusRow.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &campusRow, nil
+}
+
+func campusAuditSnapshot(campus *domain.Campus) (domain.AuditSnapshot, error) {
+	return domain.NewAuditSnapshot(campus.ToResponseDTO())
+}
+
+// Checks that a campus of the tenant exists, for placing something at it.
+// Returns store.ErrCampusNotFound if it does not.
+func checkCampusExists(ctx context.Context, tx queryer, tenantId uint64, campusId uint64) error {
+	var exists bool
+	err := tx.QueryRowContext(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM campus WHERE tenant_id = ? AND id = ?);",
+		tenantId, campusId,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errCampusNotFound
+	}
+	return nil
+}
+
+// Returns store.ErrCampusNameTaken if there is already a campus with the name.
+func (store *CampusStore) Create(ctx context.Context, actor string, createDto *domain.CampusUpdateDTO) (*domain.Campus, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row, err := scanCampusRow(tx.QueryRowContext(
+		ctx,
+		"INSERT INTO campus (tenant_id, name, created_at)\n"+
+			"VALUES (?, ?, ?)\n"+
+			"ON CONFLICT (tenant_id, name) DO NOTHING\n"+
+			"RETURNING "+campusColumns+";",
+		store.tenantId, createDto.Name, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errCampusNameTaken
+		}
+		return nil, err
+	}
+	campus, err := row.ToCampus()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := campusAuditSnapshot(campus)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityCampus, campus.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return campus, nil
+}
+
+// Returns nil if there is no such campus, or store.ErrCampusNameTaken if
+// another campus already has the name.
+func (store *CampusStore) Rename(ctx context.Context, actor string, id uint64, updateDto *domain.CampusUpdateDTO) (*domain.Campus, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := findCampus(ctx, tx, store.tenantId, id)
+	if err != nil || before == nil {
+		return nil, err
+	}
+
+	var taken bool
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM campus WHERE tenant_id = ? AND name = ? AND id <> ?);",
+		store.tenantId, updateDto.Name, id,
+	).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, errCampusNameTaken
+	}
+
+	row, err := scanCampusRow(tx.QueryRowContext(
+		ctx,
+		"UPDATE campus SET name = ? WHERE tenant_id = ? AND id = ?\n"+
+			"RETURNING "+campusColumns+";",
+		updateDto.Name, store.tenantId, id,
+	))
+	if err != nil {
+		return nil, err
+	}
+	after, err := row.ToCampus()
+	if err != nil {
+		return nil, err
+	}
+
+	beforeSnapshot, err := campusAuditSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	afterSnapshot, err := campusAuditSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityCampus, id, domain.AuditActionUpdate, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// Removes a campus, leaving its members and schedules at no campus. Users
+// limited to the campus keep its id, rather than being allowed every campus
+// once they are limited to none, and so see nothing of it. Returns false if
+// there is no such campus.
+func (store *CampusStore) DeleteById(ctx context.Context, actor string, id uint64) (bool, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	campus, err := findCampus(ctx, tx, store.tenantId, id)
+	if err != nil || campus == nil {
+		return false, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM campus WHERE tenant_id = ? AND id = ?;", store.tenantId, id); err != nil {
+		return false, err
+	}
+
+	snapshot, err := campusAuditSnapshot(campus)
+	if err != nil {
+		return false, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityCampus, id, domain.AuditActionDelete, actor, snapshot, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Returns nil if the tenant has no such campus.
+func findCampus(ctx context.Context, tx queryer, tenantId uint64, id uint64) (*domain.Campus, error) {
+	row, err := scanCampusRow(tx.QueryRowContext(
+		ctx,
+		"SELECT "+campusColumns+" FROM campus WHERE tenant_id = ? AND id = ?;",
+		tenantId, id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.ToCampus()
+}
+
+// Gets every campus in order of name.
+func (store *CampusStore) GetAll(ctx context.Context) ([]domain.Campus, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+campusColumns+" FROM campus WHERE tenant_id = ? ORDER BY name, id;",
+		store.tenantId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campuses := make([]domain.Campus, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanCampusRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		campus, err := row.ToCampus()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to campus at row %d: %v", i, err)
+		}
+		campuses = append(campuses, *campus)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return campuses, nil
+}
@@ -0,0 +1,180 @@
+// Package sqlite keeps everything in a SQLite database rather than in
+// postgres, for small churches which would rather not run a database server,
+// e.g. on a single Raspberry Pi. The stores behave as those of the store
+// package do. SQLite has no row-level security, so every query of a store is
+// limited to the rows of its tenant by the query itself.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// The scheme of connection strings naming a SQLite database, which are the
+// scheme followed by the path of the database's file, e.g.
+// sqlite:///var/lib/churchmanager/church.db.
+const Scheme = "sqlite"
+
+// Options for each connection to the database. Foreign keys are not enforced
+// by default. Writing transactions take the database's lock when they begin,
+// which stands in for locking rows with FOR UPDATE, and wait for it rather
+// than failing at once while another writes.
+const connectionOptions = "_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate"
+
+// Whether the connection string names a SQLite database rather than a
+// postgres one.
+func IsConnectionString(connectionString string) bool {
+	return strings.HasPrefix(connectionString, Scheme+"://")
+}
+
+func databasePath(connectionString string) string {
+	return strings.TrimPrefix(connectionString, Scheme+"://")
+}
+
+// The URL golang-migrate opens the database named by the connection string
+// with.
+func MigrationURL(connectionString string) string {
+	return "sqlite3://" + databasePath(connectionString)
+}
+
+// The errors, limits and schema of the store package, which the receivers of
+// the stores shadow within their methods.
+var (
+	errCampusNameTaken = store.ErrCampusNameTaken
+	errCampusNotFound  = store.ErrCampusNotFound
+	errMemberNotFound  = store.ErrMemberNotFound
+	errNotEmpty        = store.ErrNotEmpty
+	errUsernameTaken   = store.ErrUsernameTaken
+	schema             = store.Schema
+)
+
+const (
+	apiKeyPrefix             = store.APIKeyPrefix
+	apiKeyDisplayLength      = store.APIKeyDisplayLength
+	apiKeyLastUsedResolution = store.APIKeyLastUsedResolution
+)
+
+// The database shared by the stores.
+type db struct {
+	*sql.DB
+	queryTimeout time.Duration
+}
+
+// Opens the database, creating its file if it does not exist yet. The queries
+// of each call to a store are cancelled once they have run for the query
+// timeout, unless the timeout is 0, as SQLite has no timeout of its own.
+// Streamed reads apply the timeout to each of the queries they read a batch
+// with rather than to the whole stream.
+func Open(ctx context.Context, connectionString string, queryTimeout time.Duration) (*store.Stores, error) {
+	if !IsConnectionString(connectionString) {
+		return nil, fmt.Errorf("not a %s:// connection string", Scheme)
+	}
+	sqlDB, err := sql.Open("sqlite3", databasePath(connectionString)+"?"+connectionOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err = sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	database := &db{DB: sqlDB, queryTimeout: queryTimeout}
+	return &store.Stores{
+		Tenants:       &TenantStore{db: database},
+		Users:         &UserStore{db: database},
+		Sessions:      &SessionStore{db: database},
+		OIDCLogins:    &OIDCLoginStore{db: database},
+		APIKeys:       &APIKeyStore{db: database},
+		Campuses:      &CampusStore{db: database},
+		Members:       &MemberStore{db: database},
+		MemberChanges: &MemberChangeStore{db: database},
+		Schedules:     &ScheduleStore{db: database},
+		Audit:         &AuditStore{db: database},
+		Health:        &HealthStore{db: database},
+		Backup:        &BackupStore{db: database},
+		Close:         func() { sqlDB.Close() },
+	}, nil
+}
+
+// The context a call to a store runs its queries with, which is cancelled once
+// they have run for the query timeout. Calls which hand rows to a caller's
+// function as they are read take a context for each query instead, so that
+// only the time spent in the database counts against the timeout.
+func (db *db) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, db.queryTimeout)
+}
+
+// Either the database or a transaction, for queries made as part of larger
+// changes.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Either a single row or rows being iterated over.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// Gives the time as postgres keeps it in a TIMESTAMP WITHOUT TIME ZONE column,
+// with its wall clock kept and its zone discarded. Times are kept as text,
+// which only sorts in order while every time is in the same zone.
+func withoutTimeZone(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	wallClock := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return &wallClock
+}
+
+// Encodes a value for a column holding JSON, which SQLite uses in place of
+// arrays and JSONB.
+func encodeJSON(value any) (any, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// Scans a column holding JSON into the value it points to, leaving the value
+// as it is if the column is NULL.
+type jsonColumn struct {
+	value any
+}
+
+func (column jsonColumn) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), column.value)
+	case []byte:
+		return json.Unmarshal(src, column.value)
+	default:
+		return fmt.Errorf("cannot scan %T as JSON", src)
+	}
+}
+
+// A condition that a column is one of the values, which SQLite has no arrays
+// to pass in a single argument for.
+func inCondition[T any](column string, values []T) (string, []any) {
+	placeholders := make([]string, len(values))
+	args := make([]any, len(values))
+	for i := range values {
+		placeholders[i] = "?"
+		args[i] = values[i]
+	}
+	return column + " IN (" + strings.Join(placeholders, ", ") + ")", args
+}
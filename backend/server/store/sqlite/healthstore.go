@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+// Checks on the database as a whole, which are not for any tenant.
+type HealthStore struct {
+	db *db
+}
+
+// Checks that the database can be opened and used.
+func (store *HealthStore) Ping(ctx context.Context) error {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	return store.db.PingContext(ctx)
+}
+
+// Gives how the connections to the database's file are used.
+func (store *HealthStore) ConnectionStats() *domain.ConnectionStats {
+	stats := store.db.Stats()
+	return &domain.ConnectionStats{
+		Acquired:     int64(stats.InUse),
+		Idle:         int64(stats.Idle),
+		Max:          int64(stats.MaxOpenConnections),
+		Waits:        stats.WaitCount,
+		WaitDuration: stats.WaitDuration,
+	}
+}
+
+// Reads the version of the schema from the table golang-migrate records it in.
+// A database which has never been migrated is at version 0.
+func (store *HealthStore) MigrationStatus(ctx context.Context) (*domain.MigrationStatus, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	var status domain.MigrationStatus
+	var version int64
+	err := store.db.QueryRowContext(
+		ctx,
+		"SELECT version, dirty FROM schema_migrations LIMIT 1;",
+	).Scan(&version, &status.Dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &status, nil
+		}
+		return nil, err
+	}
+	status.Version = uint(version)
+	return &status, nil
+}
+
+// Gives the columns of each table of the database, by the name of the table.
+func (store *HealthStore) Columns(ctx context.Context) (map[string][]string, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT sqlite_master.name, columns.name FROM sqlite_master, pragma_table_info(sqlite_master.name) AS columns\n"+
+			"WHERE sqlite_master.type = 'table'\n"+
+			"ORDER BY sqlite_master.name, columns.cid;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err = rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+		columns[table] = append(columns[table], column)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
@@ -0,0 +1,233 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type MemberChangeStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *MemberChangeStore) ForTenant(tenantId uint64) store.MemberChangeRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the member_change table in the order expected by
+// scanMemberChangeRow.
+const memberChangeColumns = "id, member_id, requested_by, email_address, phone_number, status, created_at, decided_at, decided_by"
+
+func scanMemberChangeRow(row scanner) (*domain.MemberChangeRow, error) {
+	var changeRow domain.MemberChangeRow
+	err := row.Scan(
+		&changeRow.Id,
+		&changeRow.MemberId,
+		&changeRow.RequestedBy,
+		&changeRow.EmailAddress,
+		&changeRow.PhoneNumber,
+		&changeRow.Status,
+		&changeRow.CreatedAt,
+		&changeRow.DecidedAt,
+		&changeRow.DecidedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &changeRow, nil
+}
+
+// Queues a change a user made to the member they are for approval. A member
+// has at most one pending change, so a later change is combined with the one
+// already pending, with the later values taking precedence.
+func (store *MemberChangeStore) Create(ctx context.Context, userId uint64, memberId uint64, updateDto *domain.MeUpdateDTO) (*domain.MemberChange, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	row, err := scanMemberChangeRow(store.db.QueryRowContext(
+		ctx,
+		"INSERT INTO member_change (tenant_id, member_id, requested_by, email_address, phone_number, created_at)\n"+
+			"VALUES (?, ?, ?, ?, ?, ?)\n"+
+			"ON CONFLICT (member_id) WHERE status = 'pending' DO UPDATE SET\n"+
+			"requested_by = excluded.requested_by,\n"+
+			"email_address = COALESCE(excluded.email_address, member_change.email_address),\n"+
+			"phone_number = COALESCE(excluded.phone_number, member_change.phone_number),\n"+
+			"created_at = excluded.created_at\n"+
+			"RETURNING "+memberChangeColumns+";",
+		store.tenantId, memberId, userId, updateDto.EmailAddress, updateDto.PhoneNumber, time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMemberChange()
+}
+
+// Returns nil if the member has no change waiting for approval.
+func (store *MemberChangeStore) FindPending(ctx context.Context, memberId uint64) (*domain.MemberChange, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	row, err := scanMemberChangeRow(store.db.QueryRowContext(
+		ctx,
+		"SELECT "+memberChangeColumns+" FROM member_change WHERE tenant_id = ? AND member_id = ? AND status = 'pending';",
+		store.tenantId, memberId,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.ToMemberChange()
+}
+
+// Gets every change waiting for approval, oldest first.
+func (store *MemberChangeStore) GetPending(ctx context.Context) ([]domain.MemberChange, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+memberChangeColumns+" FROM member_change WHERE tenant_id = ? AND status = 'pending' ORDER BY created_at, id;",
+		store.tenantId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]domain.MemberChange, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanMemberChangeRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		change, err := row.ToMemberChange()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to member change at row %d: %v", i, err)
+		}
+		changes = append(changes, *change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Makes a pending change to its member, recording the update in the audit log
+// against the actor who approved it. Returns nil if there is no pending change
+// with the id, or store.ErrMemberNotFound if the member has since been moved
+// to the trash.
+func (store *MemberChangeStore) Approve(ctx context.Context, actor string, id uint64) (*domain.MemberChange, *domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	change, err := findPendingMemberChange(ctx, tx, store.tenantId, id)
+	if err != nil || change == nil {
+		return nil, nil, err
+	}
+
+	before, err := findMember(ctx, tx, store.tenantId, change.MemberId())
+	if err != nil {
+		return nil, nil, err
+	}
+	if before == nil || before.DeletedAt() != nil {
+		return nil, nil, errMemberNotFound
+	}
+	beforeSnapshot, err := memberAuditSnapshot(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	after, err := updateMember(ctx, tx, store.tenantId, before.Id(), change.ApplyTo(before))
+	if err != nil {
+		return nil, nil, err
+	}
+	afterSnapshot, err := memberAuditSnapshot(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityMember, after.Id(), domain.AuditActionUpdate, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if change, err = decideMemberChange(ctx, tx, store.tenantId, id, domain.MemberChangeApproved, actor); err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return change, after, nil
+}
+
+// Returns nil if there is no pending change with the id.
+func (store *MemberChangeStore) Reject(ctx context.Context, actor string, id uint64) (*domain.MemberChange, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	change, err := findPendingMemberChange(ctx, tx, store.tenantId, id)
+	if err != nil || change == nil {
+		return nil, err
+	}
+
+	if change, err = decideMemberChange(ctx, tx, store.tenantId, id, domain.MemberChangeRejected, actor); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Returns nil if there is no pending change with the id.
+func findPendingMemberChange(ctx context.Context, tx queryer, tenantId uint64, id uint64) (*domain.MemberChange, error) {
+	row, err := scanMemberChangeRow(tx.QueryRowContext(
+		ctx,
+		"SELECT "+memberChangeColumns+" FROM member_change WHERE tenant_id = ? AND id = ? AND status = 'pending';",
+		tenantId, id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.ToMemberChange()
+}
+
+func decideMemberChange(ctx context.Context, tx queryer, tenantId uint64, id uint64, status domain.MemberChangeStatus, actor string) (*domain.MemberChange, error) {
+	row, err := scanMemberChangeRow(tx.QueryRowContext(
+		ctx,
+		"UPDATE member_change SET status = ?, decided_at = ?, decided_by = ? WHERE tenant_id = ? AND id = ?\n"+
+			"RETURNING "+memberChangeColumns+";",
+		string(status), time.Now().UTC(), actor, tenantId, id,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMemberChange()
+}
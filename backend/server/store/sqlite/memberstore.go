@@ -0,0 +1,592 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+	"github.com/carsonalh/churchmanagerbackend/server/util"
+)
+
+type MemberStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *MemberStore) ForTenant(tenantId uint64) store.MemberRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the member table in the order expected by scanMemberRow.
+const memberColumns = "id, first_name, last_name, email_address, phone_number, address, campus_id, notes, deleted_at"
+
+func scanMemberRow(row scanner) (*domain.MemberRow, error) {
+	var memberRow domain.MemberRow
+	err := row.Scan(
+		&memberRow.Id,
+		&memberRow.FirstName,
+		&memberRow.LastName,
+		&memberRow.EmailAddress,
+		&memberRow.PhoneNumber,
+		&memberRow.Address,
+		&memberRow.CampusId,
+		&memberRow.Notes,
+		&memberRow.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &memberRow, nil
+}
+
+func collectMembers(rows *sql.Rows) ([]domain.Member, error) {
+	defer rows.Close()
+	members := make([]domain.Member, 0)
+	i := 0
+	for rows.Next() {
+		row, err := scanMemberRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		member, err := row.ToMember()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to member at row %d: %v", i, err)
+		}
+		members = append(members, *member)
+		i += 1
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func memberAuditSnapshot(member *domain.Member) (domain.AuditSnapshot, error) {
+	return domain.NewAuditSnapshot(member.ToResponseDTO())
+}
+
+// Finds a member of the tenant regardless of whether it is in the trash.
+// Returns nil if there is no such member.
+func findMember(ctx context.Context, tx queryer, tenantId uint64, id uint64) (*domain.Member, error) {
+	row, err := scanMemberRow(tx.QueryRowContext(
+		ctx,
+		"SELECT "+memberColumns+" FROM member WHERE tenant_id = ? AND id = ?;",
+		tenantId, id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.ToMember()
+}
+
+// Runs a change to a single member in a transaction, recording the change in
+// the audit log. The change is given the member as it was before the change
+// (nil for creations) and returns the member as it is after the change, or nil
+// if it made no change.
+func (store *MemberStore) changeMember(
+	ctx context.Context,
+	id *uint64,
+	action domain.AuditAction,
+	actor string,
+	change func(tx *sql.Tx, before *domain.Member) (*domain.Member, error),
+) (*domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var before *domain.Member
+	var beforeSnapshot domain.AuditSnapshot
+	if id != nil {
+		if before, err = findMember(ctx, tx, store.tenantId, *id); err != nil {
+			return nil, err
+		}
+		if before == nil {
+			return nil, nil
+		}
+		if beforeSnapshot, err = memberAuditSnapshot(before); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := change(tx, before)
+	if err != nil || after == nil {
+		return nil, err
+	}
+
+	afterSnapshot, err := memberAuditSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityMember, after.Id(), action, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// Returns store.ErrCampusNotFound if the member's campus does not exist.
+func insertMember(ctx context.Context, tx queryer, tenantId uint64, createDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	if createDto.CampusId != nil {
+		if err := checkCampusExists(ctx, tx, tenantId, *createDto.CampusId); err != nil {
+			return nil, err
+		}
+	}
+	row, err := scanMemberRow(tx.QueryRowContext(
+		ctx,
+		"INSERT INTO member (tenant_id, first_name, last_name, email_address, phone_number, address, campus_id, notes)\n"+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?)\n"+
+			"RETURNING "+memberColumns+";",
+		tenantId, createDto.FirstName, createDto.LastName, createDto.EmailAddress, createDto.PhoneNumber, createDto.Address,
+		createDto.CampusId, createDto.Notes))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMember()
+}
+
+// Returns store.ErrCampusNotFound if the member's campus does not exist.
+func updateMember(ctx context.Context, tx queryer, tenantId uint64, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	if updateDto.CampusId != nil {
+		if err := checkCampusExists(ctx, tx, tenantId, *updateDto.CampusId); err != nil {
+			return nil, err
+		}
+	}
+	row, err := scanMemberRow(tx.QueryRowContext(
+		ctx,
+		"UPDATE member SET first_name = ?, last_name = ?, email_address = ?, phone_number = ?, address = ?,\n"+
+			"campus_id = ?, notes = ?\n"+
+			"WHERE tenant_id = ? AND id = ?\n"+
+			"RETURNING "+memberColumns+";",
+		updateDto.FirstName, updateDto.LastName, updateDto.EmailAddress, updateDto.PhoneNumber, updateDto.Address, updateDto.CampusId,
+		updateDto.Notes, tenantId, id,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMember()
+}
+
+// Moves a member to the trash, or takes it out if deletedAt is nil.
+func setMemberDeletedAt(ctx context.Context, tx queryer, tenantId uint64, id uint64, deletedAt *time.Time) (*domain.Member, error) {
+	row, err := scanMemberRow(tx.QueryRowContext(
+		ctx,
+		"UPDATE member SET deleted_at = ? WHERE tenant_id = ? AND id = ?\n"+
+			"RETURNING "+memberColumns+";",
+		deletedAt, tenantId, id,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToMember()
+}
+
+// Ignores member's Id field. Returns store.ErrCampusNotFound if the member's
+// campus does not exist.
+func (store *MemberStore) Create(ctx context.Context, actor string, createDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	return store.changeMember(ctx, nil, domain.AuditActionCreate, actor, func(tx *sql.Tx, _ *domain.Member) (*domain.Member, error) {
+		return insertMember(ctx, tx, store.tenantId, createDto)
+	})
+}
+
+// Creates every member in a single transaction, so that either all of the
+// members are created or none are. The created members are returned in the
+// same order.
+func (store *MemberStore) CreateMany(ctx context.Context, actor string, createDtos []domain.MemberUpdateDTO) ([]domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	members := make([]domain.Member, 0, len(createDtos))
+	for i := range createDtos {
+		member, err := insertMember(ctx, tx, store.tenantId, &createDtos[i])
+		if err != nil {
+			return nil, fmt.Errorf("creating member %d: %v", i, err)
+		}
+		snapshot, err := memberAuditSnapshot(member)
+		if err != nil {
+			return nil, err
+		}
+		err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityMember, member.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *member)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// Returns nil if there is no member with the given id, or if that member is in
+// the trash, or store.ErrCampusNotFound if the member's campus does not exist.
+func (store *MemberStore) Update(ctx context.Context, actor string, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	return store.changeMember(ctx, &id, domain.AuditActionUpdate, actor, func(tx *sql.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() != nil {
+			return nil, nil
+		}
+		return updateMember(ctx, tx, store.tenantId, id, updateDto)
+	})
+}
+
+// Updates a member as Update does, but keeps the member's notes, for users who
+// may not write them.
+func (store *MemberStore) UpdateExceptNotes(ctx context.Context, actor string, id uint64, updateDto *domain.MemberUpdateDTO) (*domain.Member, error) {
+	return store.changeMember(ctx, &id, domain.AuditActionUpdate, actor, func(tx *sql.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() != nil {
+			return nil, nil
+		}
+		withNotes := *updateDto
+		withNotes.Notes = before.Notes()
+		return updateMember(ctx, tx, store.tenantId, id, &withNotes)
+	})
+}
+
+// Updates the email address and phone number a member has given for
+// themselves, keeping every other field. Returns nil if there is no member
+// with the given id, or if that member is in the trash.
+func (store *MemberStore) UpdateContactDetails(ctx context.Context, actor string, id uint64, updateDto *domain.MeUpdateDTO) (*domain.Member, error) {
+	return store.changeMember(ctx, &id, domain.AuditActionUpdate, actor, func(tx *sql.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() != nil {
+			return nil, nil
+		}
+		return updateMember(ctx, tx, store.tenantId, id, updateDto.ApplyTo(before))
+	})
+}
+
+// A column of another table which refers to a member by its id.
+type memberReference struct {
+	table  string
+	column string
+}
+
+// Every reference to a member from another table, which must be re-pointed to
+// the survivor when two members are merged, as in the store package. Tables
+// which refer to members must be added to both.
+var memberReferences = []memberReference{
+	{table: "app_user", column: "member_id"},
+	{table: "member_change", column: "member_id"},
+}
+
+// Merges two members into the survivor, taking each field from the member
+// chosen by the merge, re-pointing every reference to the merged member to the
+// survivor and moving the merged member to the trash, all in one transaction.
+// Returns nil if either member does not exist or is in the trash.
+func (store *MemberStore) Merge(ctx context.Context, actor string, mergeDto *domain.MemberMergeDTO) (*domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	members := make(map[uint64]*domain.Member)
+	snapshots := make(map[uint64]domain.AuditSnapshot)
+	for _, id := range []uint64{mergeDto.SurvivorId, mergeDto.MergedId} {
+		member, err := findMember(ctx, tx, store.tenantId, id)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil || member.DeletedAt() != nil {
+			return nil, nil
+		}
+		if snapshots[id], err = memberAuditSnapshot(member); err != nil {
+			return nil, err
+		}
+		members[id] = member
+	}
+	survivor, merged := members[mergeDto.SurvivorId], members[mergeDto.MergedId]
+
+	updateDto := mergeDto.Merge(survivor, merged)
+	if survivor, err = updateMember(ctx, tx, store.tenantId, survivor.Id(), updateDto); err != nil {
+		return nil, err
+	}
+
+	// a change the merged member is waiting on was made against details it no
+	// longer has, and the survivor may only have one change waiting
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE member_change SET status = ?, decided_at = ?, decided_by = ? WHERE tenant_id = ? AND member_id = ? AND status = 'pending';",
+		string(domain.MemberChangeRejected), time.Now().UTC(), actor, store.tenantId, merged.Id(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("rejecting the merged member's pending change: %v", err)
+	}
+
+	for _, reference := range memberReferences {
+		_, err = tx.ExecContext(
+			ctx,
+			fmt.Sprintf("UPDATE %s SET %s = ? WHERE tenant_id = ? AND %s = ?;", reference.table, reference.column, reference.column),
+			survivor.Id(), store.tenantId, merged.Id(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("re-pointing %s.%s to the survivor: %v", reference.table, reference.column, err)
+		}
+	}
+
+	if merged, err = setMemberDeletedAt(ctx, tx, store.tenantId, merged.Id(), util.NewPtr(time.Now().UTC())); err != nil {
+		return nil, err
+	}
+
+	for _, member := range []*domain.Member{survivor, merged} {
+		action := domain.AuditActionMerge
+		if member == merged {
+			action = domain.AuditActionDelete
+		}
+		after, err := memberAuditSnapshot(member)
+		if err != nil {
+			return nil, err
+		}
+		err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityMember, member.Id(), action, actor, snapshots[member.Id()], after)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return survivor, nil
+}
+
+// Members in the trash are not found by this method.
+func (store *MemberStore) FindById(ctx context.Context, id uint64) (*domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	member, err := findMember(ctx, store.db, store.tenantId, id)
+	if err != nil || member == nil || member.DeletedAt() != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// The conditions of a WHERE clause selecting the members of the tenant matching
+// the filter outside of the trash, and the arguments they refer to.
+func memberFilterConditions(tenantId uint64, filter *domain.MemberFilter) ([]string, []any) {
+	conditions := []string{"tenant_id = ?", "deleted_at IS NULL"}
+	args := []any{tenantId}
+
+	if filter.Search != nil {
+		// LIKE ignores the case of ASCII letters, as ILIKE does in postgres
+		pattern := "%" + store.EscapeLike(*filter.Search) + "%"
+		args = append(args, pattern, pattern, pattern)
+		conditions = append(conditions,
+			`(first_name LIKE ? ESCAPE '\' OR last_name LIKE ? ESCAPE '\' OR email_address LIKE ? ESCAPE '\')`)
+	}
+
+	if len(filter.CampusIds) > 0 {
+		condition, campusArgs := inCondition("campus_id", filter.CampusIds)
+		args = append(args, campusArgs...)
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, args
+}
+
+// Gets a page of the members matching the filter, excluding those in the
+// trash.
+func (store *MemberStore) GetPage(ctx context.Context, filter *domain.MemberFilter, pageSize uint, page uint) ([]domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	conditions, args := memberFilterConditions(store.tenantId, filter)
+	args = append(args, pageSize, page*pageSize)
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+memberColumns+" FROM member WHERE "+strings.Join(conditions, " AND ")+" ORDER BY id LIMIT ? OFFSET ?;",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectMembers(rows)
+}
+
+// Gets every member, excluding those in the trash.
+func (store *MemberStore) GetAll(ctx context.Context) ([]domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+memberColumns+" FROM member WHERE tenant_id = ? AND deleted_at IS NULL ORDER BY id;",
+		store.tenantId)
+	if err != nil {
+		return nil, err
+	}
+	return collectMembers(rows)
+}
+
+// Counts the members, excluding those in the trash.
+func (store *MemberStore) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	err := store.db.QueryRowContext(
+		ctx,
+		"SELECT count(*) FROM member WHERE tenant_id = ? AND deleted_at IS NULL;",
+		store.tenantId,
+	).Scan(&count)
+	return count, err
+}
+
+// The most members Stream reads with a single query.
+const streamBatchSize = 500
+
+// Calls fn with every member matching the filter, excluding those in the
+// trash, in order of id. Members are read from the database in batches as
+// they are needed rather than all at once, so this is suitable for very many
+// members. Each batch is read with a query of its own, which the query
+// timeout applies to, so that the time fn takes does not count against it.
+// Stops at and returns the first error returned by fn.
+func (store *MemberStore) Stream(ctx context.Context, filter *domain.MemberFilter, fn func(member *domain.Member) error) error {
+	conditions, args := memberFilterConditions(store.tenantId, filter)
+	afterId := uint64(0)
+	for {
+		members, err := store.streamBatch(ctx, conditions, args, afterId)
+		if err != nil {
+			return err
+		}
+		for i := range members {
+			if err = fn(&members[i]); err != nil {
+				return err
+			}
+		}
+		if len(members) < streamBatchSize {
+			return nil
+		}
+		afterId = members[len(members)-1].Id()
+	}
+}
+
+// Reads the next batch of the members Stream calls fn with, which follow the
+// member with the id.
+func (store *MemberStore) streamBatch(ctx context.Context, conditions []string, args []any, afterId uint64) ([]domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+memberColumns+" FROM member WHERE "+strings.Join(conditions, " AND ")+" AND id > ? ORDER BY id LIMIT ?;",
+		slices.Concat(args, []any{afterId, streamBatchSize})...)
+	if err != nil {
+		return nil, err
+	}
+	return collectMembers(rows)
+}
+
+// Gets a page of the members in the trash, most recently deleted first.
+func (store *MemberStore) GetTrashPage(ctx context.Context, pageSize uint, page uint) ([]domain.Member, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+memberColumns+" FROM member WHERE tenant_id = ? AND deleted_at IS NOT NULL\n"+
+			"ORDER BY deleted_at DESC, id LIMIT ? OFFSET ?;",
+		store.tenantId, pageSize, page*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return collectMembers(rows)
+}
+
+// Moves a member to the trash. The member is only removed permanently once
+// it is purged with PurgeDeletedBefore.
+// Returns false if there is no member with the given id outside of the trash.
+func (store *MemberStore) DeleteById(ctx context.Context, actor string, id uint64) (bool, error) {
+	member, err := store.changeMember(ctx, &id, domain.AuditActionDelete, actor, func(tx *sql.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() != nil {
+			return nil, nil
+		}
+		return setMemberDeletedAt(ctx, tx, store.tenantId, id, util.NewPtr(time.Now().UTC()))
+	})
+	if err != nil {
+		return false, err
+	}
+	return member != nil, nil
+}
+
+// Takes a member out of the trash.
+// Returns the restored member, or nil if there is no member with the given id
+// in the trash.
+func (store *MemberStore) Restore(ctx context.Context, actor string, id uint64) (*domain.Member, error) {
+	return store.changeMember(ctx, &id, domain.AuditActionRestore, actor, func(tx *sql.Tx, before *domain.Member) (*domain.Member, error) {
+		if before.DeletedAt() == nil {
+			return nil, nil
+		}
+		return setMemberDeletedAt(ctx, tx, store.tenantId, id, nil)
+	})
+}
+
+// Permanently removes every member that was moved to the trash before the
+// given time. Returns the number of members removed.
+func (store *MemberStore) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		"DELETE FROM member WHERE tenant_id = ? AND deleted_at IS NOT NULL AND deleted_at < ?\n"+
+			"RETURNING "+memberColumns+";",
+		store.tenantId, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	purged, err := collectMembers(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, member := range purged {
+		snapshot, err := memberAuditSnapshot(&member)
+		if err != nil {
+			return 0, err
+		}
+		err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityMember, member.Id(), domain.AuditActionPurge, domain.AuditActorSystem, snapshot, nil)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type OIDCLoginStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *OIDCLoginStore) ForTenant(tenantId uint64) store.OIDCLoginRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// Keeps a started login under its state until it is finished or expires. Only
+// the hash of the state is stored. Expired logins are removed.
+func (store *OIDCLoginStore) Create(ctx context.Context, state string, login *domain.OIDCLogin, duration time.Duration) error {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+
+	_, err := store.db.ExecContext(
+		ctx,
+		"DELETE FROM oidc_login WHERE tenant_id = ? AND expires_at <= ?;",
+		store.tenantId, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.db.ExecContext(
+		ctx,
+		"INSERT INTO oidc_login (tenant_id, state_hash, provider, code_verifier, nonce, expires_at)\n"+
+			"VALUES (?, ?, ?, ?, ?, ?);",
+		store.tenantId, auth.HashToken(state), login.Provider, login.CodeVerifier, login.Nonce, now.Add(duration),
+	)
+	return err
+}
+
+// Removes and returns the unexpired login with the state, so that each login
+// can only be finished once. Returns nil if there is no such login.
+func (store *OIDCLoginStore) Take(ctx context.Context, state string) (*domain.OIDCLogin, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	var login domain.OIDCLogin
+	err := store.db.QueryRowContext(
+		ctx,
+		"DELETE FROM oidc_login WHERE tenant_id = ? AND state_hash = ? AND expires_at > ?\n"+
+			"RETURNING provider, code_verifier, nonce;",
+		store.tenantId, auth.HashToken(state), time.Now().UTC(),
+	).Scan(&login.Provider, &login.CodeVerifier, &login.Nonce)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &login, nil
+}
@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type ScheduleStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *ScheduleStore) ForTenant(tenantId uint64) store.ScheduleRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// Returns store.ErrCampusNotFound if the schedule's campus does not exist.
+func (store *ScheduleStore) Create(ctx context.Context, actor string, createDto *domain.ScheduleCreateDTO) (*domain.Schedule, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	var count *uint
+	var unit *domain.ScheduleRepeatUnit
+	var day *domain.ScheduleDayOfWeek
+	var n *int
+
+	if createDto.RepeatInterval != nil {
+		count = &createDto.RepeatInterval.Count
+		unit = &createDto.RepeatInterval.Unit
+	}
+
+	if createDto.RepeatNthDayOfMonth != nil {
+		day = &createDto.RepeatNthDayOfMonth.Day
+		n = &createDto.RepeatNthDayOfMonth.N
+	}
+
+	row := domain.ScheduleRow{
+		BeginDate:              createDto.BeginDate,
+		EndDate:                createDto.EndDate,
+		RepeatIntervalCount:    count,
+		RepeatIntervalUnit:     unit,
+		RepeatNthDayOfMonthDay: day,
+		RepeatNthDayOfMonthN:   n,
+		CampusId:               createDto.CampusId,
+	}
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if row.CampusId != nil {
+		if err = checkCampusExists(ctx, tx, store.tenantId, *row.CampusId); err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		"INSERT INTO schedule (\n"+
+			"tenant_id, begin_date, end_date, repeat_interval_count, repeat_interval_unit,\n"+
+			"repeat_nth_day_of_month_day, repeat_nth_day_of_month_n, campus_id)\n"+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?)\n"+
+			"RETURNING id;",
+		store.tenantId, withoutTimeZone(row.BeginDate), withoutTimeZone(row.EndDate),
+		row.RepeatIntervalCount, row.RepeatIntervalUnit,
+		row.RepeatNthDayOfMonthDay, row.RepeatNthDayOfMonthN,
+		row.CampusId,
+	).Scan(&row.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := row.ToSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := domain.NewAuditSnapshot(schedule.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntitySchedule, schedule.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// Gets every schedule matching the filter.
+func (store *ScheduleStore) GetAll(ctx context.Context, filter *domain.ScheduleFilter) ([]domain.Schedule, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	where := "WHERE tenant_id = ?\n"
+	args := []any{store.tenantId}
+	if len(filter.CampusIds) > 0 {
+		condition, campusArgs := inCondition("campus_id", filter.CampusIds)
+		where = "WHERE tenant_id = ? AND " + condition + "\n"
+		args = append(args, campusArgs...)
+	}
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT id, begin_date, end_date, repeat_interval_count, repeat_interval_unit,\n"+
+			"repeat_nth_day_of_month_day, repeat_nth_day_of_month_n, campus_id\n"+
+			"FROM schedule\n"+
+			where+
+			"ORDER BY begin_date, id;",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := make([]domain.Schedule, 0)
+	for i := 0; rows.Next(); i++ {
+		var row domain.ScheduleRow
+		err := rows.Scan(
+			&row.Id, &row.BeginDate, &row.EndDate,
+			&row.RepeatIntervalCount, &row.RepeatIntervalUnit,
+			&row.RepeatNthDayOfMonthDay, &row.RepeatNthDayOfMonthN,
+			&row.CampusId,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		schedule, err := row.ToSchedule()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to schedule at row %d: %v", i, err)
+		}
+		schedules = append(schedules, *schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (store *ScheduleStore) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	err := store.db.QueryRowContext(ctx, "SELECT count(*) FROM schedule WHERE tenant_id = ?;", store.tenantId).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/auth"
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type SessionStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *SessionStore) ForTenant(tenantId uint64) store.SessionRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// Starts a session for the user lasting the given duration, returning the
+// token which identifies it. Only the hash of the token is stored.
+func (store *SessionStore) Create(ctx context.Context, userId uint64, duration time.Duration) (token string, expiresAt time.Time, err error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	token, tokenHash, err := auth.GenerateToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	createdAt := time.Now().UTC()
+	expiresAt = createdAt.Add(duration)
+
+	_, err = store.db.ExecContext(
+		ctx,
+		"INSERT INTO user_session (tenant_id, token_hash, user_id, created_at, expires_at)\n"+
+			"VALUES (?, ?, ?, ?, ?);",
+		store.tenantId, tokenHash, userId, createdAt, expiresAt,
+	)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// Finds the user a token belongs to. Returns nil if there is no unexpired
+// session with the token.
+func (store *SessionStore) FindUser(ctx context.Context, token string) (*domain.User, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	row, err := scanUserRow(store.db.QueryRowContext(
+		ctx,
+		"SELECT app_user.id, app_user.username, app_user.password_hash, app_user.roles, app_user.member_id, app_user.campus_ids,\n"+
+			"app_user.created_at\n"+
+			"FROM user_session JOIN app_user ON app_user.id = user_session.user_id\n"+
+			"WHERE user_session.tenant_id = ? AND user_session.token_hash = ? AND user_session.expires_at > ?;",
+		store.tenantId, auth.HashToken(token), time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return row.ToUser()
+}
+
+// Ends the session with the token. Returns false if there was no such session.
+func (store *SessionStore) Delete(ctx context.Context, token string) (bool, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	result, err := store.db.ExecContext(
+		ctx,
+		"DELETE FROM user_session WHERE tenant_id = ? AND token_hash = ?;",
+		store.tenantId, auth.HashToken(token),
+	)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+// Removes every session which has expired, returning how many were removed.
+func (store *SessionStore) DeleteExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	result, err := store.db.ExecContext(
+		ctx,
+		"DELETE FROM user_session WHERE tenant_id = ? AND expires_at <= ?;",
+		store.tenantId, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
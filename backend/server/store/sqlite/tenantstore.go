@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+)
+
+// Tenants are not themselves limited to a tenant, as the tenant of a request
+// must be found before anything else.
+type TenantStore struct {
+	db *db
+}
+
+// The columns of the tenant table in the order expected by scanTenantRow.
+const tenantColumns = "id, slug, name, created_at"
+
+func scanTenantRow(row scanner) (*domain.TenantRow, error) {
+	var tenantRow domain.TenantRow
+	err := row.Scan(
+		&tenantRow.Id,
+		&tenantRow.Slug,
+		&tenantRow.Name,
+		&tenantRow.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantRow, nil
+}
+
+// Creates the tenant, or renames it if a tenant with the slug already exists.
+func (store *TenantStore) Ensure(ctx context.Context, slug string, name string) (*domain.Tenant, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	row, err := scanTenantRow(store.db.QueryRowContext(
+		ctx,
+		"INSERT INTO tenant (slug, name, created_at)\n"+
+			"VALUES (?, ?, ?)\n"+
+			"ON CONFLICT (slug) DO UPDATE SET name = excluded.name\n"+
+			"RETURNING "+tenantColumns+";",
+		slug, name, time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToTenant()
+}
+
+// Returns nil if there is no tenant with the slug.
+func (store *TenantStore) FindBySlug(ctx context.Context, slug string) (*domain.Tenant, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	row, err := scanTenantRow(store.db.QueryRowContext(
+		ctx,
+		"SELECT "+tenantColumns+" FROM tenant WHERE slug = ?;",
+		slug,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.ToTenant()
+}
+
+func (store *TenantStore) GetAll(ctx context.Context) ([]domain.Tenant, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+tenantColumns+" FROM tenant ORDER BY id;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := make([]domain.Tenant, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanTenantRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		tenant, err := row.ToTenant()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to tenant at row %d: %v", i, err)
+		}
+		tenants = append(tenants, *tenant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
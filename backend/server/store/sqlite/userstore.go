@@ -0,0 +1,407 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/carsonalh/churchmanagerbackend/server/store"
+)
+
+type UserStore struct {
+	db       *db
+	tenantId uint64
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *UserStore) ForTenant(tenantId uint64) store.UserRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the app_user table in the order expected by scanUserRow.
+const userColumns = "id, username, password_hash, roles, member_id, campus_ids, created_at"
+
+func scanUserRow(row scanner) (*domain.UserRow, error) {
+	var userRow domain.UserRow
+	err := row.Scan(
+		&userRow.Id,
+		&userRow.Username,
+		&userRow.PasswordHash,
+		jsonColumn{&userRow.Roles},
+		&userRow.MemberId,
+		jsonColumn{&userRow.CampusIds},
+		&userRow.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &userRow, nil
+}
+
+// Checks that a member of the tenant exists outside of the trash, for linking
+// a user to it. Returns store.ErrMemberNotFound if it does not.
+func checkMemberExists(ctx context.Context, tx queryer, tenantId uint64, memberId uint64) error {
+	var exists bool
+	err := tx.QueryRowContext(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM member WHERE tenant_id = ? AND id = ? AND deleted_at IS NULL);",
+		tenantId, memberId,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errMemberNotFound
+	}
+	return nil
+}
+
+// Encodes the names of the roles for the roles column.
+func encodeRoles(roles []domain.Role) (any, error) {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return encodeJSON(names)
+}
+
+// Creates a user with an already hashed password, linked to the member if the
+// member id is not nil. The username is expected to be normalised. Returns
+// store.ErrUsernameTaken if a user with the username already exists, or
+// store.ErrMemberNotFound if there is no such member.
+func (store *UserStore) Create(ctx context.Context, actor string, username string, passwordHash string, roles []domain.Role, memberId *uint64) (*domain.User, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	encodedRoles, err := encodeRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if memberId != nil {
+		if err = checkMemberExists(ctx, tx, store.tenantId, *memberId); err != nil {
+			return nil, err
+		}
+	}
+
+	row, err := scanUserRow(tx.QueryRowContext(
+		ctx,
+		"INSERT INTO app_user (tenant_id, username, password_hash, roles, member_id, created_at)\n"+
+			"VALUES (?, ?, ?, ?, ?, ?)\n"+
+			"ON CONFLICT (tenant_id, username) DO NOTHING\n"+
+			"RETURNING "+userColumns+";",
+		store.tenantId, username, passwordHash, encodedRoles, memberId, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errUsernameTaken
+		}
+		return nil, err
+	}
+
+	user, err := row.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := domain.NewAuditSnapshot(user.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityUser, user.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Replaces the roles of a user. Returns nil if there is no such user.
+func (store *UserStore) SetRoles(ctx context.Context, actor string, id uint64, roles []domain.Role) (*domain.User, error) {
+	encodedRoles, err := encodeRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	return store.changeUser(ctx, actor, id, func(ctx context.Context, tx *sql.Tx) (*domain.UserRow, error) {
+		return scanUserRow(tx.QueryRowContext(
+			ctx,
+			"UPDATE app_user SET roles = ? WHERE tenant_id = ? AND id = ?\n"+
+				"RETURNING "+userColumns+";",
+			encodedRoles, store.tenantId, id,
+		))
+	})
+}
+
+// Links a user to the member they are, or unlinks them if the member id is
+// nil. Returns nil if there is no such user, or store.ErrMemberNotFound if
+// there is no such member.
+func (store *UserStore) SetMember(ctx context.Context, actor string, id uint64, memberId *uint64) (*domain.User, error) {
+	return store.changeUser(ctx, actor, id, func(ctx context.Context, tx *sql.Tx) (*domain.UserRow, error) {
+		if memberId != nil {
+			if err := checkMemberExists(ctx, tx, store.tenantId, *memberId); err != nil {
+				return nil, err
+			}
+		}
+		return scanUserRow(tx.QueryRowContext(
+			ctx,
+			"UPDATE app_user SET member_id = ? WHERE tenant_id = ? AND id = ?\n"+
+				"RETURNING "+userColumns+";",
+			memberId, store.tenantId, id,
+		))
+	})
+}
+
+// Limits a user to the members and schedules of the campuses, or allows them
+// every campus if there are none. Returns nil if there is no such user, or
+// store.ErrCampusNotFound if any of the campuses does not exist.
+func (store *UserStore) SetCampuses(ctx context.Context, actor string, id uint64, campusIds []uint64) (*domain.User, error) {
+	if campusIds == nil {
+		campusIds = make([]uint64, 0)
+	}
+	encodedCampusIds, err := encodeJSON(campusIds)
+	if err != nil {
+		return nil, err
+	}
+	return store.changeUser(ctx, actor, id, func(ctx context.Context, tx *sql.Tx) (*domain.UserRow, error) {
+		for _, campusId := range campusIds {
+			if err := checkCampusExists(ctx, tx, store.tenantId, campusId); err != nil {
+				return nil, err
+			}
+		}
+		return scanUserRow(tx.QueryRowContext(
+			ctx,
+			"UPDATE app_user SET campus_ids = ? WHERE tenant_id = ? AND id = ?\n"+
+				"RETURNING "+userColumns+";",
+			encodedCampusIds, store.tenantId, id,
+		))
+	})
+}
+
+// Runs an update to a single user in a transaction, recording the change in
+// the audit log. Returns nil if there is no such user.
+func (store *UserStore) changeUser(
+	ctx context.Context,
+	actor string,
+	id uint64,
+	update func(ctx context.Context, tx *sql.Tx) (*domain.UserRow, error),
+) (*domain.User, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	beforeRow, err := scanUserRow(tx.QueryRowContext(
+		ctx,
+		"SELECT "+userColumns+" FROM app_user WHERE tenant_id = ? AND id = ?;",
+		store.tenantId, id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	before, err := beforeRow.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	afterRow, err := update(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	after, err := afterRow.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	beforeSnapshot, err := domain.NewAuditSnapshot(before.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	afterSnapshot, err := domain.NewAuditSnapshot(after.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityUser, id, domain.AuditActionUpdate, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// Finds the user who logs in with the identity at an OpenID Connect issuer,
+// creating them with the username if there is no such user, and gives them
+// the roles. Roles are replaced on every login so that changes at the provider
+// are followed. Returns store.ErrUsernameTaken if a new user's username is
+// taken by another user.
+func (store *UserStore) ProvisionOIDCUser(ctx context.Context, issuer string, subject string, username string, roles []domain.Role) (*domain.User, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	encodedRoles, err := encodeRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var before *domain.User
+	beforeRow, err := scanUserRow(tx.QueryRowContext(
+		ctx,
+		"SELECT "+userColumns+" FROM app_user WHERE tenant_id = ? AND oidc_issuer = ? AND oidc_subject = ?;",
+		store.tenantId, issuer, subject,
+	))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil {
+		if before, err = beforeRow.ToUser(); err != nil {
+			return nil, err
+		}
+	}
+
+	var afterRow *domain.UserRow
+	action := domain.AuditActionUpdate
+	if before == nil {
+		action = domain.AuditActionCreate
+		afterRow, err = scanUserRow(tx.QueryRowContext(
+			ctx,
+			"INSERT INTO app_user (tenant_id, username, roles, oidc_issuer, oidc_subject, created_at)\n"+
+				"VALUES (?, ?, ?, ?, ?, ?)\n"+
+				"ON CONFLICT (tenant_id, username) DO NOTHING\n"+
+				"RETURNING "+userColumns+";",
+			store.tenantId, username, encodedRoles, issuer, subject, time.Now().UTC(),
+		))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errUsernameTaken
+		}
+	} else if slices.Equal(before.Roles(), roles) {
+		return before, nil
+	} else {
+		afterRow, err = scanUserRow(tx.QueryRowContext(
+			ctx,
+			"UPDATE app_user SET roles = ? WHERE tenant_id = ? AND id = ?\n"+
+				"RETURNING "+userColumns+";",
+			encodedRoles, store.tenantId, before.Id(),
+		))
+	}
+	if err != nil {
+		return nil, err
+	}
+	after, err := afterRow.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	var beforeSnapshot domain.AuditSnapshot
+	if before != nil {
+		if beforeSnapshot, err = domain.NewAuditSnapshot(before.ToResponseDTO()); err != nil {
+			return nil, err
+		}
+	}
+	afterSnapshot, err := domain.NewAuditSnapshot(after.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, store.tenantId, domain.AuditEntityUser, after.Id(), action, domain.AuditActorSystem, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// Returns nil if there is no user with the username.
+func (store *UserStore) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	row, err := scanUserRow(store.db.QueryRowContext(
+		ctx,
+		"SELECT "+userColumns+" FROM app_user WHERE tenant_id = ? AND username = ?;",
+		store.tenantId, username,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return row.ToUser()
+}
+
+func (store *UserStore) GetAll(ctx context.Context) ([]domain.User, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := store.db.QueryContext(
+		ctx,
+		"SELECT "+userColumns+" FROM app_user WHERE tenant_id = ? ORDER BY id;",
+		store.tenantId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]domain.User, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanUserRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		user, err := row.ToUser()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to user at row %d: %v", i, err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (store *UserStore) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := store.db.withTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	err := store.db.QueryRowContext(ctx, "SELECT count(*) FROM app_user WHERE tenant_id = ?;", store.tenantId).Scan(&count)
+	return count, err
+}
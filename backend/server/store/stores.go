@@ -0,0 +1,41 @@
+package store
+
+import "github.com/jackc/pgx/v5/pgxpool"
+
+// Everything the server keeps, in whichever database it is configured with.
+type Stores struct {
+	Tenants       TenantRepository
+	Users         UserRepository
+	Sessions      SessionRepository
+	OIDCLogins    OIDCLoginRepository
+	APIKeys       APIKeyRepository
+	Campuses      CampusRepository
+	Members       MemberRepository
+	MemberChanges MemberChangeRepository
+	Schedules     ScheduleRepository
+	Audit         AuditRepository
+	Health        HealthRepository
+	Backup        BackupRepository
+	// Closes the connections to the database, once the stores are no longer
+	// used
+	Close func()
+}
+
+// Gives the stores over a postgres database.
+func CreateStores(pool *pgxpool.Pool) *Stores {
+	return &Stores{
+		Tenants:       CreateTenantStore(pool),
+		Users:         CreateUserStore(pool),
+		Sessions:      CreateSessionStore(pool),
+		OIDCLogins:    CreateOIDCLoginStore(pool),
+		APIKeys:       CreateAPIKeyStore(pool),
+		Campuses:      CreateCampusStore(pool),
+		Members:       CreateMemberStore(pool),
+		MemberChanges: CreateMemberChangeStore(pool),
+		Schedules:     CreateScheduleStore(pool),
+		Audit:         CreateAuditStore(pool),
+		Health:        CreateHealthStore(pool),
+		Backup:        CreateBackupStore(pool),
+		Close:         pool.Close,
+	}
+}
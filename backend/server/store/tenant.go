@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// The role queries run as. Unlike the owner of the tables or a superuser, the
+// role is always subject to row-level security, which limits every query to
+// the rows of the tenant it is run for.
+const tenantRole = "churchmanager_tenant"
+
+// The setting the row-level security policies read the tenant's id from.
+const tenantSetting = "app.tenant_id"
+
+type contextKey int
+
+const (
+	tenantContextKey contextKey = iota
+	queryStartContextKey
+)
+
+// Gives a context in which queries only see and change the rows of the
+// tenant.
+func WithTenant(ctx context.Context, tenantId uint64) context.Context {
+	return context.WithValue(ctx, tenantContextKey, tenantId)
+}
+
+// The part of a store which limits its queries to a single tenant. Stores
+// which have not been given a tenant with ForTenant see no rows at all.
+type tenantScope struct {
+	tenantId uint64
+}
+
+// The context each query of the store is run with, which is cancelled along
+// with the context the store was called with.
+func (scope tenantScope) ctx(ctx context.Context) context.Context {
+	return WithTenant(ctx, scope.tenantId)
+}
+
+// Sets the tenant of a connection to that of the context it is acquired with,
+// before it is used. The tenant is kept with the connection so that it is only
+// set when it changes.
+func setConnectionTenant(ctx context.Context, conn *pgx.Conn) bool {
+	tenant := ""
+	if tenantId, ok := ctx.Value(tenantContextKey).(uint64); ok && tenantId != 0 {
+		tenant = strconv.FormatUint(tenantId, 10)
+	}
+
+	current, _ := conn.PgConn().CustomData()[tenantSetting].(string)
+	if current == tenant {
+		return true
+	}
+
+	_, err := conn.Exec(ctx, "SELECT set_config($1, $2, false);", tenantSetting, tenant)
+	if err != nil {
+		// the connection is replaced rather than used with the wrong tenant
+		return false
+	}
+	conn.PgConn().CustomData()[tenantSetting] = tenant
+	return true
+}
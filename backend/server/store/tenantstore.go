@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Tenants are not themselves limited to a tenant, as the tenant of a request
+// must be found before anything else.
+type TenantStore struct {
+	pool *pgxpool.Pool
+}
+
+func CreateTenantStore(pool *pgxpool.Pool) *TenantStore {
+	return &TenantStore{pool}
+}
+
+// The columns of the tenant table in the order expected by scanTenantRow.
+const tenantColumns = "id, slug, name, created_at"
+
+func scanTenantRow(row pgx.Row) (*domain.TenantRow, error) {
+	var tenantRow domain.TenantRow
+	err := row.Scan(
+		&tenantRow.Id,
+		&tenantRow.Slug,
+		&tenantRow.Name,
+		&tenantRow.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantRow, nil
+}
+
+// Creates the tenant, or renames it if a tenant with the slug already exists.
+func (store *TenantStore) Ensure(ctx context.Context, slug string, name string) (*domain.Tenant, error) {
+	row, err := scanTenantRow(store.pool.QueryRow(
+		ctx,
+		"INSERT INTO tenant (slug, name, created_at)\n"+
+			"VALUES ($1, $2, $3)\n"+
+			"ON CONFLICT (slug) DO UPDATE SET name = EXCLUDED.name\n"+
+			"RETURNING "+tenantColumns+";",
+		slug, name, time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, err
+	}
+	return row.ToTenant()
+}
+
+// Returns nil if there is no tenant with the slug.
+func (store *TenantStore) FindBySlug(ctx context.Context, slug string) (*domain.Tenant, error) {
+	row, err := scanTenantRow(store.pool.QueryRow(
+		ctx,
+		"SELECT "+tenantColumns+" FROM tenant WHERE slug = $1;",
+		slug,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+
+	return row.ToTenant()
+}
+
+func (store *TenantStore) GetAll(ctx context.Context) ([]domain.Tenant, error) {
+	rows, err := store.pool.Query(
+		ctx,
+		"SELECT "+tenantColumns+" FROM tenant ORDER BY id;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := make([]domain.Tenant, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanTenantRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		tenant, err := row.ToTenant()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to tenant at row %d: %v", i, err)
+		}
+		tenants = append(tenants, *tenant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
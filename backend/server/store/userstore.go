@@ -0,0 +1,373 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/carsonalh/churchmanagerbackend/server/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrUsernameTaken = errors.New("username is already taken")
+
+var ErrMemberNotFound = errors.New("member does not exist")
+
+type UserStore struct {
+	pool *pgxpool.Pool
+	tenantScope
+}
+
+func CreateUserStore(pool *pgxpool.Pool) *UserStore {
+	return &UserStore{pool: pool}
+}
+
+// Gives the store limited to the rows of the tenant.
+func (store *UserStore) ForTenant(tenantId uint64) UserRepository {
+	scoped := *store
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// The columns of the app_user table in the order expected by scanUserRow.
+const userColumns = "id, username, password_hash, roles, member_id, campus_ids, created_at"
+
+func scanUserRow(row pgx.Row) (*domain.UserRow, error) {
+	var userRow domain.UserRow
+	err := row.Scan(
+		&userRow.Id,
+		&userRow.Username,
+		&userRow.PasswordHash,
+		&userRow.Roles,
+		&userRow.MemberId,
+		&userRow.CampusIds,
+		&userRow.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &userRow, nil
+}
+
+// Checks that a member exists outside of the trash, for linking a user to it.
+// Returns ErrMemberNotFound if it does not.
+func checkMemberExists(ctx context.Context, tx pgx.Tx, memberId uint64) error {
+	var exists bool
+	err := tx.QueryRow(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM member WHERE id = $1 AND deleted_at IS NULL);",
+		memberId,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// Creates a user with an already hashed password, linked to the member if the
+// member id is not nil. The username is expected to be normalised. Returns
+// ErrUsernameTaken if a user with the username already exists, or
+// ErrMemberNotFound if there is no such member.
+func (store *UserStore) Create(ctx context.Context, actor string, username string, passwordHash string, roles []domain.Role, memberId *uint64) (*domain.User, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	if memberId != nil {
+		if err = checkMemberExists(ctx, tx, *memberId); err != nil {
+			return nil, err
+		}
+	}
+
+	row, err := scanUserRow(tx.QueryRow(
+		ctx,
+		"INSERT INTO app_user (username, password_hash, roles, member_id, created_at)\n"+
+			"VALUES ($1, $2, $3, $4, $5)\n"+
+			"ON CONFLICT (tenant_id, username) DO NOTHING\n"+
+			"RETURNING "+userColumns+";",
+		username, passwordHash, roleNames(roles), memberId, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	user, err := row.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := domain.NewAuditSnapshot(user.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityUser, user.Id(), domain.AuditActionCreate, actor, nil, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Replaces the roles of a user. Returns nil if there is no such user.
+func (store *UserStore) SetRoles(ctx context.Context, actor string, id uint64, roles []domain.Role) (*domain.User, error) {
+	return store.changeUser(ctx, actor, id, func(tx pgx.Tx) (*domain.UserRow, error) {
+		return scanUserRow(tx.QueryRow(
+			ctx,
+			"UPDATE app_user SET roles = $2 WHERE id = $1\n"+
+				"RETURNING "+userColumns+";",
+			id, roleNames(roles),
+		))
+	})
+}
+
+// Links a user to the member they are, or unlinks them if the member id is
+// nil. Returns nil if there is no such user, or ErrMemberNotFound if there is
+// no such member.
+func (store *UserStore) SetMember(ctx context.Context, actor string, id uint64, memberId *uint64) (*domain.User, error) {
+	return store.changeUser(ctx, actor, id, func(tx pgx.Tx) (*domain.UserRow, error) {
+		if memberId != nil {
+			if err := checkMemberExists(ctx, tx, *memberId); err != nil {
+				return nil, err
+			}
+		}
+		return scanUserRow(tx.QueryRow(
+			ctx,
+			"UPDATE app_user SET member_id = $2 WHERE id = $1\n"+
+				"RETURNING "+userColumns+";",
+			id, memberId,
+		))
+	})
+}
+
+// Limits a user to the members and schedules of the campuses, or allows them
+// every campus if there are none. Returns nil if there is no such user, or
+// ErrCampusNotFound if any of the campuses does not exist.
+func (store *UserStore) SetCampuses(ctx context.Context, actor string, id uint64, campusIds []uint64) (*domain.User, error) {
+	if campusIds == nil {
+		campusIds = make([]uint64, 0)
+	}
+	return store.changeUser(ctx, actor, id, func(tx pgx.Tx) (*domain.UserRow, error) {
+		for _, campusId := range campusIds {
+			if err := checkCampusExists(ctx, tx, campusId); err != nil {
+				return nil, err
+			}
+		}
+		return scanUserRow(tx.QueryRow(
+			ctx,
+			"UPDATE app_user SET campus_ids = $2 WHERE id = $1\n"+
+				"RETURNING "+userColumns+";",
+			id, campusIds,
+		))
+	})
+}
+
+// Runs an update to a single user in a transaction, recording the change in
+// the audit log. Returns nil if there is no such user.
+func (store *UserStore) changeUser(ctx context.Context, actor string, id uint64, update func(tx pgx.Tx) (*domain.UserRow, error)) (*domain.User, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	beforeRow, err := scanUserRow(tx.QueryRow(
+		ctx,
+		"SELECT "+userColumns+" FROM app_user WHERE id = $1 FOR UPDATE;",
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	before, err := beforeRow.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	afterRow, err := update(tx)
+	if err != nil {
+		return nil, err
+	}
+	after, err := afterRow.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	beforeSnapshot, err := domain.NewAuditSnapshot(before.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	afterSnapshot, err := domain.NewAuditSnapshot(after.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityUser, id, domain.AuditActionUpdate, actor, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// Finds the user who logs in with the identity at an OpenID Connect issuer,
+// creating them with the username if there is no such user, and gives them
+// the roles. Roles are replaced on every login so that changes at the provider
+// are followed. Returns ErrUsernameTaken if a new user's username is taken by
+// another user.
+func (store *UserStore) ProvisionOIDCUser(ctx context.Context, issuer string, subject string, username string, roles []domain.Role) (*domain.User, error) {
+	tx, err := store.pool.Begin(store.ctx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	var before *domain.User
+	beforeRow, err := scanUserRow(tx.QueryRow(
+		ctx,
+		"SELECT "+userColumns+" FROM app_user WHERE oidc_issuer = $1 AND oidc_subject = $2 FOR UPDATE;",
+		issuer, subject,
+	))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil {
+		if before, err = beforeRow.ToUser(); err != nil {
+			return nil, err
+		}
+	}
+
+	var afterRow *domain.UserRow
+	action := domain.AuditActionUpdate
+	if before == nil {
+		action = domain.AuditActionCreate
+		afterRow, err = scanUserRow(tx.QueryRow(
+			ctx,
+			"INSERT INTO app_user (username, roles, oidc_issuer, oidc_subject, created_at)\n"+
+				"VALUES ($1, $2, $3, $4, $5)\n"+
+				"ON CONFLICT (tenant_id, username) DO NOTHING\n"+
+				"RETURNING "+userColumns+";",
+			username, roleNames(roles), issuer, subject, time.Now().UTC(),
+		))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUsernameTaken
+		}
+	} else if slices.Equal(before.Roles(), roles) {
+		return before, nil
+	} else {
+		afterRow, err = scanUserRow(tx.QueryRow(
+			ctx,
+			"UPDATE app_user SET roles = $2 WHERE id = $1\n"+
+				"RETURNING "+userColumns+";",
+			before.Id(), roleNames(roles),
+		))
+	}
+	if err != nil {
+		return nil, err
+	}
+	after, err := afterRow.ToUser()
+	if err != nil {
+		return nil, err
+	}
+
+	var beforeSnapshot domain.AuditSnapshot
+	if before != nil {
+		if beforeSnapshot, err = domain.NewAuditSnapshot(before.ToResponseDTO()); err != nil {
+			return nil, err
+		}
+	}
+	afterSnapshot, err := domain.NewAuditSnapshot(after.ToResponseDTO())
+	if err != nil {
+		return nil, err
+	}
+	err = recordAudit(ctx, tx, domain.AuditEntityUser, after.Id(), action, domain.AuditActorSystem, beforeSnapshot, afterSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// Returns nil if there is no user with the username.
+func (store *UserStore) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	row, err := scanUserRow(store.pool.QueryRow(
+		store.ctx(ctx),
+		"SELECT "+userColumns+" FROM app_user WHERE username = $1;",
+		username,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+
+	return row.ToUser()
+}
+
+func (store *UserStore) GetAll(ctx context.Context) ([]domain.User, error) {
+	rows, err := store.pool.Query(
+		store.ctx(ctx),
+		"SELECT "+userColumns+" FROM app_user ORDER BY id;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]domain.User, 0)
+	for i := 0; rows.Next(); i++ {
+		row, err := scanUserRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row %d: %v", i, err)
+		}
+		user, err := row.ToUser()
+		if err != nil {
+			return nil, fmt.Errorf("converting row to user at row %d: %v", i, err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (store *UserStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := store.pool.QueryRow(store.ctx(ctx), "SELECT count(*) FROM app_user;").Scan(&count)
+	return count, err
+}
+
+func roleNames(roles []domain.Role) []string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return names
+}